@@ -1,6 +1,9 @@
 package main
 
-import "github.com/levchenki/tea-api/internal/app"
+import (
+	"github.com/levchenki/tea-api/internal/app"
+	"os"
+)
 
 //	@title			Tea API
 //	@version		1.0
@@ -15,7 +18,14 @@ import "github.com/levchenki/tea-api/internal/app"
 //	@in							header
 //	@name						Authorization
 
+//	@securityDefinitions.apikey	ApiKeyAuth
+//	@in							header
+//	@name						X-API-Key
+
 // @query.collection.format	multi
 func main() {
+	if len(os.Args) > 1 {
+		os.Exit(app.RunCommand(os.Args[1:]))
+	}
 	app.Run()
 }
@@ -8,25 +8,33 @@ import (
 	_ "github.com/levchenki/tea-api/docs"
 	v1 "github.com/levchenki/tea-api/internal/api/v1"
 	"github.com/levchenki/tea-api/internal/config"
+	controllerV1 "github.com/levchenki/tea-api/internal/controller/v1"
+	"github.com/levchenki/tea-api/internal/jwtx"
 	"github.com/levchenki/tea-api/internal/logx"
+	"github.com/levchenki/tea-api/internal/netx"
 	httpSwagger "github.com/swaggo/http-swagger/v2"
 )
 
-func NewRouter(cfg *config.Config, db *sqlx.DB, log logx.AppLogger) *chi.Mux {
+func NewRouter(cfg *config.Config, db *sqlx.DB, jwtKeys *jwtx.KeySet, log logx.AppLogger) *chi.Mux {
 	r := chi.NewRouter()
 
+	// The client IP is needed by the logger, the rate limits and the sessions.
+	r.Use(netx.RealIP(netx.MustParseTrustedProxies(cfg.TrustedProxies)))
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{cfg.AppDomain},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", "X-Guest-Token"},
 		ExposedHeaders:   []string{"Link"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
 
-	v1Router := v1.NewRouter(cfg, db, log)
+	v1Router := v1.NewRouter(cfg, db, jwtKeys, log)
+	jwksController := controllerV1.NewJWKSController(jwtKeys)
+
+	r.Get("/.well-known/jwks.json", jwksController.GetJWKS)
 
 	r.Route("/api", func(r chi.Router) {
 		r.Get("/swagger/*", httpSwagger.Handler())
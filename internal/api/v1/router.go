@@ -5,92 +5,444 @@ import (
 	"github.com/jmoiron/sqlx"
 	"github.com/levchenki/tea-api/internal/config"
 	v1 "github.com/levchenki/tea-api/internal/controller/v1"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/eventx"
+	"github.com/levchenki/tea-api/internal/jwtx"
 	"github.com/levchenki/tea-api/internal/logx"
+	"github.com/levchenki/tea-api/internal/mailx"
+	"github.com/levchenki/tea-api/internal/ratex"
 	"github.com/levchenki/tea-api/internal/repository/postgres"
 	"github.com/levchenki/tea-api/internal/service"
 )
 
-func NewRouter(cfg *config.Config, db *sqlx.DB, log logx.AppLogger) *chi.Mux {
+func NewRouter(cfg *config.Config, db *sqlx.DB, jwtKeys *jwtx.KeySet, log logx.AppLogger) *chi.Mux {
 	teaRepository := postgres.NewTeaRepository(db)
 	tagRepository := postgres.NewTagRepository(db)
 	userRepository := postgres.NewUserRepository(db)
 	categoryRepository := postgres.NewCategoryRepository(db)
 	unitRepository := postgres.NewUnitRepository(db)
+	listRepository := postgres.NewListRepository(db)
+	roleRepository := postgres.NewRoleRepository(db)
+	sessionRepository := postgres.NewSessionRepository(db)
+	identityRepository := postgres.NewIdentityRepository(db)
+	apiKeyRepository := postgres.NewApiKeyRepository(db)
+	guestRepository := postgres.NewGuestRepository(db)
+	cartRepository := postgres.NewCartRepository(db)
+	orderRepository := postgres.NewOrderRepository(db)
+	notificationRepository := postgres.NewNotificationRepository(db)
+	locationRepository := postgres.NewLocationRepository(db)
+	eventRepository := postgres.NewEventRepository(db)
+	loyaltyRepository := postgres.NewLoyaltyRepository(db)
+	discountRepository := postgres.NewDiscountRepository(db)
 
-	teaService := service.NewTeaService(teaRepository, tagRepository, unitRepository)
-	userService := service.NewUserService(userRepository)
+	orderEvents := eventx.NewBroker[entity.OrderEvent](32)
+
+	identityProviders := []service.IdentityProvider{
+		service.NewTelegramWidgetIdentityProvider(cfg.BotToken, cfg.TelegramAuthMaxAge),
+		service.NewTelegramMiniAppIdentityProvider(cfg.BotToken, cfg.TelegramAuthMaxAge),
+	}
+
+	// Sign-in links are only written to the log in development, anywhere
+	// else they would let the readers of the log sign in as anyone.
+	var mailSender mailx.Sender
+	if cfg.Mail.Host != "" {
+		mailSender = mailx.NewSMTPSender(mailx.SMTPConfig{
+			Host:     cfg.Mail.Host,
+			Port:     cfg.Mail.Port,
+			Username: cfg.Mail.Username,
+			Password: cfg.Mail.Password,
+			From:     cfg.Mail.From,
+		})
+	} else if cfg.Environment == config.EnvDev || cfg.Environment == config.EnvLocal {
+		mailSender = mailx.NewLogSender(log)
+	}
+
+	if mailSender != nil {
+		identityProviders = append(identityProviders,
+			service.NewEmailIdentityProvider(identityRepository, mailSender, cfg.AppDomain, cfg.EmailLoginTTL))
+	} else {
+		log.Info("Email sign-in is disabled, SMTP is not configured")
+	}
+	if cfg.OIDC.Issuer != "" {
+		identityProviders = append(identityProviders, service.NewOIDCIdentityProvider(service.OIDCConfig{
+			Issuer:       cfg.OIDC.Issuer,
+			ClientId:     cfg.OIDC.ClientId,
+			ClientSecret: cfg.OIDC.ClientSecret,
+			RedirectURL:  cfg.OIDC.RedirectURL,
+			Scopes:       cfg.OIDC.Scopes,
+		}, identityRepository))
+	}
+
+	adminBootstrapService := service.NewAdminBootstrapService(userRepository, roleRepository, cfg.AdminTelegramIds, log)
+
+	notificationService := service.NewNotificationService(notificationRepository, log)
+
+	teaService := service.NewTeaService(teaRepository, tagRepository, unitRepository, categoryRepository, locationRepository, notificationService)
+	userService := service.NewUserService(
+		userRepository,
+		roleRepository,
+		sessionRepository,
+		identityRepository,
+		identityProviders,
+		adminBootstrapService,
+		cfg.RefreshGracePeriod,
+		log,
+	)
 	categoryService := service.NewCategoryService(categoryRepository, teaRepository)
 	tagService := service.NewTagService(tagRepository)
 	unitService := service.NewUnitService(unitRepository)
+	listService := service.NewListService(listRepository, teaRepository, tagRepository)
+	roleService := service.NewRoleService(roleRepository, userRepository)
+	apiKeyService := service.NewApiKeyService(apiKeyRepository)
+	guestService := service.NewGuestService(guestRepository, teaRepository)
+	botService := service.NewBotService(teaService, categoryService, userRepository, cfg.BotName, cfg.MiniAppName)
+	discountService := service.NewDiscountService(discountRepository, tagRepository, categoryRepository, teaRepository)
+	orderService := service.NewOrderService(
+		cartRepository,
+		orderRepository,
+		teaRepository,
+		locationRepository,
+		loyaltyRepository,
+		discountService,
+		orderEvents,
+		notificationService,
+		cfg.Loyalty.PointValue,
+	)
+	locationService := service.NewLocationService(locationRepository, teaRepository, userRepository)
+	eventService := service.NewEventService(eventRepository, teaRepository, locationRepository)
+	loyaltyService := service.NewLoyaltyService(loyaltyRepository, userRepository, cfg.Loyalty.PointValue)
 
 	teaControllerV1 := v1.NewTeaController(teaService, log)
 	categoryControllerV1 := v1.NewCategoryController(categoryService, log)
 	tagControllerV1 := v1.NewTagController(tagService, log)
 	unitControllerV1 := v1.NewUnitController(unitService, log)
+	adminControllerV1 := v1.NewAdminController(userService, log)
+	listControllerV1 := v1.NewListController(cfg.BotName, cfg.MiniAppName, listService, log)
+	roleControllerV1 := v1.NewRoleController(roleService, log)
+	apiKeyControllerV1 := v1.NewApiKeyController(apiKeyService, log)
+	guestControllerV1 := v1.NewGuestController(jwtKeys, guestService, log)
+	orderControllerV1 := v1.NewOrderController(orderService, log)
+	baristaControllerV1 := v1.NewBaristaController(orderService, orderEvents, log)
+	botControllerV1 := v1.NewBotController(cfg.BotWebhookSecret, botService, log)
+	locationControllerV1 := v1.NewLocationController(locationService, log)
+	eventControllerV1 := v1.NewEventController(cfg.BotName, cfg.MiniAppName, eventService, log)
+	loyaltyControllerV1 := v1.NewLoyaltyController(loyaltyService, log)
+	discountControllerV1 := v1.NewDiscountController(discountService, log)
+
+	rateLimiter := v1.NewRateLimiter(ratex.NewMemoryStore(), log)
+	limitAuth := rateLimiter.Limit("auth", ratex.MustParsePolicy(cfg.RateLimit.Auth))
+	limitRead := rateLimiter.Limit("read", ratex.MustParsePolicy(cfg.RateLimit.Read))
+	limitWrite := rateLimiter.Limit("write", ratex.MustParsePolicy(cfg.RateLimit.Write))
 
 	authControllerV1 := v1.NewUserController(
-		cfg.JWTSecretKey,
-		cfg.BotToken,
+		jwtKeys,
 		userService,
+		guestService,
 		log,
 	)
 
 	r := chi.NewRouter()
 
 	r.Route("/auth", func(r chi.Router) {
+		r.Use(limitAuth)
 		r.Post("/", authControllerV1.Auth)
 		r.Post("/mini-app", authControllerV1.AuthMiniApp)
+		r.Post("/email/link", authControllerV1.RequestEmailLogin)
+		r.Post("/email", authControllerV1.AuthEmail)
+		r.Get("/oidc", authControllerV1.GetOIDCAuthorizationURL)
+		r.Post("/oidc", authControllerV1.AuthOIDC)
 		r.Post("/refresh", authControllerV1.UpdateAccessToken)
+		r.Post("/logout", authControllerV1.Logout)
+	})
+
+	if cfg.BotWebhookSecret != "" {
+		r.Post("/bot/webhook", botControllerV1.Webhook)
+	}
+
+	r.Route("/guest", func(r chi.Router) {
+		r.With(limitAuth).Post("/", guestControllerV1.CreateGuest)
+
+		r.Group(func(r chi.Router) {
+			r.Use(guestControllerV1.GuestMiddleware)
+			r.Use(limitWrite)
+			r.Get("/marks", guestControllerV1.GetGuestMarks)
+			r.Post("/teas/{id}/favourite", guestControllerV1.ToggleGuestFavourite)
+			r.Post("/teas/{id}/want-to-try", guestControllerV1.ToggleGuestWantToTry)
+		})
+	})
+
+	r.Route("/admin", func(r chi.Router) {
+		r.Use(authControllerV1.AuthMiddleware(true))
+		r.Use(authControllerV1.RequireAllLocations)
+
+		r.Route("/users", func(r chi.Router) {
+			r.Group(func(r chi.Router) {
+				r.Use(authControllerV1.RequirePermission(entity.PermissionUsersManage))
+				r.Get("/", adminControllerV1.GetAllUsers)
+				r.Get("/{id}", adminControllerV1.GetUserActivity)
+				r.Post("/{id}/block", adminControllerV1.BlockUser)
+				r.Delete("/{id}/block", adminControllerV1.UnblockUser)
+			})
+
+			r.Group(func(r chi.Router) {
+				r.Use(authControllerV1.RequirePermission(entity.PermissionRolesManage))
+				r.Post("/{id}/admin", adminControllerV1.GrantAdmin)
+				r.Delete("/{id}/admin", adminControllerV1.RevokeAdmin)
+				r.Get("/{id}/roles", roleControllerV1.GetUserRoles)
+				r.Post("/{id}/roles/{roleId}", roleControllerV1.AssignRole)
+				r.Delete("/{id}/roles/{roleId}", roleControllerV1.UnassignRole)
+				r.Put("/{id}/location", locationControllerV1.SetUserLocation)
+			})
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(authControllerV1.RequirePermission(entity.PermissionRolesManage))
+			r.Get("/permissions", roleControllerV1.GetAllPermissions)
+			r.Get("/roles", roleControllerV1.GetAllRoles)
+			r.Post("/roles", roleControllerV1.CreateRole)
+			r.Put("/roles/{id}", roleControllerV1.UpdateRole)
+			r.Delete("/roles/{id}", roleControllerV1.DeleteRole)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(authControllerV1.RequirePermission(entity.PermissionApiKeysManage))
+			r.Get("/api-keys", apiKeyControllerV1.GetAllApiKeys)
+			r.Post("/api-keys", apiKeyControllerV1.CreateApiKey)
+			r.Delete("/api-keys/{id}", apiKeyControllerV1.RevokeApiKey)
+		})
+	})
+
+	r.Route("/me", func(r chi.Router) {
+		r.Use(authControllerV1.AuthMiddleware(true))
+		r.Get("/", authControllerV1.GetMe)
+		r.Put("/", authControllerV1.UpdateMe)
+		r.Delete("/", authControllerV1.DeleteMe)
+		r.Get("/sessions", authControllerV1.GetMySessions)
+		r.Delete("/sessions", authControllerV1.RevokeAllMySessions)
+		r.Delete("/sessions/{id}", authControllerV1.RevokeMySession)
+		r.Get("/identities", authControllerV1.GetMyIdentities)
+		r.Post("/identities/{provider}", authControllerV1.LinkMyIdentity)
+		r.Delete("/identities/{id}", authControllerV1.UnlinkMyIdentity)
+
+		r.Get("/cart", orderControllerV1.GetMyCart)
+		r.Delete("/cart", orderControllerV1.ClearMyCart)
+		r.Post("/cart/items", orderControllerV1.AddMyCartItem)
+		r.Put("/cart/items/{id}", orderControllerV1.UpdateMyCartItem)
+		r.Delete("/cart/items/{id}", orderControllerV1.DeleteMyCartItem)
+		r.With(limitWrite).Put("/cart/promo-code", orderControllerV1.SetMyPromoCode)
+		r.Delete("/cart/promo-code", orderControllerV1.RemoveMyPromoCode)
+		r.Get("/orders", orderControllerV1.GetMyOrders)
+		r.With(limitWrite).Post("/orders", orderControllerV1.PlaceMyOrder)
+		r.Get("/orders/{id}", orderControllerV1.GetMyOrderById)
+
+		r.Get("/loyalty", loyaltyControllerV1.GetMyLoyalty)
+		r.Get("/loyalty/history", loyaltyControllerV1.GetMyLoyaltyHistory)
+	})
+
+	r.Route("/loyalty", func(r chi.Router) {
+		r.With(limitRead).Get("/rules", loyaltyControllerV1.GetLoyaltyRules)
+
+		r.Group(func(r chi.Router) {
+			r.Use(authControllerV1.AuthMiddleware(true))
+			r.Use(authControllerV1.RequirePermission(entity.PermissionLoyaltyAdjust))
+			r.Get("/users/{id}", loyaltyControllerV1.GetUserLoyalty)
+			r.Get("/users/{id}/history", loyaltyControllerV1.GetUserLoyaltyHistory)
+			r.With(limitWrite).Post("/users/{id}/adjustments", loyaltyControllerV1.AdjustUserLoyalty)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(authControllerV1.AuthMiddleware(true))
+			r.Use(authControllerV1.RequireAllLocations)
+			r.Use(authControllerV1.RequirePermission(entity.PermissionLoyaltyRules))
+			r.With(limitWrite).Put("/rules/{categoryId}", loyaltyControllerV1.SetLoyaltyRule)
+		})
+	})
+
+	r.Route("/discounts", func(r chi.Router) {
+		r.Use(authControllerV1.AuthMiddleware(true))
+		r.Use(authControllerV1.RequireAllLocations)
+		r.Use(authControllerV1.RequirePermission(entity.PermissionDiscountsManage))
+		r.Get("/", discountControllerV1.GetAllDiscounts)
+		r.Get("/report", discountControllerV1.GetDiscountReport)
+		r.Get("/{id}", discountControllerV1.GetDiscountById)
+		r.Get("/{id}/redemptions", discountControllerV1.GetDiscountRedemptions)
+
+		r.Group(func(r chi.Router) {
+			r.Use(limitWrite)
+			r.Post("/", discountControllerV1.CreateDiscount)
+			r.Put("/{id}", discountControllerV1.UpdateDiscount)
+			r.Delete("/{id}", discountControllerV1.DeleteDiscount)
+		})
+	})
+
+	r.Route("/barista", func(r chi.Router) {
+		r.Use(authControllerV1.AuthMiddleware(true))
+		r.Use(authControllerV1.RequirePermission(entity.PermissionOrdersManage))
+		r.Get("/queue", baristaControllerV1.GetQueue)
+		r.Get("/queue/stream", baristaControllerV1.StreamQueue)
+		r.With(limitWrite).Patch("/orders/{id}/status", baristaControllerV1.SetOrderStatus)
 	})
 
 	r.Route("/teas", func(r chi.Router) {
 		r.Group(func(r chi.Router) {
+			r.Use(apiKeyControllerV1.ApiKeyMiddleware)
 			r.Use(authControllerV1.AuthMiddleware(false))
+			r.Use(limitRead)
 			r.Get("/", teaControllerV1.GetAllTeas)
+			r.Get("/compare", teaControllerV1.CompareTeas)
 			r.Get("/{id}", teaControllerV1.GetTeaById)
 		})
 
 		r.Group(func(r chi.Router) {
 			r.Use(authControllerV1.AuthMiddleware(true))
+			r.Use(limitWrite)
 			r.Post("/{id}/evaluate", teaControllerV1.Evaluate)
 			r.Delete("/{id}/evaluate", teaControllerV1.DeleteEvaluation)
 			r.Post("/{id}/favourite", teaControllerV1.ToggleFavourites)
 
+			r.With(authControllerV1.RequireAllLocations, authControllerV1.RequirePermission(entity.PermissionTeasVisibility)).
+				Patch("/{id}/visibility", teaControllerV1.SetTeaVisibility)
+			r.With(authControllerV1.RequirePermission(entity.PermissionReviewsModerate)).
+				Delete("/{id}/evaluations/{userId}", teaControllerV1.DeleteUserEvaluation)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(apiKeyControllerV1.ApiKeyMiddleware)
+			r.Use(authControllerV1.AuthMiddleware(true))
+			r.Use(limitWrite)
+			r.Use(authControllerV1.RequireAllLocations)
+
 			r.Group(func(r chi.Router) {
-				r.Use(authControllerV1.AdminMiddleware)
+				r.Use(authControllerV1.RequirePermission(entity.PermissionTeasWrite))
 				r.Post("/", teaControllerV1.CreateTea)
 				r.Delete("/{id}", teaControllerV1.DeleteTea)
 				r.Put("/{id}", teaControllerV1.UpdateTea)
 			})
+
+			r.With(authControllerV1.RequirePermission(entity.PermissionStockWrite)).
+				Patch("/{id}/stock", teaControllerV1.SetTeaStock)
 		})
 
 		r.Route("/units", func(r chi.Router) {
-			r.Get("/", unitControllerV1.GetAllUnits)
-			r.Get("/weights", unitControllerV1.GetAllWeights)
-			r.Post("/", unitControllerV1.CreateUnit)
-			r.Delete("/{id}", unitControllerV1.DeleteUnit)
-			r.Put("/{id}", unitControllerV1.UpdateUnit)
+			r.Use(apiKeyControllerV1.ApiKeyMiddleware)
+			r.With(limitRead).Get("/", unitControllerV1.GetAllUnits)
+			r.With(limitRead).Get("/weights", unitControllerV1.GetAllWeights)
+
+			r.Group(func(r chi.Router) {
+				r.Use(authControllerV1.AuthMiddleware(true))
+				r.Use(limitWrite)
+				r.Use(authControllerV1.RequireAllLocations)
+				r.Use(authControllerV1.RequirePermission(entity.PermissionUnitsWrite))
+				r.Post("/", unitControllerV1.CreateUnit)
+				r.Delete("/{id}", unitControllerV1.DeleteUnit)
+				r.Put("/{id}", unitControllerV1.UpdateUnit)
+			})
 		})
 	})
 
 	r.Route("/categories", func(r chi.Router) {
-		r.Get("/{id}", categoryControllerV1.GetCategoryById)
-		r.Get("/", categoryControllerV1.GetAllCategories)
+		r.Use(apiKeyControllerV1.ApiKeyMiddleware)
+		r.With(limitRead).Get("/{id}", categoryControllerV1.GetCategoryById)
+		r.With(limitRead).Get("/", categoryControllerV1.GetAllCategories)
 
 		r.Group(func(r chi.Router) {
 			r.Use(authControllerV1.AuthMiddleware(true))
-			r.Use(authControllerV1.AdminMiddleware)
+			r.Use(limitWrite)
+			r.Use(authControllerV1.RequireAllLocations)
+			r.Use(authControllerV1.RequirePermission(entity.PermissionCategoriesWrite))
 			r.Post("/", categoryControllerV1.CreateCategory)
 			r.Delete("/{id}", categoryControllerV1.DeleteCategory)
 			r.Put("/{id}", categoryControllerV1.UpdateCategory)
 		})
 	})
+	r.Route("/locations", func(r chi.Router) {
+		r.With(limitRead).Get("/", locationControllerV1.GetAllLocations)
+		r.With(limitRead).Get("/{id}", locationControllerV1.GetLocationById)
+		r.With(limitRead).Get("/{id}/teas/{teaId}", locationControllerV1.GetTeaOverride)
+
+		r.Group(func(r chi.Router) {
+			r.Use(authControllerV1.AuthMiddleware(true))
+			r.Use(limitWrite)
+			r.Use(authControllerV1.RequireAllLocations)
+			r.Use(authControllerV1.RequirePermission(entity.PermissionLocationsManage))
+			r.Post("/", locationControllerV1.CreateLocation)
+			r.Put("/{id}", locationControllerV1.UpdateLocation)
+			r.Delete("/{id}", locationControllerV1.DeleteLocation)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(authControllerV1.AuthMiddleware(true))
+			r.Use(limitWrite)
+			r.With(authControllerV1.RequireLocationPermission(entity.PermissionTeasVisibility)).
+				Patch("/{id}/teas/{teaId}/visibility", locationControllerV1.SetTeaVisibilityOverride)
+			r.With(authControllerV1.RequireLocationPermission(entity.PermissionStockWrite)).
+				Patch("/{id}/teas/{teaId}/stock", locationControllerV1.SetTeaStockOverride)
+			r.With(authControllerV1.RequireLocationPermission(entity.PermissionTeasWrite)).
+				Put("/{id}/teas/{teaId}/prices", locationControllerV1.SetTeaPricesOverride)
+			r.With(authControllerV1.RequireLocationPermission(entity.PermissionTeasWrite)).
+				Delete("/{id}/teas/{teaId}", locationControllerV1.DeleteTeaOverride)
+		})
+	})
+
+	r.Route("/events", func(r chi.Router) {
+		r.Group(func(r chi.Router) {
+			r.Use(authControllerV1.AuthMiddleware(false))
+			r.Use(limitRead)
+			r.Get("/", eventControllerV1.GetAllEvents)
+			r.Get("/{id}", eventControllerV1.GetEventById)
+			r.Get("/{id}/calendar.ics", eventControllerV1.GetEventCalendar)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(authControllerV1.AuthMiddleware(true))
+			r.Use(limitWrite)
+			r.Post("/{id}/registration", eventControllerV1.RegisterForEvent)
+			r.Delete("/{id}/registration", eventControllerV1.CancelEventRegistration)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(authControllerV1.AuthMiddleware(true))
+			r.Use(authControllerV1.RequireAllLocations)
+			r.Use(authControllerV1.RequirePermission(entity.PermissionEventsManage))
+			r.Get("/{id}/attendees", eventControllerV1.GetEventAttendees)
+
+			r.Group(func(r chi.Router) {
+				r.Use(limitWrite)
+				r.Post("/", eventControllerV1.CreateEvent)
+				r.Put("/{id}", eventControllerV1.UpdateEvent)
+				r.Delete("/{id}", eventControllerV1.DeleteEvent)
+			})
+		})
+	})
+
+	r.Route("/lists", func(r chi.Router) {
+		r.Use(authControllerV1.AuthMiddleware(true))
+		r.Get("/", listControllerV1.GetAllLists)
+		r.Post("/", listControllerV1.CreateList)
+		r.Get("/{id}", listControllerV1.GetListById)
+		r.Put("/{id}", listControllerV1.UpdateList)
+		r.Delete("/{id}", listControllerV1.DeleteList)
+		r.Post("/{id}/items", listControllerV1.AddListItem)
+		r.Put("/{id}/items/order", listControllerV1.ReorderListItems)
+		r.Put("/{id}/items/{teaId}", listControllerV1.UpdateListItem)
+		r.Delete("/{id}/items/{teaId}", listControllerV1.DeleteListItem)
+		r.Post("/{id}/share", listControllerV1.ShareList)
+		r.Delete("/{id}/share", listControllerV1.RevokeListShare)
+	})
+
+	r.Route("/shared", func(r chi.Router) {
+		r.Get("/lists/{token}", listControllerV1.GetSharedList)
+	})
+
 	r.Route("/tags", func(r chi.Router) {
-		r.Get("/", tagControllerV1.GetAllTags)
+		r.Use(apiKeyControllerV1.ApiKeyMiddleware)
+		r.With(limitRead).Get("/", tagControllerV1.GetAllTags)
 
 		r.Group(func(r chi.Router) {
 			r.Use(authControllerV1.AuthMiddleware(true))
-			r.Use(authControllerV1.AdminMiddleware)
+			r.Use(limitWrite)
+			r.Use(authControllerV1.RequireAllLocations)
+			r.Use(authControllerV1.RequirePermission(entity.PermissionTagsWrite))
 			r.Post("/", tagControllerV1.CreateTag)
 			r.Delete("/{id}", tagControllerV1.DeleteTag)
 			r.Put("/{id}", tagControllerV1.UpdateTag)
@@ -1,12 +1,17 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"github.com/levchenki/tea-api/internal/api"
 	"github.com/levchenki/tea-api/internal/config"
+	"github.com/levchenki/tea-api/internal/jwtx"
 	"github.com/levchenki/tea-api/internal/logx"
 	"github.com/levchenki/tea-api/internal/logx/slogx"
 	"github.com/levchenki/tea-api/internal/migrations"
+	"github.com/levchenki/tea-api/internal/notifyx"
+	"github.com/levchenki/tea-api/internal/repository/postgres"
+	"github.com/levchenki/tea-api/internal/service"
 	"github.com/levchenki/tea-api/internal/storage"
 	"net/http"
 	"os"
@@ -34,7 +39,43 @@ func Run() {
 	}
 	log.Info("Connected to database successfully")
 
-	r := api.NewRouter(cfg, db, log)
+	log.Info("Granting configured admins...")
+	adminBootstrapService := service.NewAdminBootstrapService(
+		postgres.NewUserRepository(db),
+		postgres.NewRoleRepository(db),
+		cfg.AdminTelegramIds,
+		log,
+	)
+	err = adminBootstrapService.GrantConfigured()
+	if err != nil {
+		log.Error(err.Error())
+		os.Exit(1)
+	}
+	log.Info("Configured admins granted successfully")
+
+	jwtKeys, err := jwtx.NewKeySet(jwtx.Config{
+		Algorithm:    cfg.JWTAlgorithm,
+		Secret:       cfg.JWTSecretKey,
+		KeysDir:      cfg.JWTKeysDir,
+		SigningKeyId: cfg.JWTSigningKeyId,
+	})
+	if err != nil {
+		log.Error(err.Error())
+		os.Exit(1)
+	}
+
+	notificationDispatcher := service.NewNotificationDispatcher(
+		postgres.NewNotificationRepository(db),
+		notifyx.NewTelegramMessenger(cfg.BotToken),
+		cfg.Notifications.Interval,
+		cfg.Notifications.MaxAttempts,
+		log,
+	)
+	ctx, stopDispatcher := context.WithCancel(context.Background())
+	defer stopDispatcher()
+	go notificationDispatcher.Run(ctx)
+
+	r := api.NewRouter(cfg, db, jwtKeys, log)
 
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%s", cfg.Server.Port),
@@ -54,6 +95,7 @@ func Run() {
 	signal.Notify(quit, os.Interrupt)
 	<-quit
 	log.Info("Shutting down server...")
+	stopDispatcher()
 	err = server.Close()
 	if err != nil {
 		log.Error(err.Error())
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"fmt"
+	"github.com/levchenki/tea-api/internal/config"
+	"github.com/levchenki/tea-api/internal/logx"
+	"github.com/levchenki/tea-api/internal/logx/slogx"
+	"github.com/levchenki/tea-api/internal/repository/postgres"
+	"github.com/levchenki/tea-api/internal/service"
+	"github.com/levchenki/tea-api/internal/storage"
+	"os"
+	"strconv"
+)
+
+const commandUsage = `Usage:
+  tea-api                              start the server
+  tea-api admin grant <telegram-id>    make an admin of a registered user
+`
+
+// RunCommand runs an operator command given on the command line and returns
+// the exit code.
+func RunCommand(args []string) int {
+	if len(args) == 3 && args[0] == "admin" && args[1] == "grant" {
+		return grantAdmin(args[2])
+	}
+
+	fmt.Fprint(os.Stderr, commandUsage)
+	return 2
+}
+
+func grantAdmin(rawTelegramId string) int {
+	telegramId, err := strconv.ParseUint(rawTelegramId, 10, 64)
+	if err != nil || telegramId == 0 {
+		fmt.Fprintf(os.Stderr, "invalid telegram id %q\n", rawTelegramId)
+		return 2
+	}
+
+	cfg := config.Setup()
+	var log logx.AppLogger = slogx.Setup(cfg.Environment)
+
+	db, err := storage.NewPostgresConnection(cfg)
+	if err != nil {
+		log.Error(err.Error())
+		return 1
+	}
+	defer db.Close()
+
+	adminBootstrapService := service.NewAdminBootstrapService(
+		postgres.NewUserRepository(db),
+		postgres.NewRoleRepository(db),
+		cfg.AdminTelegramIds,
+		log,
+	)
+
+	user, err := adminBootstrapService.Grant(telegramId)
+	if err != nil {
+		log.Error(err.Error())
+		return 1
+	}
+
+	log.Info(fmt.Sprintf("User %s (telegram id %d) is an admin", user.Id, user.TelegramId))
+	return 0
+}
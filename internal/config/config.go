@@ -2,7 +2,10 @@ package config
 
 import (
 	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/levchenki/tea-api/internal/netx"
+	"github.com/levchenki/tea-api/internal/ratex"
 	"log"
+	"time"
 )
 
 type Environment string
@@ -14,12 +17,28 @@ const (
 )
 
 type Config struct {
-	Database     `env-prefix:"DB_"`
-	Server       `env-prefix:"SERVER_"`
-	Environment  `env:"APP_ENV" env-default:"dev"`
-	AppDomain    string `env:"APP_DOMAIN" env-required:"true"`
-	JWTSecretKey string `env:"JWT_SECRET_KEY" env-required:"true"`
-	BotToken     string `env:"TELEGRAM_BOT_TOKEN" env-required:"true"`
+	Database           `env-prefix:"DB_"`
+	Server             `env-prefix:"SERVER_"`
+	Mail               `env-prefix:"MAIL_"`
+	OIDC               `env-prefix:"OIDC_"`
+	RateLimit          `env-prefix:"RATE_LIMIT_"`
+	Notifications      `env-prefix:"NOTIFICATIONS_"`
+	Loyalty            `env-prefix:"LOYALTY_"`
+	Environment        `env:"APP_ENV" env-default:"dev"`
+	AppDomain          string        `env:"APP_DOMAIN" env-required:"true"`
+	JWTAlgorithm       string        `env:"JWT_ALGORITHM" env-default:"HS256"`
+	JWTSecretKey       string        `env:"JWT_SECRET_KEY"`
+	JWTKeysDir         string        `env:"JWT_KEYS_DIR"`
+	JWTSigningKeyId    string        `env:"JWT_SIGNING_KEY_ID"`
+	RefreshGracePeriod time.Duration `env:"JWT_REFRESH_GRACE_PERIOD" env-default:"10s"`
+	BotToken           string        `env:"TELEGRAM_BOT_TOKEN" env-required:"true"`
+	TelegramAuthMaxAge time.Duration `env:"TELEGRAM_AUTH_MAX_AGE" env-default:"1h"`
+	BotName            string        `env:"TELEGRAM_BOT_NAME"`
+	MiniAppName        string        `env:"TELEGRAM_MINI_APP_NAME"`
+	BotWebhookSecret   string        `env:"TELEGRAM_WEBHOOK_SECRET"`
+	EmailLoginTTL      time.Duration `env:"EMAIL_LOGIN_TTL" env-default:"15m"`
+	AdminTelegramIds   []uint64      `env:"ADMIN_TELEGRAM_IDS"`
+	TrustedProxies     []string      `env:"TRUSTED_PROXIES"`
 }
 
 type Database struct {
@@ -34,11 +53,61 @@ type Server struct {
 	Port string `env:"PORT" env-required:"true"`
 }
 
+type Mail struct {
+	Host     string `env:"SMTP_HOST"`
+	Port     string `env:"SMTP_PORT" env-default:"587"`
+	Username string `env:"SMTP_USERNAME"`
+	Password string `env:"SMTP_PASSWORD"`
+	From     string `env:"FROM"`
+}
+
+type OIDC struct {
+	Issuer       string   `env:"ISSUER"`
+	ClientId     string   `env:"CLIENT_ID"`
+	ClientSecret string   `env:"CLIENT_SECRET"`
+	RedirectURL  string   `env:"REDIRECT_URL"`
+	Scopes       []string `env:"SCOPES" env-default:"openid,email,profile"`
+}
+
+type RateLimit struct {
+	Auth  string `env:"AUTH" env-default:"20/1m"`
+	Read  string `env:"READ" env-default:"300/1m"`
+	Write string `env:"WRITE" env-default:"60/1m"`
+}
+
+type Notifications struct {
+	Interval    time.Duration `env:"INTERVAL" env-default:"10s"`
+	MaxAttempts int           `env:"MAX_ATTEMPTS" env-default:"8"`
+}
+
+// Loyalty.PointValue is the discount one point gives. Zero turns redeeming
+// off, points are still earned.
+type Loyalty struct {
+	PointValue float64 `env:"POINT_VALUE" env-default:"0"`
+}
+
 func Setup() *Config {
 	var cfg Config
 	err := cleanenv.ReadEnv(&cfg)
 	if err != nil {
-		log.Fatalf("Error loading config: %w", err)
+		log.Fatalf("Error loading config: %v", err)
+	}
+
+	for _, policy := range []string{cfg.RateLimit.Auth, cfg.RateLimit.Read, cfg.RateLimit.Write} {
+		if _, err := ratex.ParsePolicy(policy); err != nil {
+			log.Fatalf("Error loading config: %v", err)
+		}
+	}
+
+	if _, err := netx.ParseTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("Error loading config: %v", err)
+	}
+
+	if cfg.Notifications.Interval <= 0 || cfg.Notifications.MaxAttempts <= 0 {
+		log.Fatalf("Error loading config: NOTIFICATIONS_INTERVAL and NOTIFICATIONS_MAX_ATTEMPTS must be positive")
+	}
+	if cfg.Loyalty.PointValue < 0 {
+		log.Fatalf("Error loading config: LOYALTY_POINT_VALUE can not be negative")
 	}
 	return &cfg
 }
@@ -0,0 +1,226 @@
+package v1
+
+import (
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/errx"
+	"github.com/levchenki/tea-api/internal/logx"
+	"github.com/levchenki/tea-api/internal/schemas"
+	"github.com/levchenki/tea-api/internal/schemas/userSchemas"
+	"net/http"
+)
+
+type AdminUserService interface {
+	GetAllUsers(filters *userSchemas.Filters) ([]entity.User, uint64, error)
+	GetUserActivity(userId uuid.UUID) (*entity.UserActivity, error)
+	SetAdmin(actorId, userId uuid.UUID, isAdmin bool) (*entity.User, error)
+	SetBlocked(actorId, userId uuid.UUID, isBlocked bool) (*entity.User, error)
+}
+
+type AdminController struct {
+	userService AdminUserService
+	log         logx.AppLogger
+}
+
+func NewAdminController(userService AdminUserService, log logx.AppLogger) *AdminController {
+	return &AdminController{
+		userService: userService,
+		log:         log,
+	}
+}
+
+// GetAllUsers godoc
+//
+//	@Summary	Return users
+//	@Tags		Admin
+//	@Accept		json
+//	@Produce	json
+//	@Param		page		query		int		false	"Page number"
+//	@Param		limit		query		int		false	"Page size"
+//	@Param		search		query		string	false	"Username, name or Telegram ID"
+//	@Param		isAdmin		query		bool	false	"Is admin"
+//	@Param		isBlocked	query		bool	false	"Is blocked"
+//	@Success	200			{object}	schemas.PaginatedResult[userSchemas.UserResponseModel]
+//	@Failure	400			{object}	errx.AppError
+//	@Failure	401			{object}	errx.AppError
+//	@Failure	403			{object}	errx.AppError
+//	@Failure	500			{object}	errx.AppError
+//	@Router		/api/v1/admin/users [get]
+//	@Security	BearerAuth
+func (c *AdminController) GetAllUsers(w http.ResponseWriter, r *http.Request) {
+	filters := &userSchemas.Filters{}
+	if err := filters.Validate(r); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	users, total, err := c.userService.GetAllUsers(filters)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	items := make([]*userSchemas.UserResponseModel, len(users))
+	for i := range users {
+		items[i] = userSchemas.NewUserResponseModel(&users[i])
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, schemas.PaginatedResult[*userSchemas.UserResponseModel]{
+		Total: total,
+		Items: items,
+	})
+}
+
+// GetUserActivity godoc
+//
+//	@Summary	Return user with their activity
+//	@Tags		Admin
+//	@Accept		json
+//	@Produce	json
+//	@Param		id	path		string	true	"User ID"
+//	@Success	200	{object}	userSchemas.ActivityResponseModel
+//	@Failure	400	{object}	errx.AppError
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	403	{object}	errx.AppError
+//	@Failure	404	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/admin/users/{id} [get]
+//	@Security	BearerAuth
+func (c *AdminController) GetUserActivity(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	activity, err := c.userService.GetUserActivity(id)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, userSchemas.NewActivityResponseModel(activity))
+}
+
+// GrantAdmin godoc
+//
+//	@Summary	Grant admin permissions to user
+//	@Tags		Admin
+//	@Accept		json
+//	@Produce	json
+//	@Param		id	path		string	true	"User ID"
+//	@Success	200	{object}	userSchemas.UserResponseModel
+//	@Failure	400	{object}	errx.AppError
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	403	{object}	errx.AppError
+//	@Failure	404	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/admin/users/{id}/admin [post]
+//	@Security	BearerAuth
+func (c *AdminController) GrantAdmin(w http.ResponseWriter, r *http.Request) {
+	c.setAdmin(w, r, true)
+}
+
+// RevokeAdmin godoc
+//
+//	@Summary	Revoke admin permissions from user
+//	@Tags		Admin
+//	@Accept		json
+//	@Produce	json
+//	@Param		id	path		string	true	"User ID"
+//	@Success	200	{object}	userSchemas.UserResponseModel
+//	@Failure	400	{object}	errx.AppError
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	403	{object}	errx.AppError
+//	@Failure	404	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/admin/users/{id}/admin [delete]
+//	@Security	BearerAuth
+func (c *AdminController) RevokeAdmin(w http.ResponseWriter, r *http.Request) {
+	c.setAdmin(w, r, false)
+}
+
+// BlockUser godoc
+//
+//	@Summary	Block user
+//	@Tags		Admin
+//	@Accept		json
+//	@Produce	json
+//	@Param		id	path		string	true	"User ID"
+//	@Success	200	{object}	userSchemas.UserResponseModel
+//	@Failure	400	{object}	errx.AppError
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	403	{object}	errx.AppError
+//	@Failure	404	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/admin/users/{id}/block [post]
+//	@Security	BearerAuth
+func (c *AdminController) BlockUser(w http.ResponseWriter, r *http.Request) {
+	c.setBlocked(w, r, true)
+}
+
+// UnblockUser godoc
+//
+//	@Summary	Unblock user
+//	@Tags		Admin
+//	@Accept		json
+//	@Produce	json
+//	@Param		id	path		string	true	"User ID"
+//	@Success	200	{object}	userSchemas.UserResponseModel
+//	@Failure	400	{object}	errx.AppError
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	403	{object}	errx.AppError
+//	@Failure	404	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/admin/users/{id}/block [delete]
+//	@Security	BearerAuth
+func (c *AdminController) UnblockUser(w http.ResponseWriter, r *http.Request) {
+	c.setBlocked(w, r, false)
+}
+
+func (c *AdminController) setAdmin(w http.ResponseWriter, r *http.Request, isAdmin bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	user, err := c.userService.SetAdmin(userClaims.Id, id, isAdmin)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, userSchemas.NewUserResponseModel(user))
+}
+
+func (c *AdminController) setBlocked(w http.ResponseWriter, r *http.Request, isBlocked bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	user, err := c.userService.SetBlocked(userClaims.Id, id, isBlocked)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, userSchemas.NewUserResponseModel(user))
+}
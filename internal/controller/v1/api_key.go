@@ -0,0 +1,160 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/errx"
+	"github.com/levchenki/tea-api/internal/logx"
+	"github.com/levchenki/tea-api/internal/schemas/apiKeySchemas"
+	"github.com/levchenki/tea-api/internal/schemas/userSchemas"
+	"net/http"
+)
+
+type ApiKeyService interface {
+	GetAll() ([]entity.ApiKey, error)
+	Create(actorId uuid.UUID, apiKey *apiKeySchemas.RequestModel) (*entity.ApiKey, string, error)
+	Revoke(id uuid.UUID) error
+	Authenticate(key string) (*entity.ApiKey, error)
+}
+
+type ApiKeyController struct {
+	apiKeyService ApiKeyService
+	log           logx.AppLogger
+}
+
+func NewApiKeyController(apiKeyService ApiKeyService, log logx.AppLogger) *ApiKeyController {
+	return &ApiKeyController{
+		apiKeyService: apiKeyService,
+		log:           log,
+	}
+}
+
+// GetAllApiKeys godoc
+//
+//	@Summary	Return all API keys
+//	@Tags		Admin
+//	@Accept		json
+//	@Produce	json
+//	@Success	200	{object}	[]apiKeySchemas.ResponseModel
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	403	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/admin/api-keys [get]
+//	@Security	BearerAuth
+func (c *ApiKeyController) GetAllApiKeys(w http.ResponseWriter, r *http.Request) {
+	apiKeys, err := c.apiKeyService.GetAll()
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	response := make([]*apiKeySchemas.ResponseModel, len(apiKeys))
+	for i := range apiKeys {
+		response[i] = apiKeySchemas.NewApiKeyResponseModel(&apiKeys[i])
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response)
+}
+
+// CreateApiKey godoc
+//
+//	@Summary		Create API key
+//	@Description	Scopes: catalog:read, catalog:write, stock:write. The key is returned only in this response.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			apiKey	body		apiKeySchemas.RequestModel	true	"API key"
+//	@Success		201		{object}	apiKeySchemas.CreatedResponseModel
+//	@Failure		400		{object}	errx.AppError
+//	@Failure		401		{object}	errx.AppError
+//	@Failure		403		{object}	errx.AppError
+//	@Failure		500		{object}	errx.AppError
+//	@Router			/api/v1/admin/api-keys [post]
+//	@Security		BearerAuth
+func (c *ApiKeyController) CreateApiKey(w http.ResponseWriter, r *http.Request) {
+	apiKeyRequest := &apiKeySchemas.RequestModel{}
+	if err := render.Bind(r, apiKeyRequest); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	apiKey, key, err := c.apiKeyService.Create(userClaims.Id, apiKeyRequest)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, apiKeySchemas.NewCreatedResponseModel(apiKey, key))
+}
+
+// RevokeApiKey godoc
+//
+//	@Summary	Revoke API key
+//	@Tags		Admin
+//	@Accept		json
+//	@Produce	json
+//	@Param		id	path	string	true	"API key ID"
+//	@Success	200
+//	@Failure	400	{object}	errx.AppError
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	403	{object}	errx.AppError
+//	@Failure	404	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/admin/api-keys/{id} [delete]
+//	@Security	BearerAuth
+func (c *ApiKeyController) RevokeApiKey(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	err = c.apiKeyService.Revoke(id)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+}
+
+// ApiKeyMiddleware authenticates requests carrying an X-API-Key header and
+// puts claims built from the key scopes into the context, where
+// AuthMiddleware and RequirePermission pick them up. Requests without the
+// header are passed on untouched.
+func (c *ApiKeyController) ApiKeyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-API-Key")
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		apiKey, err := c.apiKeyService.Authenticate(key)
+		if err != nil {
+			handleError(w, r, c.log, err)
+			return
+		}
+
+		isRead := r.Method == http.MethodGet || r.Method == http.MethodHead
+		if isRead && !apiKey.HasScope(entity.ApiKeyScopeCatalogRead) {
+			err := fmt.Errorf("api key %s does not have %s scope", apiKey.Prefix, entity.ApiKeyScopeCatalogRead)
+			errResponse := errx.NewForbiddenError(err)
+			handleError(w, r, c.log, errResponse)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), "accessTokenClaims", userSchemas.NewApiKeyAccessTokenClaims(apiKey))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
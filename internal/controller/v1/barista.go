@@ -0,0 +1,246 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/errx"
+	"github.com/levchenki/tea-api/internal/logx"
+	"github.com/levchenki/tea-api/internal/schemas/orderSchemas"
+	"github.com/levchenki/tea-api/internal/schemas/teaSchemas"
+	"github.com/levchenki/tea-api/internal/schemas/userSchemas"
+	"net/http"
+	"time"
+)
+
+// queueStreamHeartbeat keeps idle streams from being closed by proxies.
+const queueStreamHeartbeat = 25 * time.Second
+
+const queueStreamSnapshotEvent = "queue"
+
+type BaristaService interface {
+	GetQueue(locationId uuid.UUID) ([]entity.Order, error)
+	SetStatus(id uuid.UUID, status string, locationId uuid.UUID) (*entity.Order, error)
+}
+
+type OrderEventSubscriber interface {
+	Subscribe() (<-chan entity.OrderEvent, func())
+}
+
+type BaristaController struct {
+	baristaService BaristaService
+	orderEvents    OrderEventSubscriber
+	log            logx.AppLogger
+}
+
+func NewBaristaController(baristaService BaristaService, orderEvents OrderEventSubscriber, log logx.AppLogger) *BaristaController {
+	return &BaristaController{
+		baristaService: baristaService,
+		orderEvents:    orderEvents,
+		log:            log,
+	}
+}
+
+// GetQueue godoc
+//
+//	@Summary		Return active orders for the bar
+//	@Description	Users scoped to a location get the queue of their location, the rest get the queue of the requested location or of all locations.
+//	@Tags			Barista
+//	@Accept			json
+//	@Produce		json
+//	@Param			locationId	query		string	false	"Location ID"
+//	@Success		200			{array}		orderSchemas.OrderResponseModel
+//	@Failure		400			{object}	errx.AppError
+//	@Failure		401			{object}	errx.AppError
+//	@Failure		403			{object}	errx.AppError
+//	@Failure		404			{object}	errx.AppError
+//	@Failure		500			{object}	errx.AppError
+//	@Router			/api/v1/barista/queue [get]
+//	@Security		BearerAuth
+func (c *BaristaController) GetQueue(w http.ResponseWriter, r *http.Request) {
+	locationId, err := queueLocationId(r)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	orders, err := c.baristaService.GetQueue(locationId)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, newOrderResponseModels(orders))
+}
+
+// StreamQueue godoc
+//
+//	@Summary		Stream changes of the order queue
+//	@Description	Server-sent events. The stream starts with a "queue" event holding the active orders, then sends "order.created" and "order.updated" events with the order. Comment lines are sent as a heartbeat. The stream is closed if the client falls behind, it should reconnect to get a fresh queue. Browsers need a fetch based event source to pass the Authorization header. The queue is scoped to a location as in GET /barista/queue.
+//	@Tags			Barista
+//	@Produce		text/event-stream
+//	@Param			locationId	query	string	false	"Location ID"
+//	@Success		200
+//	@Failure		400	{object}	errx.AppError
+//	@Failure		401	{object}	errx.AppError
+//	@Failure		403	{object}	errx.AppError
+//	@Failure		404	{object}	errx.AppError
+//	@Failure		500	{object}	errx.AppError
+//	@Router			/api/v1/barista/queue/stream [get]
+//	@Security		BearerAuth
+func (c *BaristaController) StreamQueue(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		errResponse := errx.NewAppError(http.StatusInternalServerError, fmt.Errorf("streaming is not supported"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	locationId, err := queueLocationId(r)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	// Subscribe before reading the queue, so no change falls in between.
+	events, unsubscribe := c.orderEvents.Subscribe()
+	defer unsubscribe()
+
+	orders, err := c.baristaService.GetQueue(locationId)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	err = writeServerEvent(w, flusher, queueStreamSnapshotEvent, newOrderResponseModels(orders))
+	if err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(queueStreamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-events:
+			// The broker drops subscribers that fall behind, ending the
+			// stream makes the client reconnect and reload the queue.
+			if !ok {
+				return
+			}
+			if locationId != uuid.Nil && (event.Order.LocationId == nil || *event.Order.LocationId != locationId) {
+				continue
+			}
+			err = writeServerEvent(w, flusher, event.Type, orderSchemas.NewOrderResponseModel(event.Order))
+			if err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			_, err = fmt.Fprint(w, ": heartbeat\n\n")
+			if err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// SetOrderStatus godoc
+//
+//	@Summary		Change status of order
+//	@Description	Orders go new → accepted → brewing → ready → served and can be cancelled until served. Weight items of a cancelled order go back to the stock of its location. Users scoped to a location can change orders of their location only. Returns 409 if the status has been changed by someone else.
+//	@Tags			Barista
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string							true	"Order ID"
+//	@Param			status	body		orderSchemas.StatusRequestModel	true	"Status"
+//	@Success		200		{object}	orderSchemas.OrderResponseModel
+//	@Failure		400		{object}	errx.AppError
+//	@Failure		401		{object}	errx.AppError
+//	@Failure		403		{object}	errx.AppError
+//	@Failure		404		{object}	errx.AppError
+//	@Failure		409		{object}	errx.AppError
+//	@Failure		500		{object}	errx.AppError
+//	@Router			/api/v1/barista/orders/{id}/status [patch]
+//	@Security		BearerAuth
+func (c *BaristaController) SetOrderStatus(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	request := &orderSchemas.StatusRequestModel{}
+	if err := render.Bind(r, request); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	order, err := c.baristaService.SetStatus(id, request.Status, userClaims.LocationId)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, orderSchemas.NewOrderResponseModel(order))
+}
+
+// queueLocationId returns the location of the queue the user works with. The
+// nil location id stands for all locations.
+func queueLocationId(r *http.Request) (uuid.UUID, error) {
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	locationId, err := teaSchemas.ParseLocationId(r)
+	if err != nil {
+		return uuid.Nil, errx.NewBadRequestError(err)
+	}
+
+	if userClaims.LocationId == uuid.Nil {
+		return locationId, nil
+	}
+
+	if locationId != uuid.Nil && locationId != userClaims.LocationId {
+		err := fmt.Errorf("user with id %s is scoped to another location", userClaims.Id)
+		return uuid.Nil, errx.NewForbiddenError(err)
+	}
+	return userClaims.LocationId, nil
+}
+
+func newOrderResponseModels(orders []entity.Order) []*orderSchemas.OrderResponseModel {
+	items := make([]*orderSchemas.OrderResponseModel, len(orders))
+	for i := range orders {
+		items[i] = orderSchemas.NewOrderResponseModel(&orders[i])
+	}
+	return items
+}
+
+func writeServerEvent(w http.ResponseWriter, flusher http.Flusher, event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+	if err != nil {
+		return err
+	}
+	flusher.Flush()
+	return nil
+}
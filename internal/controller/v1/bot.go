@@ -0,0 +1,82 @@
+package v1
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"github.com/go-chi/render"
+	"github.com/levchenki/tea-api/internal/errx"
+	"github.com/levchenki/tea-api/internal/logx"
+	"github.com/levchenki/tea-api/internal/tgx"
+	"net/http"
+)
+
+const (
+	botSecretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+	maxBotUpdateSize     = 1 << 20
+)
+
+type BotService interface {
+	HandleUpdate(update *tgx.Update) (any, error)
+}
+
+type BotController struct {
+	webhookSecret string
+	botService    BotService
+	log           logx.AppLogger
+}
+
+func NewBotController(webhookSecret string, botService BotService, log logx.AppLogger) *BotController {
+	return &BotController{
+		webhookSecret: webhookSecret,
+		botService:    botService,
+		log:           log,
+	}
+}
+
+// Webhook godoc
+//
+//	@Summary		Receive Telegram bot update
+//	@Description	Called by Telegram with the secret token given to setWebhook. The answer to the update is returned as a Bot API method in the response body.
+//	@Tags			Bot
+//	@Accept			json
+//	@Produce		json
+//	@Param			X-Telegram-Bot-Api-Secret-Token	header	string		true	"Webhook secret token"
+//	@Param			update							body	tgx.Update	true	"Update"
+//	@Success		200
+//	@Failure		400	{object}	errx.AppError
+//	@Failure		401	{object}	errx.AppError
+//	@Router			/api/v1/bot/webhook [post]
+func (c *BotController) Webhook(w http.ResponseWriter, r *http.Request) {
+	secretToken := r.Header.Get(botSecretTokenHeader)
+	if subtle.ConstantTimeCompare([]byte(secretToken), []byte(c.webhookSecret)) != 1 {
+		errResponse := errx.NewUnauthorizedError(fmt.Errorf("invalid secret token"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	update := &tgx.Update{}
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBotUpdateSize)).Decode(update)
+	if err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	reply, err := c.botService.HandleUpdate(update)
+	if err != nil {
+		// Telegram redelivers updates answered with an error, which would not
+		// help here, so the update is dropped.
+		c.log.Error("Failed to handle bot update", "updateId", update.UpdateId, "error", err.Error())
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if reply == nil {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, reply)
+}
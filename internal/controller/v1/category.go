@@ -102,6 +102,7 @@ func (c *CategoryController) GetAllCategories(w http.ResponseWriter, r *http.Req
 //	@Failure	500			{object}	errx.AppError
 //	@Router		/api/v1/categories [post]
 //	@Security	BearerAuth
+//	@Security	ApiKeyAuth
 func (c *CategoryController) CreateCategory(w http.ResponseWriter, r *http.Request) {
 	categoryRequest := &categorySchemas.RequestModel{}
 	if err := render.Bind(r, categoryRequest); err != nil {
@@ -142,6 +143,7 @@ func (c *CategoryController) CreateCategory(w http.ResponseWriter, r *http.Reque
 //	@Failure	500			{object}	errx.AppError
 //	@Router		/api/v1/categories/{id} [put]
 //	@Security	BearerAuth
+//	@Security	ApiKeyAuth
 func (c *CategoryController) UpdateCategory(w http.ResponseWriter, r *http.Request) {
 	strId := chi.URLParam(r, "id")
 	id, err := uuid.Parse(strId)
@@ -189,6 +191,7 @@ func (c *CategoryController) UpdateCategory(w http.ResponseWriter, r *http.Reque
 //	@Failure	500	{object}	errx.AppError
 //	@Router		/api/v1/categories/{id} [delete]
 //	@Security	BearerAuth
+//	@Security	ApiKeyAuth
 func (c *CategoryController) DeleteCategory(w http.ResponseWriter, r *http.Request) {
 	strId := chi.URLParam(r, "id")
 	id, err := uuid.Parse(strId)
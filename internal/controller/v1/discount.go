@@ -0,0 +1,317 @@
+package v1
+
+import (
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/errx"
+	"github.com/levchenki/tea-api/internal/logx"
+	"github.com/levchenki/tea-api/internal/schemas"
+	"github.com/levchenki/tea-api/internal/schemas/discountSchemas"
+	"net/http"
+)
+
+type DiscountService interface {
+	GetAll() ([]entity.Discount, error)
+	GetById(id uuid.UUID) (*entity.Discount, error)
+	Create(discount *entity.Discount) (*entity.Discount, error)
+	Update(id uuid.UUID, discount *entity.Discount) (*entity.Discount, error)
+	Delete(id uuid.UUID) error
+	GetReport(filters *discountSchemas.ReportFilters) ([]entity.DiscountReport, error)
+	GetRedemptions(id uuid.UUID, filters *discountSchemas.RedemptionFilters) ([]entity.DiscountRedemption, uint64, error)
+}
+
+type DiscountController struct {
+	discountService DiscountService
+	log             logx.AppLogger
+}
+
+func NewDiscountController(discountService DiscountService, log logx.AppLogger) *DiscountController {
+	return &DiscountController{
+		discountService: discountService,
+		log:             log,
+	}
+}
+
+// GetAllDiscounts godoc
+//
+//	@Summary	Return promo codes and automatic discount rules
+//	@Tags		Discounts
+//	@Accept		json
+//	@Produce	json
+//	@Success	200	{array}		discountSchemas.ResponseModel
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	403	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/discounts [get]
+//	@Security	BearerAuth
+func (c *DiscountController) GetAllDiscounts(w http.ResponseWriter, r *http.Request) {
+	discounts, err := c.discountService.GetAll()
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	response := make([]*discountSchemas.ResponseModel, len(discounts))
+	for i := range discounts {
+		response[i] = discountSchemas.NewResponseModel(&discounts[i])
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response)
+}
+
+// GetDiscountById godoc
+//
+//	@Summary	Return discount
+//	@Tags		Discounts
+//	@Accept		json
+//	@Produce	json
+//	@Param		id	path		string	true	"Discount ID"
+//	@Success	200	{object}	discountSchemas.ResponseModel
+//	@Failure	400	{object}	errx.AppError
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	403	{object}	errx.AppError
+//	@Failure	404	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/discounts/{id} [get]
+//	@Security	BearerAuth
+func (c *DiscountController) GetDiscountById(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	discount, err := c.discountService.GetById(id)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, discountSchemas.NewResponseModel(discount))
+}
+
+// CreateDiscount godoc
+//
+//	@Summary		Create promo code or automatic discount rule
+//	@Description	A discount with a code is a promo code, one without a code applies to every cart that matches it. Only the greatest automatic discount applies to a cart, a promo code applies on top of it.
+//	@Tags			Discounts
+//	@Accept			json
+//	@Produce		json
+//	@Param			discount	body		discountSchemas.RequestModel	true	"Discount"
+//	@Success		201			{object}	discountSchemas.ResponseModel
+//	@Failure		400			{object}	errx.AppError
+//	@Failure		401			{object}	errx.AppError
+//	@Failure		403			{object}	errx.AppError
+//	@Failure		404			{object}	errx.AppError
+//	@Failure		500			{object}	errx.AppError
+//	@Router			/api/v1/discounts [post]
+//	@Security		BearerAuth
+func (c *DiscountController) CreateDiscount(w http.ResponseWriter, r *http.Request) {
+	request := &discountSchemas.RequestModel{}
+	if err := render.Bind(r, request); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	discount, err := c.discountService.Create(newDiscount(request))
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, discountSchemas.NewResponseModel(discount))
+}
+
+// UpdateDiscount godoc
+//
+//	@Summary	Update discount
+//	@Tags		Discounts
+//	@Accept		json
+//	@Produce	json
+//	@Param		id			path		string							true	"Discount ID"
+//	@Param		discount	body		discountSchemas.RequestModel	true	"Discount"
+//	@Success	200			{object}	discountSchemas.ResponseModel
+//	@Failure	400			{object}	errx.AppError
+//	@Failure	401			{object}	errx.AppError
+//	@Failure	403			{object}	errx.AppError
+//	@Failure	404			{object}	errx.AppError
+//	@Failure	500			{object}	errx.AppError
+//	@Router		/api/v1/discounts/{id} [put]
+//	@Security	BearerAuth
+func (c *DiscountController) UpdateDiscount(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	request := &discountSchemas.RequestModel{}
+	if err := render.Bind(r, request); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	discount, err := c.discountService.Update(id, newDiscount(request))
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, discountSchemas.NewResponseModel(discount))
+}
+
+// DeleteDiscount godoc
+//
+//	@Summary		Delete discount
+//	@Description	The discount stops applying and is no longer listed, its redemptions stay in the report.
+//	@Tags			Discounts
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Discount ID"
+//	@Success		200	{object}	bool
+//	@Failure		400	{object}	errx.AppError
+//	@Failure		401	{object}	errx.AppError
+//	@Failure		403	{object}	errx.AppError
+//	@Failure		404	{object}	errx.AppError
+//	@Failure		500	{object}	errx.AppError
+//	@Router			/api/v1/discounts/{id} [delete]
+//	@Security		BearerAuth
+func (c *DiscountController) DeleteDiscount(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	err = c.discountService.Delete(id)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, true)
+}
+
+// GetDiscountReport godoc
+//
+//	@Summary		Return redemptions report of discounts
+//	@Description	Sums up the redemptions of every discount made in the period. Redemptions of cancelled orders are not counted.
+//	@Tags			Discounts
+//	@Accept			json
+//	@Produce		json
+//	@Param			from	query		string	false	"Start of the period, RFC 3339"
+//	@Param			to		query		string	false	"End of the period, RFC 3339"
+//	@Success		200		{array}		discountSchemas.ReportResponseModel
+//	@Failure		400		{object}	errx.AppError
+//	@Failure		401		{object}	errx.AppError
+//	@Failure		403		{object}	errx.AppError
+//	@Failure		500		{object}	errx.AppError
+//	@Router			/api/v1/discounts/report [get]
+//	@Security		BearerAuth
+func (c *DiscountController) GetDiscountReport(w http.ResponseWriter, r *http.Request) {
+	filters := &discountSchemas.ReportFilters{}
+	if err := filters.Validate(r); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	reports, err := c.discountService.GetReport(filters)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	response := make([]*discountSchemas.ReportResponseModel, len(reports))
+	for i := range reports {
+		response[i] = discountSchemas.NewReportResponseModel(&reports[i])
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response)
+}
+
+// GetDiscountRedemptions godoc
+//
+//	@Summary	Return redemptions of discount
+//	@Tags		Discounts
+//	@Accept		json
+//	@Produce	json
+//	@Param		id		path		string	true	"Discount ID"
+//	@Param		limit	query		int		false	"Limit"
+//	@Param		page	query		int		false	"Page"
+//	@Success	200		{object}	schemas.PaginatedResult[discountSchemas.RedemptionResponseModel]
+//	@Failure	400		{object}	errx.AppError
+//	@Failure	401		{object}	errx.AppError
+//	@Failure	403		{object}	errx.AppError
+//	@Failure	404		{object}	errx.AppError
+//	@Failure	500		{object}	errx.AppError
+//	@Router		/api/v1/discounts/{id}/redemptions [get]
+//	@Security	BearerAuth
+func (c *DiscountController) GetDiscountRedemptions(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	filters := &discountSchemas.RedemptionFilters{}
+	if err := filters.Validate(r); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	redemptions, total, err := c.discountService.GetRedemptions(id, filters)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	response := make([]*discountSchemas.RedemptionResponseModel, len(redemptions))
+	for i := range redemptions {
+		response[i] = discountSchemas.NewRedemptionResponseModel(&redemptions[i])
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, schemas.PaginatedResult[*discountSchemas.RedemptionResponseModel]{
+		Total: total,
+		Items: response,
+	})
+}
+
+func newDiscount(request *discountSchemas.RequestModel) *entity.Discount {
+	isActive := true
+	if request.IsActive != nil {
+		isActive = *request.IsActive
+	}
+
+	return &entity.Discount{
+		Name:           request.Name,
+		Code:           request.Code,
+		Kind:           request.Kind,
+		Value:          request.Value,
+		Scope:          request.Scope,
+		TargetId:       request.TargetId,
+		MinOrderAmount: request.MinOrderAmount,
+		StartsAt:       request.StartsAt,
+		EndsAt:         request.EndsAt,
+		UsageLimit:     request.UsageLimit,
+		PerUserLimit:   request.PerUserLimit,
+		IsActive:       isActive,
+	}
+}
@@ -0,0 +1,411 @@
+package v1
+
+import (
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/errx"
+	"github.com/levchenki/tea-api/internal/icsx"
+	"github.com/levchenki/tea-api/internal/logx"
+	"github.com/levchenki/tea-api/internal/schemas"
+	"github.com/levchenki/tea-api/internal/schemas/eventSchemas"
+	"github.com/levchenki/tea-api/internal/schemas/userSchemas"
+	"github.com/levchenki/tea-api/internal/tgx"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const eventCalendarProductId = "-//tea-api//events//EN"
+
+type EventService interface {
+	GetAll(filters *eventSchemas.Filters) ([]entity.Event, uint64, error)
+	GetById(id, userId uuid.UUID) (*entity.Event, error)
+	Create(event *entity.Event, teaIds []uuid.UUID) (*entity.Event, error)
+	Update(id uuid.UUID, event *entity.Event, teaIds []uuid.UUID) (*entity.Event, error)
+	Delete(id uuid.UUID) error
+	Register(id, userId uuid.UUID) (*entity.Event, error)
+	Cancel(id, userId uuid.UUID) (*entity.Event, error)
+	GetAttendees(id uuid.UUID) ([]entity.EventAttendee, error)
+}
+
+type EventController struct {
+	botName      string
+	miniAppName  string
+	eventService EventService
+	log          logx.AppLogger
+}
+
+func NewEventController(botName, miniAppName string, eventService EventService, log logx.AppLogger) *EventController {
+	return &EventController{
+		botName:      botName,
+		miniAppName:  miniAppName,
+		eventService: eventService,
+		log:          log,
+	}
+}
+
+// GetAllEvents godoc
+//
+//	@Summary		Return tasting events
+//	@Description	Upcoming events go from the nearest, past ones from the latest. Signed in users see their registration status.
+//	@Tags			Event
+//	@Accept			json
+//	@Produce		json
+//	@Param			limit		query		int		false	"Limit"
+//	@Param			page		query		int		false	"Page"
+//	@Param			isPast		query		bool	false	"Return past events instead of upcoming ones"
+//	@Param			locationId	query		string	false	"Location ID"
+//	@Success		200			{object}	schemas.PaginatedResult[eventSchemas.ResponseModel]
+//	@Failure		400			{object}	errx.AppError
+//	@Failure		404			{object}	errx.AppError
+//	@Failure		500			{object}	errx.AppError
+//	@Router			/api/v1/events [get]
+func (c *EventController) GetAllEvents(w http.ResponseWriter, r *http.Request) {
+	filters := &eventSchemas.Filters{}
+	if err := filters.Validate(r); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+	filters.UserId = optionalUserId(r)
+
+	events, total, err := c.eventService.GetAll(filters)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	response := make([]*eventSchemas.ResponseModel, len(events))
+	for i := range events {
+		response[i] = eventSchemas.NewResponseModel(&events[i])
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, schemas.PaginatedResult[*eventSchemas.ResponseModel]{
+		Total: total,
+		Items: response,
+	})
+}
+
+// GetEventById godoc
+//
+//	@Summary	Return tasting event by ID
+//	@Tags		Event
+//	@Accept		json
+//	@Produce	json
+//	@Param		id	path		string	true	"Event ID"
+//	@Success	200	{object}	eventSchemas.ResponseModel
+//	@Failure	400	{object}	errx.AppError
+//	@Failure	404	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/events/{id} [get]
+func (c *EventController) GetEventById(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	event, err := c.eventService.GetById(id, optionalUserId(r))
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, eventSchemas.NewResponseModel(event))
+}
+
+// GetEventCalendar godoc
+//
+//	@Summary	Export tasting event to calendar
+//	@Tags		Event
+//	@Produce	text/calendar
+//	@Param		id	path		string	true	"Event ID"
+//	@Success	200	{string}	string	"iCalendar file"
+//	@Failure	400	{object}	errx.AppError
+//	@Failure	404	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/events/{id}/calendar.ics [get]
+func (c *EventController) GetEventCalendar(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	event, err := c.eventService.GetById(id, uuid.Nil)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	locationParts := make([]string, 0, 2)
+	for _, part := range []string{event.LocationName, event.LocationAddress} {
+		if part != "" {
+			locationParts = append(locationParts, part)
+		}
+	}
+
+	calendar := icsx.Calendar(eventCalendarProductId, icsx.Event{
+		UID:         event.Id.String() + "@tea-api",
+		Summary:     event.Title,
+		Description: event.Description,
+		Location:    strings.Join(locationParts, ", "),
+		URL:         tgx.MiniAppLink(c.botName, c.miniAppName, "event_"+event.Id.String()),
+		Start:       event.StartsAt,
+		End:         event.EndsAt,
+		Stamp:       time.Now(),
+	})
+
+	w.Header().Set("Content-Type", icsx.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="event-%s.ics"`, event.Id.String()))
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(calendar)
+	if err != nil {
+		c.log.Error("Failed to write event calendar", "eventId", event.Id.String(), "error", err.Error())
+	}
+}
+
+// CreateEvent godoc
+//
+//	@Summary	Create tasting event
+//	@Tags		Event
+//	@Accept		json
+//	@Produce	json
+//	@Param		event	body		eventSchemas.RequestModel	true	"Event"
+//	@Success	201		{object}	eventSchemas.ResponseModel
+//	@Failure	400		{object}	errx.AppError
+//	@Failure	401		{object}	errx.AppError
+//	@Failure	403		{object}	errx.AppError
+//	@Failure	404		{object}	errx.AppError
+//	@Failure	500		{object}	errx.AppError
+//	@Router		/api/v1/events [post]
+//	@Security	BearerAuth
+func (c *EventController) CreateEvent(w http.ResponseWriter, r *http.Request) {
+	request := &eventSchemas.RequestModel{}
+	if err := render.Bind(r, request); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	event, err := c.eventService.Create(newEvent(request), request.TeaIds)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, eventSchemas.NewResponseModel(event))
+}
+
+// UpdateEvent godoc
+//
+//	@Summary		Update tasting event
+//	@Description	Waitlisted users take the places added by a greater capacity. Registered users keep their places if the capacity is reduced.
+//	@Tags			Event
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string						true	"Event ID"
+//	@Param			event	body		eventSchemas.RequestModel	true	"Event"
+//	@Success		200		{object}	eventSchemas.ResponseModel
+//	@Failure		400		{object}	errx.AppError
+//	@Failure		401		{object}	errx.AppError
+//	@Failure		403		{object}	errx.AppError
+//	@Failure		404		{object}	errx.AppError
+//	@Failure		500		{object}	errx.AppError
+//	@Router			/api/v1/events/{id} [put]
+//	@Security		BearerAuth
+func (c *EventController) UpdateEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	request := &eventSchemas.RequestModel{}
+	if err := render.Bind(r, request); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	event, err := c.eventService.Update(id, newEvent(request), request.TeaIds)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, eventSchemas.NewResponseModel(event))
+}
+
+// DeleteEvent godoc
+//
+//	@Summary	Delete tasting event
+//	@Tags		Event
+//	@Accept		json
+//	@Produce	json
+//	@Param		id	path		string	true	"Event ID"
+//	@Success	200	{object}	bool
+//	@Failure	400	{object}	errx.AppError
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	403	{object}	errx.AppError
+//	@Failure	404	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/events/{id} [delete]
+//	@Security	BearerAuth
+func (c *EventController) DeleteEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	err = c.eventService.Delete(id)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, true)
+}
+
+// GetEventAttendees godoc
+//
+//	@Summary	Return attendees of tasting event
+//	@Tags		Event
+//	@Accept		json
+//	@Produce	json
+//	@Param		id	path		string	true	"Event ID"
+//	@Success	200	{array}		eventSchemas.AttendeeResponseModel
+//	@Failure	400	{object}	errx.AppError
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	403	{object}	errx.AppError
+//	@Failure	404	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/events/{id}/attendees [get]
+//	@Security	BearerAuth
+func (c *EventController) GetEventAttendees(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	attendees, err := c.eventService.GetAttendees(id)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	response := make([]*eventSchemas.AttendeeResponseModel, len(attendees))
+	for i := range attendees {
+		response[i] = eventSchemas.NewAttendeeResponseModel(&attendees[i])
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response)
+}
+
+// RegisterForEvent godoc
+//
+//	@Summary		Sign up for tasting event
+//	@Description	A full event puts the user on the waitlist. Signing up again keeps the existing registration.
+//	@Tags			Event
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Event ID"
+//	@Success		200	{object}	eventSchemas.ResponseModel
+//	@Failure		400	{object}	errx.AppError
+//	@Failure		401	{object}	errx.AppError
+//	@Failure		404	{object}	errx.AppError
+//	@Failure		500	{object}	errx.AppError
+//	@Router			/api/v1/events/{id}/registration [post]
+//	@Security		BearerAuth
+func (c *EventController) RegisterForEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	event, err := c.eventService.Register(id, userClaims.Id)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, eventSchemas.NewResponseModel(event))
+}
+
+// CancelEventRegistration godoc
+//
+//	@Summary		Cancel sign-up for tasting event
+//	@Description	The freed place goes to the first user on the waitlist.
+//	@Tags			Event
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Event ID"
+//	@Success		200	{object}	eventSchemas.ResponseModel
+//	@Failure		400	{object}	errx.AppError
+//	@Failure		401	{object}	errx.AppError
+//	@Failure		404	{object}	errx.AppError
+//	@Failure		500	{object}	errx.AppError
+//	@Router			/api/v1/events/{id}/registration [delete]
+//	@Security		BearerAuth
+func (c *EventController) CancelEventRegistration(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	event, err := c.eventService.Cancel(id, userClaims.Id)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, eventSchemas.NewResponseModel(event))
+}
+
+func newEvent(request *eventSchemas.RequestModel) *entity.Event {
+	return &entity.Event{
+		Title:       request.Title,
+		Description: request.Description,
+		StartsAt:    request.StartsAt,
+		EndsAt:      request.EndsAt,
+		Capacity:    request.Capacity,
+		Price:       request.Price,
+		LocationId:  request.LocationId,
+	}
+}
+
+// optionalUserId returns the id of the signed in user on routes where
+// signing in is optional.
+func optionalUserId(r *http.Request) uuid.UUID {
+	userClaims, ok := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+	if !ok {
+		return uuid.Nil
+	}
+	return userClaims.Id
+}
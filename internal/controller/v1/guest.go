@@ -0,0 +1,174 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/errx"
+	"github.com/levchenki/tea-api/internal/jwtx"
+	"github.com/levchenki/tea-api/internal/logx"
+	"github.com/levchenki/tea-api/internal/schemas/guestSchemas"
+	"net/http"
+	"strconv"
+)
+
+const guestTokenHeader = "X-Guest-Token"
+
+type GuestService interface {
+	CreateGuest(jwtKeys *jwtx.KeySet) (string, error)
+	Authenticate(guestToken string, jwtKeys *jwtx.KeySet) (uuid.UUID, error)
+	GetMarks(guestId uuid.UUID) ([]entity.GuestTeaMark, error)
+	ToggleMark(guestId, teaId uuid.UUID, mark string, isSet bool) error
+	MergeIntoUser(guestToken string, userId uuid.UUID, jwtKeys *jwtx.KeySet) error
+}
+
+type GuestController struct {
+	jwtKeys      *jwtx.KeySet
+	guestService GuestService
+	log          logx.AppLogger
+}
+
+func NewGuestController(jwtKeys *jwtx.KeySet, guestService GuestService, log logx.AppLogger) *GuestController {
+	return &GuestController{
+		jwtKeys:      jwtKeys,
+		guestService: guestService,
+		log:          log,
+	}
+}
+
+// CreateGuest godoc
+//
+//	@Summary		Create guest
+//	@Description	Returns a token for an anonymous visitor. Send it in the X-Guest-Token header to keep marks before signing in, and to the sign-in endpoints to move the marks to the account.
+//	@Tags			Guest
+//	@Accept			json
+//	@Produce		json
+//	@Success		201	{object}	guestSchemas.TokenResponse
+//	@Failure		500	{object}	errx.AppError
+//	@Router			/api/v1/guest [post]
+func (c *GuestController) CreateGuest(w http.ResponseWriter, r *http.Request) {
+	guestToken, err := c.guestService.CreateGuest(c.jwtKeys)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, &guestSchemas.TokenResponse{GuestToken: guestToken})
+}
+
+// GetGuestMarks godoc
+//
+//	@Summary	Return favourites and "want to try" marks of the guest
+//	@Tags		Guest
+//	@Accept		json
+//	@Produce	json
+//	@Param		X-Guest-Token	header		string	true	"Guest token"
+//	@Success	200				{object}	[]guestSchemas.MarkResponseModel
+//	@Failure	401				{object}	errx.AppError
+//	@Failure	500				{object}	errx.AppError
+//	@Router		/api/v1/guest/marks [get]
+func (c *GuestController) GetGuestMarks(w http.ResponseWriter, r *http.Request) {
+	guestId := r.Context().Value("guestId").(uuid.UUID)
+
+	marks, err := c.guestService.GetMarks(guestId)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	response := make([]*guestSchemas.MarkResponseModel, len(marks))
+	for i := range marks {
+		response[i] = guestSchemas.NewMarkResponseModel(&marks[i])
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response)
+}
+
+// ToggleGuestFavourite godoc
+//
+//	@Summary	Add or remove tea to/from favourites of the guest
+//	@Tags		Guest
+//	@Accept		json
+//	@Produce	json
+//	@Param		X-Guest-Token	header	string	true	"Guest token"
+//	@Param		id				path	string	true	"Tea ID"
+//	@Param		isFavourite		query	bool	false	"Is favourite status"
+//	@Success	200
+//	@Failure	400	{object}	errx.AppError
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	404	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/guest/teas/{id}/favourite [post]
+func (c *GuestController) ToggleGuestFavourite(w http.ResponseWriter, r *http.Request) {
+	c.toggleMark(w, r, entity.GuestMarkFavourite, "isFavourite")
+}
+
+// ToggleGuestWantToTry godoc
+//
+//	@Summary	Add or remove "want to try" mark of the guest
+//	@Tags		Guest
+//	@Accept		json
+//	@Produce	json
+//	@Param		X-Guest-Token	header	string	true	"Guest token"
+//	@Param		id				path	string	true	"Tea ID"
+//	@Param		isWantToTry		query	bool	false	"Is want to try status"
+//	@Success	200
+//	@Failure	400	{object}	errx.AppError
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	404	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/guest/teas/{id}/want-to-try [post]
+func (c *GuestController) ToggleGuestWantToTry(w http.ResponseWriter, r *http.Request) {
+	c.toggleMark(w, r, entity.GuestMarkWantToTry, "isWantToTry")
+}
+
+func (c *GuestController) toggleMark(w http.ResponseWriter, r *http.Request, mark, queryParam string) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	isSet, err := strconv.ParseBool(r.URL.Query().Get(queryParam))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid %s", queryParam))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	guestId := r.Context().Value("guestId").(uuid.UUID)
+
+	err = c.guestService.ToggleMark(guestId, id, mark, isSet)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+}
+
+func (c *GuestController) GuestMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		guestToken := r.Header.Get(guestTokenHeader)
+		if guestToken == "" {
+			errResponse := errx.NewUnauthorizedError(fmt.Errorf("guest token is missing"))
+			handleError(w, r, c.log, errResponse)
+			return
+		}
+
+		guestId, err := c.guestService.Authenticate(guestToken, c.jwtKeys)
+		if err != nil {
+			handleError(w, r, c.log, err)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), "guestId", guestId)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,31 @@
+package v1
+
+import (
+	"github.com/go-chi/render"
+	"github.com/levchenki/tea-api/internal/jwtx"
+	"net/http"
+)
+
+type JWKSController struct {
+	jwtKeys *jwtx.KeySet
+}
+
+func NewJWKSController(jwtKeys *jwtx.KeySet) *JWKSController {
+	return &JWKSController{
+		jwtKeys: jwtKeys,
+	}
+}
+
+// GetJWKS godoc
+//
+//	@Summary		Return public keys for access token verification
+//	@Description	The list is empty when tokens are signed with HS256
+//	@Tags			Auth
+//	@Produce		json
+//	@Success		200	{object}	jwtx.JWKS
+//	@Router			/.well-known/jwks.json [get]
+func (c *JWKSController) GetJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, c.jwtKeys.JWKS())
+}
@@ -0,0 +1,491 @@
+package v1
+
+import (
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/errx"
+	"github.com/levchenki/tea-api/internal/logx"
+	"github.com/levchenki/tea-api/internal/schemas/listSchemas"
+	"github.com/levchenki/tea-api/internal/schemas/userSchemas"
+	"github.com/levchenki/tea-api/internal/tgx"
+	"net/http"
+)
+
+type ListService interface {
+	GetAll(userId uuid.UUID) ([]entity.TeaList, error)
+	GetById(id, userId uuid.UUID) (*entity.TeaList, error)
+	Create(userId uuid.UUID, name string) (*entity.TeaList, error)
+	Update(id, userId uuid.UUID, name string) (*entity.TeaList, error)
+	Delete(id, userId uuid.UUID) error
+
+	AddItem(id, userId, teaId uuid.UUID, note string) (*entity.TeaList, error)
+	UpdateItemNote(id, userId, teaId uuid.UUID, note string) (*entity.TeaList, error)
+	RemoveItem(id, userId, teaId uuid.UUID) error
+	Reorder(id, userId uuid.UUID, teaIds []uuid.UUID) (*entity.TeaList, error)
+
+	Share(id, userId uuid.UUID) (*entity.TeaList, error)
+	RevokeShare(id, userId uuid.UUID) error
+	GetShared(token string) (*entity.TeaList, error)
+}
+
+type ListController struct {
+	botName     string
+	miniAppName string
+	listService ListService
+	log         logx.AppLogger
+}
+
+func NewListController(botName, miniAppName string, listService ListService, log logx.AppLogger) *ListController {
+	return &ListController{
+		botName:     botName,
+		miniAppName: miniAppName,
+		listService: listService,
+		log:         log,
+	}
+}
+
+// GetAllLists godoc
+//
+//	@Summary	Return all personal lists of the current user
+//	@Tags		List
+//	@Accept		json
+//	@Produce	json
+//	@Success	200	{array}		listSchemas.ResponseModel
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/lists [get]
+//	@Security	BearerAuth
+func (c *ListController) GetAllLists(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	lists, err := c.listService.GetAll(userClaims.Id)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	response := make([]*listSchemas.ResponseModel, len(lists))
+	for i := range lists {
+		response[i] = listSchemas.NewResponseModel(&lists[i])
+	}
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response)
+}
+
+// GetListById godoc
+//
+//	@Summary	Return personal list with its teas
+//	@Tags		List
+//	@Accept		json
+//	@Produce	json
+//	@Param		id	path		string	true	"List ID"
+//	@Success	200	{object}	listSchemas.WithItemsResponseModel
+//	@Failure	400	{object}	errx.AppError
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	404	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/lists/{id} [get]
+//	@Security	BearerAuth
+func (c *ListController) GetListById(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	list, err := c.listService.GetById(id, userClaims.Id)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, listSchemas.NewWithItemsResponseModel(list))
+}
+
+// CreateList godoc
+//
+//	@Summary	Create personal list
+//	@Tags		List
+//	@Accept		json
+//	@Produce	json
+//	@Param		list	body		listSchemas.RequestModel	true	"List"
+//	@Success	201		{object}	listSchemas.WithItemsResponseModel
+//	@Failure	400		{object}	errx.AppError
+//	@Failure	401		{object}	errx.AppError
+//	@Failure	500		{object}	errx.AppError
+//	@Router		/api/v1/lists [post]
+//	@Security	BearerAuth
+func (c *ListController) CreateList(w http.ResponseWriter, r *http.Request) {
+	listRequest := &listSchemas.RequestModel{}
+	if err := render.Bind(r, listRequest); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	list, err := c.listService.Create(userClaims.Id, listRequest.Name)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, listSchemas.NewWithItemsResponseModel(list))
+}
+
+// UpdateList godoc
+//
+//	@Summary	Rename personal list
+//	@Tags		List
+//	@Accept		json
+//	@Produce	json
+//	@Param		id		path		string						true	"List ID"
+//	@Param		list	body		listSchemas.RequestModel	true	"List"
+//	@Success	200		{object}	listSchemas.WithItemsResponseModel
+//	@Failure	400		{object}	errx.AppError
+//	@Failure	401		{object}	errx.AppError
+//	@Failure	404		{object}	errx.AppError
+//	@Failure	500		{object}	errx.AppError
+//	@Router		/api/v1/lists/{id} [put]
+//	@Security	BearerAuth
+func (c *ListController) UpdateList(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	listRequest := &listSchemas.RequestModel{}
+	if err := render.Bind(r, listRequest); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	list, err := c.listService.Update(id, userClaims.Id, listRequest.Name)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, listSchemas.NewWithItemsResponseModel(list))
+}
+
+// DeleteList godoc
+//
+//	@Summary	Delete personal list
+//	@Tags		List
+//	@Accept		json
+//	@Produce	json
+//	@Param		id	path		string	true	"List ID"
+//	@Success	200	{object}	bool
+//	@Failure	400	{object}	errx.AppError
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	404	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/lists/{id} [delete]
+//	@Security	BearerAuth
+func (c *ListController) DeleteList(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	err = c.listService.Delete(id, userClaims.Id)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, true)
+}
+
+// AddListItem godoc
+//
+//	@Summary	Add tea to personal list
+//	@Tags		List
+//	@Accept		json
+//	@Produce	json
+//	@Param		id		path		string						true	"List ID"
+//	@Param		item	body		listSchemas.ItemRequestModel	true	"Item"
+//	@Success	200		{object}	listSchemas.WithItemsResponseModel
+//	@Failure	400		{object}	errx.AppError
+//	@Failure	401		{object}	errx.AppError
+//	@Failure	404		{object}	errx.AppError
+//	@Failure	500		{object}	errx.AppError
+//	@Router		/api/v1/lists/{id}/items [post]
+//	@Security	BearerAuth
+func (c *ListController) AddListItem(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	itemRequest := &listSchemas.ItemRequestModel{}
+	if err := render.Bind(r, itemRequest); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	list, err := c.listService.AddItem(id, userClaims.Id, itemRequest.TeaId, itemRequest.Note)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, listSchemas.NewWithItemsResponseModel(list))
+}
+
+// UpdateListItem godoc
+//
+//	@Summary	Update note of tea in personal list
+//	@Tags		List
+//	@Accept		json
+//	@Produce	json
+//	@Param		id		path		string							true	"List ID"
+//	@Param		teaId	path		string							true	"Tea ID"
+//	@Param		item	body		listSchemas.ItemNoteRequestModel	true	"Note"
+//	@Success	200		{object}	listSchemas.WithItemsResponseModel
+//	@Failure	400		{object}	errx.AppError
+//	@Failure	401		{object}	errx.AppError
+//	@Failure	404		{object}	errx.AppError
+//	@Failure	500		{object}	errx.AppError
+//	@Router		/api/v1/lists/{id}/items/{teaId} [put]
+//	@Security	BearerAuth
+func (c *ListController) UpdateListItem(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	teaId, err := uuid.Parse(chi.URLParam(r, "teaId"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid tea id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	noteRequest := &listSchemas.ItemNoteRequestModel{}
+	if err := render.Bind(r, noteRequest); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	list, err := c.listService.UpdateItemNote(id, userClaims.Id, teaId, noteRequest.Note)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, listSchemas.NewWithItemsResponseModel(list))
+}
+
+// DeleteListItem godoc
+//
+//	@Summary	Remove tea from personal list
+//	@Tags		List
+//	@Accept		json
+//	@Produce	json
+//	@Param		id		path		string	true	"List ID"
+//	@Param		teaId	path		string	true	"Tea ID"
+//	@Success	200		{object}	bool
+//	@Failure	400		{object}	errx.AppError
+//	@Failure	401		{object}	errx.AppError
+//	@Failure	404		{object}	errx.AppError
+//	@Failure	500		{object}	errx.AppError
+//	@Router		/api/v1/lists/{id}/items/{teaId} [delete]
+//	@Security	BearerAuth
+func (c *ListController) DeleteListItem(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	teaId, err := uuid.Parse(chi.URLParam(r, "teaId"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid tea id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	err = c.listService.RemoveItem(id, userClaims.Id, teaId)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, true)
+}
+
+// ReorderListItems godoc
+//
+//	@Summary	Reorder teas in personal list
+//	@Tags		List
+//	@Accept		json
+//	@Produce	json
+//	@Param		id		path		string							true	"List ID"
+//	@Param		order	body		listSchemas.OrderRequestModel	true	"Tea IDs in the new order"
+//	@Success	200		{object}	listSchemas.WithItemsResponseModel
+//	@Failure	400		{object}	errx.AppError
+//	@Failure	401		{object}	errx.AppError
+//	@Failure	404		{object}	errx.AppError
+//	@Failure	500		{object}	errx.AppError
+//	@Router		/api/v1/lists/{id}/items/order [put]
+//	@Security	BearerAuth
+func (c *ListController) ReorderListItems(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	orderRequest := &listSchemas.OrderRequestModel{}
+	if err := render.Bind(r, orderRequest); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	list, err := c.listService.Reorder(id, userClaims.Id, orderRequest.TeaIds)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, listSchemas.NewWithItemsResponseModel(list))
+}
+
+// ShareList godoc
+//
+//	@Summary	Create a public share link for personal list
+//	@Tags		List
+//	@Accept		json
+//	@Produce	json
+//	@Param		id	path		string	true	"List ID"
+//	@Success	200	{object}	listSchemas.ShareResponseModel
+//	@Failure	400	{object}	errx.AppError
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	404	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/lists/{id}/share [post]
+//	@Security	BearerAuth
+func (c *ListController) ShareList(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	list, err := c.listService.Share(id, userClaims.Id)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, listSchemas.NewShareResponseModel(list.ShareToken, c.shareLink(list.ShareToken)))
+}
+
+// RevokeListShare godoc
+//
+//	@Summary	Revoke the public share link of personal list
+//	@Tags		List
+//	@Accept		json
+//	@Produce	json
+//	@Param		id	path		string	true	"List ID"
+//	@Success	200	{object}	bool
+//	@Failure	400	{object}	errx.AppError
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	404	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/lists/{id}/share [delete]
+//	@Security	BearerAuth
+func (c *ListController) RevokeListShare(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	err = c.listService.RevokeShare(id, userClaims.Id)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, true)
+}
+
+// GetSharedList godoc
+//
+//	@Summary	Return shared list by its public token
+//	@Tags		List
+//	@Accept		json
+//	@Produce	json
+//	@Param		token	path		string	true	"Share token"
+//	@Success	200		{object}	listSchemas.SharedResponseModel
+//	@Failure	404		{object}	errx.AppError
+//	@Failure	500		{object}	errx.AppError
+//	@Router		/api/v1/shared/lists/{token} [get]
+func (c *ListController) GetSharedList(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+
+	list, err := c.listService.GetShared(token)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, listSchemas.NewSharedResponseModel(list, c.shareLink(token)))
+}
+
+func (c *ListController) shareLink(token string) string {
+	return tgx.MiniAppLink(c.botName, c.miniAppName, "list_"+token)
+}
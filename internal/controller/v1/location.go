@@ -0,0 +1,459 @@
+package v1
+
+import (
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/errx"
+	"github.com/levchenki/tea-api/internal/logx"
+	"github.com/levchenki/tea-api/internal/schemas/locationSchemas"
+	"net/http"
+)
+
+type LocationService interface {
+	GetAll() ([]entity.Location, error)
+	GetById(id uuid.UUID) (*entity.Location, error)
+	Create(location *entity.Location) (*entity.Location, error)
+	Update(id uuid.UUID, location *entity.Location) (*entity.Location, error)
+	Delete(id uuid.UUID) error
+
+	GetOverride(locationId, teaId uuid.UUID) (*entity.TeaLocationOverride, error)
+	SetVisibility(locationId, teaId uuid.UUID, isHidden *bool) (*entity.TeaLocationOverride, error)
+	SetStock(locationId, teaId uuid.UUID, stock *float64) (*entity.TeaLocationOverride, error)
+	SetPrices(locationId, teaId uuid.UUID, servePrice, unitPrice *float64) (*entity.TeaLocationOverride, error)
+	DeleteOverride(locationId, teaId uuid.UUID) error
+
+	SetUserLocation(userId, locationId uuid.UUID) error
+}
+
+type LocationController struct {
+	locationService LocationService
+	log             logx.AppLogger
+}
+
+func NewLocationController(locationService LocationService, log logx.AppLogger) *LocationController {
+	return &LocationController{
+		locationService: locationService,
+		log:             log,
+	}
+}
+
+// GetAllLocations godoc
+//
+//	@Summary	Return all locations
+//	@Tags		Location
+//	@Accept		json
+//	@Produce	json
+//	@Success	200	{array}		locationSchemas.ResponseModel
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/locations [get]
+func (c *LocationController) GetAllLocations(w http.ResponseWriter, r *http.Request) {
+	locations, err := c.locationService.GetAll()
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	response := make([]*locationSchemas.ResponseModel, len(locations))
+	for i := range locations {
+		response[i] = locationSchemas.NewResponseModel(&locations[i])
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response)
+}
+
+// GetLocationById godoc
+//
+//	@Summary	Return location by ID
+//	@Tags		Location
+//	@Accept		json
+//	@Produce	json
+//	@Param		id	path		string	true	"Location ID"
+//	@Success	200	{object}	locationSchemas.ResponseModel
+//	@Failure	400	{object}	errx.AppError
+//	@Failure	404	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/locations/{id} [get]
+func (c *LocationController) GetLocationById(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	location, err := c.locationService.GetById(id)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, locationSchemas.NewResponseModel(location))
+}
+
+// CreateLocation godoc
+//
+//	@Summary	Create location
+//	@Tags		Location
+//	@Accept		json
+//	@Produce	json
+//	@Param		location	body		locationSchemas.RequestModel	true	"Location"
+//	@Success	201			{object}	locationSchemas.ResponseModel
+//	@Failure	400			{object}	errx.AppError
+//	@Failure	401			{object}	errx.AppError
+//	@Failure	403			{object}	errx.AppError
+//	@Failure	500			{object}	errx.AppError
+//	@Router		/api/v1/locations [post]
+//	@Security	BearerAuth
+func (c *LocationController) CreateLocation(w http.ResponseWriter, r *http.Request) {
+	request := &locationSchemas.RequestModel{}
+	if err := render.Bind(r, request); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	location, err := c.locationService.Create(&entity.Location{
+		Name:    request.Name,
+		Address: request.Address,
+	})
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, locationSchemas.NewResponseModel(location))
+}
+
+// UpdateLocation godoc
+//
+//	@Summary	Update location
+//	@Tags		Location
+//	@Accept		json
+//	@Produce	json
+//	@Param		id			path		string							true	"Location ID"
+//	@Param		location	body		locationSchemas.RequestModel	true	"Location"
+//	@Success	200			{object}	locationSchemas.ResponseModel
+//	@Failure	400			{object}	errx.AppError
+//	@Failure	401			{object}	errx.AppError
+//	@Failure	403			{object}	errx.AppError
+//	@Failure	404			{object}	errx.AppError
+//	@Failure	500			{object}	errx.AppError
+//	@Router		/api/v1/locations/{id} [put]
+//	@Security	BearerAuth
+func (c *LocationController) UpdateLocation(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	request := &locationSchemas.RequestModel{}
+	if err := render.Bind(r, request); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	location, err := c.locationService.Update(id, &entity.Location{
+		Name:    request.Name,
+		Address: request.Address,
+	})
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, locationSchemas.NewResponseModel(location))
+}
+
+// DeleteLocation godoc
+//
+//	@Summary		Delete location
+//	@Description	The default location can not be deleted.
+//	@Tags			Location
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		string	true	"Location ID"
+//	@Success		200	{object}	bool
+//	@Failure		400	{object}	errx.AppError
+//	@Failure		401	{object}	errx.AppError
+//	@Failure		403	{object}	errx.AppError
+//	@Failure		404	{object}	errx.AppError
+//	@Failure		500	{object}	errx.AppError
+//	@Router			/api/v1/locations/{id} [delete]
+//	@Security		BearerAuth
+func (c *LocationController) DeleteLocation(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	err = c.locationService.Delete(id)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, true)
+}
+
+// GetTeaOverride godoc
+//
+//	@Summary		Return values of tea overridden in location
+//	@Description	Null values fall back to the catalogue values of the tea.
+//	@Tags			Location
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string	true	"Location ID"
+//	@Param			teaId		path		string	true	"Tea ID"
+//	@Success		200			{object}	locationSchemas.OverrideResponseModel
+//	@Failure		400			{object}	errx.AppError
+//	@Failure		404			{object}	errx.AppError
+//	@Failure		500			{object}	errx.AppError
+//	@Router			/api/v1/locations/{id}/teas/{teaId} [get]
+func (c *LocationController) GetTeaOverride(w http.ResponseWriter, r *http.Request) {
+	locationId, teaId, err := parseLocationTeaIds(r)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	override, err := c.locationService.GetOverride(locationId, teaId)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, locationSchemas.NewOverrideResponseModel(override))
+}
+
+// SetTeaVisibilityOverride godoc
+//
+//	@Summary		Override visibility of tea in location
+//	@Description	Null falls back to the catalogue visibility.
+//	@Tags			Location
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string									true	"Location ID"
+//	@Param			teaId		path		string									true	"Tea ID"
+//	@Param			visibility	body		locationSchemas.VisibilityRequestModel	true	"Visibility"
+//	@Success		200			{object}	locationSchemas.OverrideResponseModel
+//	@Failure		400			{object}	errx.AppError
+//	@Failure		401			{object}	errx.AppError
+//	@Failure		403			{object}	errx.AppError
+//	@Failure		404			{object}	errx.AppError
+//	@Failure		500			{object}	errx.AppError
+//	@Router			/api/v1/locations/{id}/teas/{teaId}/visibility [patch]
+//	@Security		BearerAuth
+func (c *LocationController) SetTeaVisibilityOverride(w http.ResponseWriter, r *http.Request) {
+	locationId, teaId, err := parseLocationTeaIds(r)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	request := &locationSchemas.VisibilityRequestModel{}
+	if err := render.Bind(r, request); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	override, err := c.locationService.SetVisibility(locationId, teaId, request.IsHidden)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, locationSchemas.NewOverrideResponseModel(override))
+}
+
+// SetTeaStockOverride godoc
+//
+//	@Summary		Override stock of tea in location
+//	@Description	Null falls back to the catalogue stock.
+//	@Tags			Location
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string								true	"Location ID"
+//	@Param			teaId		path		string								true	"Tea ID"
+//	@Param			stock		body		locationSchemas.StockRequestModel	true	"Stock"
+//	@Success		200			{object}	locationSchemas.OverrideResponseModel
+//	@Failure		400			{object}	errx.AppError
+//	@Failure		401			{object}	errx.AppError
+//	@Failure		403			{object}	errx.AppError
+//	@Failure		404			{object}	errx.AppError
+//	@Failure		500			{object}	errx.AppError
+//	@Router			/api/v1/locations/{id}/teas/{teaId}/stock [patch]
+//	@Security		BearerAuth
+func (c *LocationController) SetTeaStockOverride(w http.ResponseWriter, r *http.Request) {
+	locationId, teaId, err := parseLocationTeaIds(r)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	request := &locationSchemas.StockRequestModel{}
+	if err := render.Bind(r, request); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	override, err := c.locationService.SetStock(locationId, teaId, request.Stock)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, locationSchemas.NewOverrideResponseModel(override))
+}
+
+// SetTeaPricesOverride godoc
+//
+//	@Summary		Override prices of tea in location
+//	@Description	Null prices fall back to the catalogue prices.
+//	@Tags			Location
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string								true	"Location ID"
+//	@Param			teaId		path		string								true	"Tea ID"
+//	@Param			prices		body		locationSchemas.PricesRequestModel	true	"Prices"
+//	@Success		200			{object}	locationSchemas.OverrideResponseModel
+//	@Failure		400			{object}	errx.AppError
+//	@Failure		401			{object}	errx.AppError
+//	@Failure		403			{object}	errx.AppError
+//	@Failure		404			{object}	errx.AppError
+//	@Failure		500			{object}	errx.AppError
+//	@Router			/api/v1/locations/{id}/teas/{teaId}/prices [put]
+//	@Security		BearerAuth
+func (c *LocationController) SetTeaPricesOverride(w http.ResponseWriter, r *http.Request) {
+	locationId, teaId, err := parseLocationTeaIds(r)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	request := &locationSchemas.PricesRequestModel{}
+	if err := render.Bind(r, request); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	override, err := c.locationService.SetPrices(locationId, teaId, request.ServePrice, request.UnitPrice)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, locationSchemas.NewOverrideResponseModel(override))
+}
+
+// DeleteTeaOverride godoc
+//
+//	@Summary	Reset tea in location to catalogue values
+//	@Tags		Location
+//	@Accept		json
+//	@Produce	json
+//	@Param		id			path		string	true	"Location ID"
+//	@Param		teaId		path		string	true	"Tea ID"
+//	@Success	200			{object}	bool
+//	@Failure	400			{object}	errx.AppError
+//	@Failure	401			{object}	errx.AppError
+//	@Failure	403			{object}	errx.AppError
+//	@Failure	404			{object}	errx.AppError
+//	@Failure	500			{object}	errx.AppError
+//	@Router		/api/v1/locations/{id}/teas/{teaId} [delete]
+//	@Security	BearerAuth
+func (c *LocationController) DeleteTeaOverride(w http.ResponseWriter, r *http.Request) {
+	locationId, teaId, err := parseLocationTeaIds(r)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	err = c.locationService.DeleteOverride(locationId, teaId)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, true)
+}
+
+// SetUserLocation godoc
+//
+//	@Summary		Scope user to location
+//	@Description	A scoped user can change teas only in the location and can not change the catalogue. Null location lifts the scope.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string									true	"User ID"
+//	@Param			location	body		locationSchemas.UserLocationRequestModel	true	"Location"
+//	@Success		200			{object}	bool
+//	@Failure		400			{object}	errx.AppError
+//	@Failure		401			{object}	errx.AppError
+//	@Failure		403			{object}	errx.AppError
+//	@Failure		404			{object}	errx.AppError
+//	@Failure		500			{object}	errx.AppError
+//	@Router			/api/v1/admin/users/{id}/location [put]
+//	@Security		BearerAuth
+func (c *LocationController) SetUserLocation(w http.ResponseWriter, r *http.Request) {
+	userId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	request := &locationSchemas.UserLocationRequestModel{}
+	if err := render.Bind(r, request); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	locationId := uuid.Nil
+	if request.LocationId != nil {
+		locationId = *request.LocationId
+	}
+
+	err = c.locationService.SetUserLocation(userId, locationId)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, true)
+}
+
+func parseLocationTeaIds(r *http.Request) (uuid.UUID, uuid.UUID, error) {
+	locationId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errx.NewBadRequestError(fmt.Errorf("invalid location id"))
+	}
+
+	teaId, err := uuid.Parse(chi.URLParam(r, "teaId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errx.NewBadRequestError(fmt.Errorf("invalid tea id"))
+	}
+	return locationId, teaId, nil
+}
@@ -0,0 +1,270 @@
+package v1
+
+import (
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/errx"
+	"github.com/levchenki/tea-api/internal/logx"
+	"github.com/levchenki/tea-api/internal/schemas"
+	"github.com/levchenki/tea-api/internal/schemas/loyaltySchemas"
+	"github.com/levchenki/tea-api/internal/schemas/userSchemas"
+	"net/http"
+)
+
+type LoyaltyService interface {
+	GetBalance(userId uuid.UUID) (*entity.LoyaltyBalance, error)
+	GetHistory(userId uuid.UUID, filters *loyaltySchemas.Filters) ([]entity.LoyaltyTransaction, uint64, error)
+	Adjust(userId uuid.UUID, points int, reason string, staffId uuid.UUID) (*entity.LoyaltyBalance, error)
+	GetRules() ([]entity.LoyaltyRule, error)
+	SetRule(categoryId uuid.UUID, servePoints int, amountPercent float64) (*entity.LoyaltyRule, error)
+}
+
+type LoyaltyController struct {
+	loyaltyService LoyaltyService
+	log            logx.AppLogger
+}
+
+func NewLoyaltyController(loyaltyService LoyaltyService, log logx.AppLogger) *LoyaltyController {
+	return &LoyaltyController{
+		loyaltyService: loyaltyService,
+		log:            log,
+	}
+}
+
+// GetMyLoyalty godoc
+//
+//	@Summary	Return loyalty balance of the current user
+//	@Tags		Loyalty
+//	@Accept		json
+//	@Produce	json
+//	@Success	200	{object}	loyaltySchemas.BalanceResponseModel
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/me/loyalty [get]
+//	@Security	BearerAuth
+func (c *LoyaltyController) GetMyLoyalty(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+	c.renderBalance(w, r, userClaims.Id)
+}
+
+// GetMyLoyaltyHistory godoc
+//
+//	@Summary	Return loyalty history of the current user
+//	@Tags		Loyalty
+//	@Accept		json
+//	@Produce	json
+//	@Param		limit	query		int	false	"Limit"
+//	@Param		page	query		int	false	"Page"
+//	@Success	200		{object}	schemas.PaginatedResult[loyaltySchemas.TransactionResponseModel]
+//	@Failure	400		{object}	errx.AppError
+//	@Failure	401		{object}	errx.AppError
+//	@Failure	500		{object}	errx.AppError
+//	@Router		/api/v1/me/loyalty/history [get]
+//	@Security	BearerAuth
+func (c *LoyaltyController) GetMyLoyaltyHistory(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+	c.renderHistory(w, r, userClaims.Id)
+}
+
+// GetUserLoyalty godoc
+//
+//	@Summary	Return loyalty balance of user
+//	@Tags		Loyalty
+//	@Accept		json
+//	@Produce	json
+//	@Param		id	path		string	true	"User ID"
+//	@Success	200	{object}	loyaltySchemas.BalanceResponseModel
+//	@Failure	400	{object}	errx.AppError
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	403	{object}	errx.AppError
+//	@Failure	404	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/loyalty/users/{id} [get]
+//	@Security	BearerAuth
+func (c *LoyaltyController) GetUserLoyalty(w http.ResponseWriter, r *http.Request) {
+	userId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+	c.renderBalance(w, r, userId)
+}
+
+// GetUserLoyaltyHistory godoc
+//
+//	@Summary	Return loyalty history of user
+//	@Tags		Loyalty
+//	@Accept		json
+//	@Produce	json
+//	@Param		id		path		string	true	"User ID"
+//	@Param		limit	query		int		false	"Limit"
+//	@Param		page	query		int		false	"Page"
+//	@Success	200		{object}	schemas.PaginatedResult[loyaltySchemas.TransactionResponseModel]
+//	@Failure	400		{object}	errx.AppError
+//	@Failure	401		{object}	errx.AppError
+//	@Failure	403		{object}	errx.AppError
+//	@Failure	404		{object}	errx.AppError
+//	@Failure	500		{object}	errx.AppError
+//	@Router		/api/v1/loyalty/users/{id}/history [get]
+//	@Security	BearerAuth
+func (c *LoyaltyController) GetUserLoyaltyHistory(w http.ResponseWriter, r *http.Request) {
+	userId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+	c.renderHistory(w, r, userId)
+}
+
+// AdjustUserLoyalty godoc
+//
+//	@Summary		Adjust loyalty balance of user
+//	@Description	Negative points take points from the balance, which can not go below zero. Staff can not adjust their own balance.
+//	@Tags			Loyalty
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		string									true	"User ID"
+//	@Param			adjustment	body		loyaltySchemas.AdjustmentRequestModel	true	"Adjustment"
+//	@Success		200			{object}	loyaltySchemas.BalanceResponseModel
+//	@Failure		400			{object}	errx.AppError
+//	@Failure		401			{object}	errx.AppError
+//	@Failure		403			{object}	errx.AppError
+//	@Failure		404			{object}	errx.AppError
+//	@Failure		500			{object}	errx.AppError
+//	@Router			/api/v1/loyalty/users/{id}/adjustments [post]
+//	@Security		BearerAuth
+func (c *LoyaltyController) AdjustUserLoyalty(w http.ResponseWriter, r *http.Request) {
+	userId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	request := &loyaltySchemas.AdjustmentRequestModel{}
+	if err := render.Bind(r, request); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	staffClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	balance, err := c.loyaltyService.Adjust(userId, request.Points, request.Reason, staffClaims.Id)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, loyaltySchemas.NewBalanceResponseModel(balance))
+}
+
+// GetLoyaltyRules godoc
+//
+//	@Summary	Return loyalty earning rules of categories
+//	@Tags		Loyalty
+//	@Accept		json
+//	@Produce	json
+//	@Success	200	{array}		loyaltySchemas.RuleResponseModel
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/loyalty/rules [get]
+func (c *LoyaltyController) GetLoyaltyRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := c.loyaltyService.GetRules()
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	response := make([]*loyaltySchemas.RuleResponseModel, len(rules))
+	for i := range rules {
+		response[i] = loyaltySchemas.NewRuleResponseModel(&rules[i])
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response)
+}
+
+// SetLoyaltyRule godoc
+//
+//	@Summary		Set loyalty earning rule of category
+//	@Description	A served order earns servePoints per serve and amountPercent of the amount for the teas of the category.
+//	@Tags			Loyalty
+//	@Accept			json
+//	@Produce		json
+//	@Param			categoryId	path		string							true	"Category ID"
+//	@Param			rule		body		loyaltySchemas.RuleRequestModel	true	"Rule"
+//	@Success		200			{object}	loyaltySchemas.RuleResponseModel
+//	@Failure		400			{object}	errx.AppError
+//	@Failure		401			{object}	errx.AppError
+//	@Failure		403			{object}	errx.AppError
+//	@Failure		404			{object}	errx.AppError
+//	@Failure		500			{object}	errx.AppError
+//	@Router			/api/v1/loyalty/rules/{categoryId} [put]
+//	@Security		BearerAuth
+func (c *LoyaltyController) SetLoyaltyRule(w http.ResponseWriter, r *http.Request) {
+	categoryId, err := uuid.Parse(chi.URLParam(r, "categoryId"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid category id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	request := &loyaltySchemas.RuleRequestModel{}
+	if err := render.Bind(r, request); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	rule, err := c.loyaltyService.SetRule(categoryId, request.ServePoints, request.AmountPercent)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, loyaltySchemas.NewRuleResponseModel(rule))
+}
+
+func (c *LoyaltyController) renderBalance(w http.ResponseWriter, r *http.Request, userId uuid.UUID) {
+	balance, err := c.loyaltyService.GetBalance(userId)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, loyaltySchemas.NewBalanceResponseModel(balance))
+}
+
+func (c *LoyaltyController) renderHistory(w http.ResponseWriter, r *http.Request, userId uuid.UUID) {
+	filters := &loyaltySchemas.Filters{}
+	if err := filters.Validate(r); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	transactions, total, err := c.loyaltyService.GetHistory(userId, filters)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	response := make([]*loyaltySchemas.TransactionResponseModel, len(transactions))
+	for i := range transactions {
+		response[i] = loyaltySchemas.NewTransactionResponseModel(&transactions[i])
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, schemas.PaginatedResult[*loyaltySchemas.TransactionResponseModel]{
+		Total: total,
+		Items: response,
+	})
+}
@@ -0,0 +1,366 @@
+package v1
+
+import (
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/errx"
+	"github.com/levchenki/tea-api/internal/logx"
+	"github.com/levchenki/tea-api/internal/schemas"
+	"github.com/levchenki/tea-api/internal/schemas/discountSchemas"
+	"github.com/levchenki/tea-api/internal/schemas/orderSchemas"
+	"github.com/levchenki/tea-api/internal/schemas/userSchemas"
+	"net/http"
+)
+
+type OrderService interface {
+	GetCart(userId uuid.UUID) (*entity.Cart, error)
+	AddCartItem(userId uuid.UUID, request *orderSchemas.CartItemRequestModel) (*entity.Cart, error)
+	UpdateCartItem(userId, id uuid.UUID, quantity float64) (*entity.Cart, error)
+	DeleteCartItem(userId, id uuid.UUID) (*entity.Cart, error)
+	SetPromoCode(userId uuid.UUID, code string) (*entity.Cart, error)
+	RemovePromoCode(userId uuid.UUID) (*entity.Cart, error)
+	ClearCart(userId uuid.UUID) error
+
+	PlaceOrder(userId uuid.UUID, request *orderSchemas.OrderRequestModel) (*entity.Order, error)
+	GetOrders(userId uuid.UUID, filters *orderSchemas.Filters) ([]entity.Order, uint64, error)
+	GetOrder(userId, id uuid.UUID) (*entity.Order, error)
+}
+
+type OrderController struct {
+	orderService OrderService
+	log          logx.AppLogger
+}
+
+func NewOrderController(orderService OrderService, log logx.AppLogger) *OrderController {
+	return &OrderController{
+		orderService: orderService,
+		log:          log,
+	}
+}
+
+// GetMyCart godoc
+//
+//	@Summary		Return cart of the current user
+//	@Description	Items have the list prices, the total is after the greatest automatic discount and the promo code.
+//	@Tags			Orders
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	orderSchemas.CartResponseModel
+//	@Failure		401	{object}	errx.AppError
+//	@Failure		500	{object}	errx.AppError
+//	@Router			/api/v1/me/cart [get]
+//	@Security		BearerAuth
+func (c *OrderController) GetMyCart(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	cart, err := c.orderService.GetCart(userClaims.Id)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, orderSchemas.NewCartResponseModel(cart))
+}
+
+// AddMyCartItem godoc
+//
+//	@Summary		Add tea to cart
+//	@Description	Serve quantity is a number of serves, weight quantity is in units of the tea. Adding a tea that is already in the cart in the same kind adds up the quantities. The first tea sets the location the cart is ordered from, the default location unless locationId is given. The teas are priced and checked by their values in that location.
+//	@Tags			Orders
+//	@Accept			json
+//	@Produce		json
+//	@Param			item	body		orderSchemas.CartItemRequestModel	true	"Cart item"
+//	@Success		200		{object}	orderSchemas.CartResponseModel
+//	@Failure		400		{object}	errx.AppError
+//	@Failure		401		{object}	errx.AppError
+//	@Failure		404		{object}	errx.AppError
+//	@Failure		500		{object}	errx.AppError
+//	@Router			/api/v1/me/cart/items [post]
+//	@Security		BearerAuth
+func (c *OrderController) AddMyCartItem(w http.ResponseWriter, r *http.Request) {
+	request := &orderSchemas.CartItemRequestModel{}
+	if err := render.Bind(r, request); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	cart, err := c.orderService.AddCartItem(userClaims.Id, request)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, orderSchemas.NewCartResponseModel(cart))
+}
+
+// UpdateMyCartItem godoc
+//
+//	@Summary	Change quantity of cart item
+//	@Tags		Orders
+//	@Accept		json
+//	@Produce	json
+//	@Param		id			path		string										true	"Cart item ID"
+//	@Param		quantity	body		orderSchemas.CartItemQuantityRequestModel	true	"Quantity"
+//	@Success	200			{object}	orderSchemas.CartResponseModel
+//	@Failure	400			{object}	errx.AppError
+//	@Failure	401			{object}	errx.AppError
+//	@Failure	404			{object}	errx.AppError
+//	@Failure	500			{object}	errx.AppError
+//	@Router		/api/v1/me/cart/items/{id} [put]
+//	@Security	BearerAuth
+func (c *OrderController) UpdateMyCartItem(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	request := &orderSchemas.CartItemQuantityRequestModel{}
+	if err := render.Bind(r, request); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	cart, err := c.orderService.UpdateCartItem(userClaims.Id, id, request.Quantity)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, orderSchemas.NewCartResponseModel(cart))
+}
+
+// DeleteMyCartItem godoc
+//
+//	@Summary	Remove item from cart
+//	@Tags		Orders
+//	@Accept		json
+//	@Produce	json
+//	@Param		id	path		string	true	"Cart item ID"
+//	@Success	200	{object}	orderSchemas.CartResponseModel
+//	@Failure	400	{object}	errx.AppError
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	404	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/me/cart/items/{id} [delete]
+//	@Security	BearerAuth
+func (c *OrderController) DeleteMyCartItem(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	cart, err := c.orderService.DeleteCartItem(userClaims.Id, id)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, orderSchemas.NewCartResponseModel(cart))
+}
+
+// SetMyPromoCode godoc
+//
+//	@Summary		Enter promo code for cart
+//	@Description	Returns 400 with the reason if the promo code can not be used with the cart. The promo code is redeemed when the order is placed.
+//	@Tags			Orders
+//	@Accept			json
+//	@Produce		json
+//	@Param			promoCode	body		discountSchemas.PromoCodeRequestModel	true	"Promo code"
+//	@Success		200			{object}	orderSchemas.CartResponseModel
+//	@Failure		400			{object}	errx.AppError
+//	@Failure		401			{object}	errx.AppError
+//	@Failure		404			{object}	errx.AppError
+//	@Failure		500			{object}	errx.AppError
+//	@Router			/api/v1/me/cart/promo-code [put]
+//	@Security		BearerAuth
+func (c *OrderController) SetMyPromoCode(w http.ResponseWriter, r *http.Request) {
+	request := &discountSchemas.PromoCodeRequestModel{}
+	if err := render.Bind(r, request); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	cart, err := c.orderService.SetPromoCode(userClaims.Id, request.Code)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, orderSchemas.NewCartResponseModel(cart))
+}
+
+// RemoveMyPromoCode godoc
+//
+//	@Summary	Remove promo code from cart
+//	@Tags		Orders
+//	@Accept		json
+//	@Produce	json
+//	@Success	200	{object}	orderSchemas.CartResponseModel
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/me/cart/promo-code [delete]
+//	@Security	BearerAuth
+func (c *OrderController) RemoveMyPromoCode(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	cart, err := c.orderService.RemovePromoCode(userClaims.Id)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, orderSchemas.NewCartResponseModel(cart))
+}
+
+// ClearMyCart godoc
+//
+//	@Summary	Remove all items from cart
+//	@Tags		Orders
+//	@Accept		json
+//	@Produce	json
+//	@Success	200
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/me/cart [delete]
+//	@Security	BearerAuth
+func (c *OrderController) ClearMyCart(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	err := c.orderService.ClearCart(userClaims.Id)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+}
+
+// PlaceMyOrder godoc
+//
+//	@Summary		Place order from cart
+//	@Description	Returns 409 and refreshes the cart if prices have changed since the teas were put into it, or if the discounts of the cart have changed. Redeemed loyalty points discount the order after the other discounts.
+//	@Tags			Orders
+//	@Accept			json
+//	@Produce		json
+//	@Param			order	body		orderSchemas.OrderRequestModel	true	"Order"
+//	@Success		201		{object}	orderSchemas.OrderResponseModel
+//	@Failure		400		{object}	errx.AppError
+//	@Failure		401		{object}	errx.AppError
+//	@Failure		409		{object}	errx.AppError
+//	@Failure		500		{object}	errx.AppError
+//	@Router			/api/v1/me/orders [post]
+//	@Security		BearerAuth
+func (c *OrderController) PlaceMyOrder(w http.ResponseWriter, r *http.Request) {
+	request := &orderSchemas.OrderRequestModel{}
+	if err := render.Bind(r, request); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	order, err := c.orderService.PlaceOrder(userClaims.Id, request)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, orderSchemas.NewOrderResponseModel(order))
+}
+
+// GetMyOrders godoc
+//
+//	@Summary	Return order history of the current user
+//	@Tags		Orders
+//	@Accept		json
+//	@Produce	json
+//	@Param		page	query		int	false	"Page number"
+//	@Param		limit	query		int	false	"Page size"
+//	@Success	200		{object}	schemas.PaginatedResult[orderSchemas.OrderResponseModel]
+//	@Failure	400		{object}	errx.AppError
+//	@Failure	401		{object}	errx.AppError
+//	@Failure	500		{object}	errx.AppError
+//	@Router		/api/v1/me/orders [get]
+//	@Security	BearerAuth
+func (c *OrderController) GetMyOrders(w http.ResponseWriter, r *http.Request) {
+	filters := &orderSchemas.Filters{}
+	if err := filters.Validate(r); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	orders, total, err := c.orderService.GetOrders(userClaims.Id, filters)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, schemas.PaginatedResult[*orderSchemas.OrderResponseModel]{
+		Total: total,
+		Items: newOrderResponseModels(orders),
+	})
+}
+
+// GetMyOrderById godoc
+//
+//	@Summary	Return order of the current user
+//	@Tags		Orders
+//	@Accept		json
+//	@Produce	json
+//	@Param		id	path		string	true	"Order ID"
+//	@Success	200	{object}	orderSchemas.OrderResponseModel
+//	@Failure	400	{object}	errx.AppError
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	404	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/me/orders/{id} [get]
+//	@Security	BearerAuth
+func (c *OrderController) GetMyOrderById(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	order, err := c.orderService.GetOrder(userClaims.Id, id)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, orderSchemas.NewOrderResponseModel(order))
+}
@@ -0,0 +1,75 @@
+package v1
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/errx"
+	"github.com/levchenki/tea-api/internal/logx"
+	"github.com/levchenki/tea-api/internal/ratex"
+	"github.com/levchenki/tea-api/internal/schemas/userSchemas"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type RateLimiter struct {
+	store ratex.Store
+	log   logx.AppLogger
+}
+
+func NewRateLimiter(store ratex.Store, log logx.AppLogger) *RateLimiter {
+	return &RateLimiter{
+		store: store,
+		log:   log,
+	}
+}
+
+// Limit applies the policy to every request passing through. Clients are told
+// apart by API key or user when the request is already authenticated, and by
+// IP otherwise, so the middleware goes after ApiKeyMiddleware and
+// AuthMiddleware where they are used. Behind a reverse proxy the IP is the
+// one netx.RealIP takes from a trusted proxy. The name separates the budgets of
+// different policies.
+func (l *RateLimiter) Limit(name string, policy ratex.Policy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if policy.IsDisabled() {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := name + ":" + rateLimitClientKey(r)
+
+			allowed, retryAfter, err := l.store.Allow(key, policy)
+			if err != nil {
+				// A broken store should not take the API down with it.
+				l.log.Error("Rate limit store error", "error", err.Error(), "policy", name)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !allowed {
+				retryAfterSeconds := max(1, int(math.Ceil(retryAfter.Seconds())))
+				w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds))
+				err := fmt.Errorf("too many requests, retry in %s", time.Duration(retryAfterSeconds)*time.Second)
+				errResponse := errx.NewTooManyRequestsError(err)
+				handleError(w, r, l.log, errResponse)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func rateLimitClientKey(r *http.Request) string {
+	if claims, ok := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims); ok {
+		if claims.ApiKeyId != uuid.Nil {
+			return "api_key:" + claims.ApiKeyId.String()
+		}
+		if claims.Id != uuid.Nil {
+			return "user:" + claims.Id.String()
+		}
+	}
+	return "ip:" + userSchemas.NewDeviceInfo(r).IpAddress
+}
@@ -0,0 +1,316 @@
+package v1
+
+import (
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/errx"
+	"github.com/levchenki/tea-api/internal/logx"
+	"github.com/levchenki/tea-api/internal/schemas/roleSchemas"
+	"github.com/levchenki/tea-api/internal/schemas/userSchemas"
+	"net/http"
+)
+
+type RoleService interface {
+	GetAllPermissions() ([]entity.Permission, error)
+	GetAll() ([]entity.Role, error)
+	Create(role *roleSchemas.RequestModel) (*entity.Role, error)
+	Update(id uuid.UUID, role *roleSchemas.RequestModel) (*entity.Role, error)
+	Delete(id uuid.UUID) error
+
+	GetUserRoles(userId uuid.UUID) ([]entity.Role, error)
+	AssignToUser(userId, roleId uuid.UUID) ([]entity.Role, error)
+	UnassignFromUser(actorId, userId, roleId uuid.UUID) ([]entity.Role, error)
+}
+
+type RoleController struct {
+	roleService RoleService
+	log         logx.AppLogger
+}
+
+func NewRoleController(roleService RoleService, log logx.AppLogger) *RoleController {
+	return &RoleController{
+		roleService: roleService,
+		log:         log,
+	}
+}
+
+// GetAllPermissions godoc
+//
+//	@Summary	Return all permissions
+//	@Tags		Admin
+//	@Accept		json
+//	@Produce	json
+//	@Success	200	{object}	[]roleSchemas.PermissionResponseModel
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	403	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/admin/permissions [get]
+//	@Security	BearerAuth
+func (c *RoleController) GetAllPermissions(w http.ResponseWriter, r *http.Request) {
+	permissions, err := c.roleService.GetAllPermissions()
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	response := make([]*roleSchemas.PermissionResponseModel, len(permissions))
+	for i := range permissions {
+		response[i] = roleSchemas.NewPermissionResponseModel(&permissions[i])
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response)
+}
+
+// GetAllRoles godoc
+//
+//	@Summary	Return all roles with their permissions
+//	@Tags		Admin
+//	@Accept		json
+//	@Produce	json
+//	@Success	200	{object}	[]roleSchemas.ResponseModel
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	403	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/admin/roles [get]
+//	@Security	BearerAuth
+func (c *RoleController) GetAllRoles(w http.ResponseWriter, r *http.Request) {
+	roles, err := c.roleService.GetAll()
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, c.toResponse(roles))
+}
+
+// CreateRole godoc
+//
+//	@Summary	Create role
+//	@Tags		Admin
+//	@Accept		json
+//	@Produce	json
+//	@Param		role	body		roleSchemas.RequestModel	true	"Role"
+//	@Success	201		{object}	roleSchemas.ResponseModel
+//	@Failure	400		{object}	errx.AppError
+//	@Failure	401		{object}	errx.AppError
+//	@Failure	403		{object}	errx.AppError
+//	@Failure	500		{object}	errx.AppError
+//	@Router		/api/v1/admin/roles [post]
+//	@Security	BearerAuth
+func (c *RoleController) CreateRole(w http.ResponseWriter, r *http.Request) {
+	roleRequest := &roleSchemas.RequestModel{}
+	if err := render.Bind(r, roleRequest); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	role, err := c.roleService.Create(roleRequest)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, roleSchemas.NewRoleResponseModel(role))
+}
+
+// UpdateRole godoc
+//
+//	@Summary	Update role
+//	@Tags		Admin
+//	@Accept		json
+//	@Produce	json
+//	@Param		id		path		string						true	"Role ID"
+//	@Param		role	body		roleSchemas.RequestModel	true	"Role"
+//	@Success	200		{object}	roleSchemas.ResponseModel
+//	@Failure	400		{object}	errx.AppError
+//	@Failure	401		{object}	errx.AppError
+//	@Failure	403		{object}	errx.AppError
+//	@Failure	404		{object}	errx.AppError
+//	@Failure	500		{object}	errx.AppError
+//	@Router		/api/v1/admin/roles/{id} [put]
+//	@Security	BearerAuth
+func (c *RoleController) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	roleRequest := &roleSchemas.RequestModel{}
+	if err := render.Bind(r, roleRequest); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	role, err := c.roleService.Update(id, roleRequest)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, roleSchemas.NewRoleResponseModel(role))
+}
+
+// DeleteRole godoc
+//
+//	@Summary	Delete role
+//	@Tags		Admin
+//	@Accept		json
+//	@Produce	json
+//	@Param		id	path	string	true	"Role ID"
+//	@Success	200
+//	@Failure	400	{object}	errx.AppError
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	403	{object}	errx.AppError
+//	@Failure	404	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/admin/roles/{id} [delete]
+//	@Security	BearerAuth
+func (c *RoleController) DeleteRole(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	err = c.roleService.Delete(id)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+}
+
+// GetUserRoles godoc
+//
+//	@Summary	Return roles of user
+//	@Tags		Admin
+//	@Accept		json
+//	@Produce	json
+//	@Param		id	path		string	true	"User ID"
+//	@Success	200	{object}	[]roleSchemas.ResponseModel
+//	@Failure	400	{object}	errx.AppError
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	403	{object}	errx.AppError
+//	@Failure	404	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/admin/users/{id}/roles [get]
+//	@Security	BearerAuth
+func (c *RoleController) GetUserRoles(w http.ResponseWriter, r *http.Request) {
+	userId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	roles, err := c.roleService.GetUserRoles(userId)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, c.toResponse(roles))
+}
+
+// AssignRole godoc
+//
+//	@Summary	Assign role to user
+//	@Tags		Admin
+//	@Accept		json
+//	@Produce	json
+//	@Param		id		path		string	true	"User ID"
+//	@Param		roleId	path		string	true	"Role ID"
+//	@Success	200		{object}	[]roleSchemas.ResponseModel
+//	@Failure	400		{object}	errx.AppError
+//	@Failure	401		{object}	errx.AppError
+//	@Failure	403		{object}	errx.AppError
+//	@Failure	404		{object}	errx.AppError
+//	@Failure	500		{object}	errx.AppError
+//	@Router		/api/v1/admin/users/{id}/roles/{roleId} [post]
+//	@Security	BearerAuth
+func (c *RoleController) AssignRole(w http.ResponseWriter, r *http.Request) {
+	userId, roleId, err := c.parseUserRoleIds(r)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	roles, err := c.roleService.AssignToUser(userId, roleId)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, c.toResponse(roles))
+}
+
+// UnassignRole godoc
+//
+//	@Summary	Remove role from user
+//	@Tags		Admin
+//	@Accept		json
+//	@Produce	json
+//	@Param		id		path		string	true	"User ID"
+//	@Param		roleId	path		string	true	"Role ID"
+//	@Success	200		{object}	[]roleSchemas.ResponseModel
+//	@Failure	400		{object}	errx.AppError
+//	@Failure	401		{object}	errx.AppError
+//	@Failure	403		{object}	errx.AppError
+//	@Failure	404		{object}	errx.AppError
+//	@Failure	500		{object}	errx.AppError
+//	@Router		/api/v1/admin/users/{id}/roles/{roleId} [delete]
+//	@Security	BearerAuth
+func (c *RoleController) UnassignRole(w http.ResponseWriter, r *http.Request) {
+	userId, roleId, err := c.parseUserRoleIds(r)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	roles, err := c.roleService.UnassignFromUser(userClaims.Id, userId, roleId)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, c.toResponse(roles))
+}
+
+func (c *RoleController) parseUserRoleIds(r *http.Request) (uuid.UUID, uuid.UUID, error) {
+	userId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errx.NewBadRequestError(fmt.Errorf("invalid id"))
+	}
+
+	roleId, err := uuid.Parse(chi.URLParam(r, "roleId"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errx.NewBadRequestError(fmt.Errorf("invalid roleId"))
+	}
+	return userId, roleId, nil
+}
+
+func (c *RoleController) toResponse(roles []entity.Role) []*roleSchemas.ResponseModel {
+	response := make([]*roleSchemas.ResponseModel, len(roles))
+	for i := range roles {
+		response[i] = roleSchemas.NewRoleResponseModel(&roles[i])
+	}
+	return response
+}
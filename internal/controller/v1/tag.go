@@ -67,6 +67,7 @@ func (c *TagController) GetAllTags(w http.ResponseWriter, r *http.Request) {
 //	@Failure	500	{object}	errx.AppError
 //	@Router		/api/v1/tags [post]
 //	@Security	BearerAuth
+//	@Security	ApiKeyAuth
 func (c *TagController) CreateTag(w http.ResponseWriter, r *http.Request) {
 	tagRequest := &tagSchemas.RequestModel{}
 	if err := render.Bind(r, tagRequest); err != nil {
@@ -107,6 +108,7 @@ func (c *TagController) CreateTag(w http.ResponseWriter, r *http.Request) {
 //	@Failure	500	{object}	errx.AppError
 //	@Router		/api/v1/tags/{id} [put]
 //	@Security	BearerAuth
+//	@Security	ApiKeyAuth
 func (c *TagController) UpdateTag(w http.ResponseWriter, r *http.Request) {
 	strId := chi.URLParam(r, "id")
 	id, err := uuid.Parse(strId)
@@ -155,6 +157,7 @@ func (c *TagController) UpdateTag(w http.ResponseWriter, r *http.Request) {
 //	@Failure	500	{object}	errx.AppError
 //	@Router		/api/v1/tags/{id} [delete]
 //	@Security	BearerAuth
+//	@Security	ApiKeyAuth
 func (c *TagController) DeleteTag(w http.ResponseWriter, r *http.Request) {
 	strId := chi.URLParam(r, "id")
 	id, err := uuid.Parse(strId)
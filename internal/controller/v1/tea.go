@@ -16,8 +16,9 @@ import (
 )
 
 type TeaService interface {
-	GetTeaById(id uuid.UUID, userId uuid.UUID) (*entity.TeaWithRating, error)
+	GetTeaByIdInLocation(id, userId, locationId uuid.UUID) (*entity.TeaWithRating, error)
 	GetAllTeas(filters *teaSchemas.Filters) ([]entity.TeaWithRating, uint64, error)
+	CompareTeas(filters *teaSchemas.CompareFilters) ([]entity.TeaComparison, error)
 	CreateTea(tea *teaSchemas.RequestModel) (*entity.Tea, error)
 	DeleteTea(id uuid.UUID) error
 	UpdateTea(id uuid.UUID, tea *teaSchemas.RequestModel) (*entity.Tea, error)
@@ -26,6 +27,9 @@ type TeaService interface {
 	DeleteEvaluation(userId, teaId uuid.UUID) error
 	ToggleFavourites(id uuid.UUID, userId uuid.UUID, isFavourite bool) error
 
+	SetVisibility(id uuid.UUID, isHidden bool) (*entity.TeaWithRating, error)
+	SetStock(id uuid.UUID, stock *float64) (*entity.TeaWithRating, error)
+
 	GetMinMaxServePrices(filters *teaSchemas.Filters) (float64, float64, error)
 }
 
@@ -47,13 +51,15 @@ func NewTeaController(teaService TeaService, log logx.AppLogger) *TeaController
 //	@Tags		Tea
 //	@Accept		json
 //	@Produce	json
-//	@Param		id	path		string	true	"Tea ID"
-//	@Success	200	{object}	teaSchemas.WithRatingResponseModel
-//	@Failure	400	{object}	errx.AppError
-//	@Failure	404	{object}	errx.AppError
-//	@Failure	500	{object}	errx.AppError
+//	@Param		id			path		string	true	"Tea ID"
+//	@Param		locationId	query		string	false	"Location ID, prices and availability of the location"
+//	@Success	200			{object}	teaSchemas.WithRatingResponseModel
+//	@Failure	400			{object}	errx.AppError
+//	@Failure	404			{object}	errx.AppError
+//	@Failure	500			{object}	errx.AppError
 //	@Router		/api/v1/teas/{id} [get]
 //	@Security	BearerAuth
+//	@Security	ApiKeyAuth
 func (c *TeaController) GetTeaById(w http.ResponseWriter, r *http.Request) {
 	strId := chi.URLParam(r, "id")
 	id, err := uuid.Parse(strId)
@@ -72,7 +78,14 @@ func (c *TeaController) GetTeaById(w http.ResponseWriter, r *http.Request) {
 		userId = uuid.Nil
 	}
 
-	teaById, err := c.teaService.GetTeaById(id, userId)
+	locationId, err := teaSchemas.ParseLocationId(r)
+	if err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	teaById, err := c.teaService.GetTeaByIdInLocation(id, userId, locationId)
 
 	if err != nil {
 		handleError(w, r, c.log, err)
@@ -101,11 +114,15 @@ func (c *TeaController) GetTeaById(w http.ResponseWriter, r *http.Request) {
 //	@Param		servePrice[]	query		[]float64				false	"ServePrice range"
 //	@Param		isOnlyHidden	query		bool					false	"Is only hidden"
 //	@Param		isOnlyFavourite	query		bool					false	"Is only favourite"
+//	@Param		listId			query		string					false	"Personal list ID"
+//	@Param		locationId		query		string					false	"Location ID, prices and availability of the location"
 //	@Success	200				{object}	teaSchemas.TeaPricesPaginatedResult[teaSchemas.WithRatingResponseModel]
 //	@Failure	400				{object}	errx.AppError
+//	@Failure	404				{object}	errx.AppError
 //	@Failure	500				{object}	errx.AppError
 //	@Router		/api/v1/teas [get]
 //	@Security	BearerAuth
+//	@Security	ApiKeyAuth
 func (c *TeaController) GetAllTeas(w http.ResponseWriter, r *http.Request) {
 	filters := teaSchemas.NewFilters()
 
@@ -148,6 +165,44 @@ func (c *TeaController) GetAllTeas(w http.ResponseWriter, r *http.Request) {
 	render.JSON(w, r, response)
 }
 
+// CompareTeas godoc
+//
+//	@Summary	Compare teas side by side
+//	@Tags		Tea
+//	@Accept		json
+//	@Produce	json
+//	@Param		ids	query		[]string	true	"Tea IDs (from 2 to 5)"
+//	@Success	200	{object}	teaSchemas.CompareResponseModel
+//	@Failure	400	{object}	errx.AppError
+//	@Failure	404	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/teas/compare [get]
+//	@Security	BearerAuth
+//	@Security	ApiKeyAuth
+func (c *TeaController) CompareTeas(w http.ResponseWriter, r *http.Request) {
+	filters := &teaSchemas.CompareFilters{}
+	if err := filters.Validate(r); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	user := r.Context().Value("accessTokenClaims")
+	userClaims, ok := user.(*userSchemas.AccessTokenClaims)
+	if ok {
+		filters.UserId = userClaims.Id
+	}
+
+	teas, err := c.teaService.CompareTeas(filters)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, teaSchemas.NewCompareResponseModel(teas))
+}
+
 // CreateTea godoc
 //
 //	@Summary	Create tea
@@ -162,6 +217,7 @@ func (c *TeaController) GetAllTeas(w http.ResponseWriter, r *http.Request) {
 //	@Failure	500	{object}	errx.AppError
 //	@Router		/api/v1/teas [post]
 //	@Security	BearerAuth
+//	@Security	ApiKeyAuth
 func (c *TeaController) CreateTea(w http.ResponseWriter, r *http.Request) {
 	teaRequest := &teaSchemas.RequestModel{}
 	if err := render.Bind(r, teaRequest); err != nil {
@@ -194,6 +250,7 @@ func (c *TeaController) CreateTea(w http.ResponseWriter, r *http.Request) {
 //	@Failure	500	{object}	errx.AppError
 //	@Router		/api/v1/teas/{id} [delete]
 //	@Security	BearerAuth
+//	@Security	ApiKeyAuth
 func (c *TeaController) DeleteTea(w http.ResponseWriter, r *http.Request) {
 	strId := chi.URLParam(r, "id")
 	id, err := uuid.Parse(strId)
@@ -228,6 +285,7 @@ func (c *TeaController) DeleteTea(w http.ResponseWriter, r *http.Request) {
 //	@Failure	500	{object}	errx.AppError
 //	@Router		/api/v1/teas/{id} [put]
 //	@Security	BearerAuth
+//	@Security	ApiKeyAuth
 func (c *TeaController) UpdateTea(w http.ResponseWriter, r *http.Request) {
 	strId := chi.URLParam(r, "id")
 	id, err := uuid.Parse(strId)
@@ -260,6 +318,92 @@ func (c *TeaController) UpdateTea(w http.ResponseWriter, r *http.Request) {
 	render.JSON(w, r, teaSchemas.NewTeaResponseModel(tea))
 }
 
+// SetTeaVisibility godoc
+//
+//	@Summary	Hide or unhide tea
+//	@Tags		Tea
+//	@Accept		json
+//	@Produce	json
+//	@Param		id			path		string								true	"Tea ID"
+//	@Param		visibility	body		teaSchemas.VisibilityRequestModel	true	"Visibility"
+//	@Success	200			{object}	teaSchemas.WithRatingResponseModel
+//	@Failure	400			{object}	errx.AppError
+//	@Failure	401			{object}	errx.AppError
+//	@Failure	403			{object}	errx.AppError
+//	@Failure	404			{object}	errx.AppError
+//	@Failure	500			{object}	errx.AppError
+//	@Router		/api/v1/teas/{id}/visibility [patch]
+//	@Security	BearerAuth
+func (c *TeaController) SetTeaVisibility(w http.ResponseWriter, r *http.Request) {
+	strId := chi.URLParam(r, "id")
+	id, err := uuid.Parse(strId)
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	visibilityRequest := &teaSchemas.VisibilityRequestModel{}
+	if err := render.Bind(r, visibilityRequest); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	tea, err := c.teaService.SetVisibility(id, *visibilityRequest.IsHidden)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, teaSchemas.NewTeaWithRatingResponseModel(tea))
+}
+
+// SetTeaStock godoc
+//
+//	@Summary		Set tea stock
+//	@Description	Null stock means the stock is not tracked for the tea
+//	@Tags			Tea
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		string						true	"Tea ID"
+//	@Param			stock	body		teaSchemas.StockRequestModel	true	"Stock"
+//	@Success		200		{object}	teaSchemas.WithRatingResponseModel
+//	@Failure		400		{object}	errx.AppError
+//	@Failure		401		{object}	errx.AppError
+//	@Failure		403		{object}	errx.AppError
+//	@Failure		404		{object}	errx.AppError
+//	@Failure		500		{object}	errx.AppError
+//	@Router			/api/v1/teas/{id}/stock [patch]
+//	@Security		BearerAuth
+//	@Security		ApiKeyAuth
+func (c *TeaController) SetTeaStock(w http.ResponseWriter, r *http.Request) {
+	strId := chi.URLParam(r, "id")
+	id, err := uuid.Parse(strId)
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	stockRequest := &teaSchemas.StockRequestModel{}
+	if err := render.Bind(r, stockRequest); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	tea, err := c.teaService.SetStock(id, stockRequest.Stock)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, teaSchemas.NewTeaWithRatingResponseModel(tea))
+}
+
 // Evaluate godoc
 //
 //	@Summary	Evaluate tea
@@ -342,6 +486,45 @@ func (c *TeaController) DeleteEvaluation(w http.ResponseWriter, r *http.Request)
 	render.Status(r, http.StatusOK)
 }
 
+// DeleteUserEvaluation godoc
+//
+//	@Summary	Delete evaluation of another user
+//	@Tags		Tea
+//	@Produce	json
+//	@Param		id		path	string	true	"Tea ID"
+//	@Param		userId	path	string	true	"User ID"
+//	@Success	200
+//	@Failure	400	{object}	errx.AppError
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	403	{object}	errx.AppError
+//	@Failure	404	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/teas/{id}/evaluations/{userId} [delete]
+//	@Security	BearerAuth
+func (c *TeaController) DeleteUserEvaluation(w http.ResponseWriter, r *http.Request) {
+	teaId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errorResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errorResponse)
+		return
+	}
+
+	userId, err := uuid.Parse(chi.URLParam(r, "userId"))
+	if err != nil {
+		errorResponse := errx.NewBadRequestError(fmt.Errorf("invalid userId"))
+		handleError(w, r, c.log, errorResponse)
+		return
+	}
+
+	err = c.teaService.DeleteEvaluation(userId, teaId)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+}
+
 // ToggleFavourites godoc
 //
 //	@Summary	Add or remove tea to/from favourites
@@ -5,33 +5,51 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
 	"github.com/levchenki/tea-api/internal/errx"
+	"github.com/levchenki/tea-api/internal/jwtx"
 	"github.com/levchenki/tea-api/internal/logx"
 	"github.com/levchenki/tea-api/internal/schemas/userSchemas"
 	"net/http"
 )
 
 type UserService interface {
-	AuthenticateUser(tgUser *userSchemas.TelegramUser, botToken, jwtSecret string) (*userSchemas.UserTokens, error)
-	AuthenticateTelegramMiniApp(initData, botToken, jwtSecret string) (*userSchemas.UserTokens, error)
-	CheckAuthToken(authHeader string, jwtSecret string) (*userSchemas.AccessTokenClaims, error)
-	UpdateAccessToken(signedRefreshToken, jwtSecret string) (*userSchemas.UserTokens, error)
+	SignIn(providerName string, credentials map[string]string, device *userSchemas.DeviceInfo, jwtKeys *jwtx.KeySet) (*userSchemas.UserTokens, error)
+	RequestEmailLogin(email string) error
+	GetOIDCAuthorizationURL() (string, error)
+	CheckAuthToken(authHeader string, jwtKeys *jwtx.KeySet) (*userSchemas.AccessTokenClaims, error)
+	UpdateAccessToken(signedRefreshToken string, device *userSchemas.DeviceInfo, jwtKeys *jwtx.KeySet) (*userSchemas.UserTokens, error)
+	Logout(signedRefreshToken string, jwtKeys *jwtx.KeySet) error
+
+	GetProfile(userId uuid.UUID) (*entity.User, *entity.UserStatistics, error)
+	UpdateProfile(userId uuid.UUID, profile *userSchemas.ProfileRequestModel) (*entity.User, *entity.UserStatistics, error)
+	DeleteAccount(userId uuid.UUID) error
+
+	GetSessions(userId uuid.UUID) ([]entity.Session, error)
+	RevokeSession(userId, sessionId uuid.UUID) error
+	RevokeAllSessions(userId uuid.UUID) error
+
+	GetIdentities(userId uuid.UUID) ([]entity.Identity, error)
+	LinkIdentity(userId uuid.UUID, providerName string, credentials map[string]string) (*entity.Identity, error)
+	UnlinkIdentity(userId, identityId uuid.UUID) error
 }
 
 type UserController struct {
-	jwtSecret   string
-	botToken    string
-	userService UserService
-	log         logx.AppLogger
+	jwtKeys      *jwtx.KeySet
+	userService  UserService
+	guestService GuestService
+	log          logx.AppLogger
 }
 
-func NewUserController(jwtSecret, botToken string, userService UserService, log logx.AppLogger) *UserController {
+func NewUserController(jwtKeys *jwtx.KeySet, userService UserService, guestService GuestService, log logx.AppLogger) *UserController {
 	return &UserController{
-		jwtSecret:   jwtSecret,
-		botToken:    botToken,
-		userService: userService,
-		log:         log,
+		jwtKeys:      jwtKeys,
+		userService:  userService,
+		guestService: guestService,
+		log:          log,
 	}
 }
 
@@ -42,8 +60,10 @@ func NewUserController(jwtSecret, botToken string, userService UserService, log
 //	@Accept		json
 //	@Produce	json
 //	@Param		telegramUser	body		userSchemas.TelegramUser	true	"Telegram user"
+//	@Param		X-Guest-Token	header		string	false	"Guest token, its marks are moved to the account"
 //	@Success	200				{object}	userSchemas.TokenResponse
 //	@Failure	400				{object}	errx.AppError
+//	@Failure	401				{object}	errx.AppError
 //	@Failure	403				{object}	errx.AppError
 //	@Failure	500				{object}	errx.AppError
 //	@Router		/api/v1/auth [post]
@@ -55,22 +75,16 @@ func (c *UserController) Auth(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tokens, err := c.userService.AuthenticateUser(tgUser, c.botToken, c.jwtSecret)
+	tokens, err := c.userService.SignIn(entity.IdentityProviderTelegram, tgUser.Credentials(), userSchemas.NewDeviceInfo(r), c.jwtKeys)
 
 	if err != nil {
 		handleError(w, r, c.log, err)
 		return
 	}
 
-	cookie := &http.Cookie{
-		Name:     "refreshToken",
-		Value:    tokens.RefreshToken.SignedValue,
-		Expires:  tokens.RefreshToken.Claims.Exp.Time,
-		Path:     "/",
-		HttpOnly: true,
-		Secure:   true,
-	}
-	http.SetCookie(w, cookie)
+	c.mergeGuest(r, tokens)
+
+	c.setRefreshTokenCookie(w, tokens.RefreshToken)
 	render.JSON(w, r, tokens)
 }
 
@@ -81,8 +95,10 @@ func (c *UserController) Auth(w http.ResponseWriter, r *http.Request) {
 //	@Accept		json
 //	@Produce	json
 //	@Param		MiniAppInitData	body		userSchemas.MiniAppInitRequest	true	"Telegram init data"
+//	@Param		X-Guest-Token	header		string	false	"Guest token, its marks are moved to the account"
 //	@Success	200				{object}	userSchemas.TokenResponse
 //	@Failure	400				{object}	errx.AppError
+//	@Failure	401				{object}	errx.AppError
 //	@Failure	403				{object}	errx.AppError
 //	@Failure	500				{object}	errx.AppError
 //	@Router		/api/v1/auth/mini-app [post]
@@ -94,22 +110,141 @@ func (c *UserController) AuthMiniApp(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tokens, err := c.userService.AuthenticateTelegramMiniApp(request.InitData, c.botToken, c.jwtSecret)
+	credentials := map[string]string{"initData": request.InitData}
+	tokens, err := c.userService.SignIn(entity.IdentityProviderTelegramMiniApp, credentials, userSchemas.NewDeviceInfo(r), c.jwtKeys)
 
 	if err != nil {
 		handleError(w, r, c.log, err)
 		return
 	}
 
-	cookie := &http.Cookie{
-		Name:     "refreshToken",
-		Value:    tokens.RefreshToken.SignedValue,
-		Expires:  tokens.RefreshToken.Claims.Exp.Time,
-		Path:     "/",
-		HttpOnly: true,
-		Secure:   true,
+	c.mergeGuest(r, tokens)
+
+	c.setRefreshTokenCookie(w, tokens.RefreshToken)
+	render.JSON(w, r, tokens)
+}
+
+// RequestEmailLogin godoc
+//
+//	@Summary		Send sign-in link by email
+//	@Description	Mails a one-time sign-in link to the address. The link leads to the frontend, which passes the token to /auth/email.
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		userSchemas.EmailLoginRequest	true	"Email"
+//	@Success		202		{object}	bool
+//	@Failure		400		{object}	errx.AppError
+//	@Failure		500		{object}	errx.AppError
+//	@Router			/api/v1/auth/email/link [post]
+func (c *UserController) RequestEmailLogin(w http.ResponseWriter, r *http.Request) {
+	request := &userSchemas.EmailLoginRequest{}
+	if err := render.Bind(r, request); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
 	}
-	http.SetCookie(w, cookie)
+
+	err := c.userService.RequestEmailLogin(request.Email)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusAccepted)
+	render.JSON(w, r, true)
+}
+
+// AuthEmail godoc
+//
+//	@Summary	Auth with email sign-in link
+//	@Tags		Auth
+//	@Accept		json
+//	@Produce	json
+//	@Param		request	body		userSchemas.EmailAuthRequest	true	"Token from the sign-in link"
+//	@Param		X-Guest-Token	header		string	false	"Guest token, its marks are moved to the account"
+//	@Success	200		{object}	userSchemas.TokenResponse
+//	@Failure	400		{object}	errx.AppError
+//	@Failure	401		{object}	errx.AppError
+//	@Failure	403		{object}	errx.AppError
+//	@Failure	500		{object}	errx.AppError
+//	@Router		/api/v1/auth/email [post]
+func (c *UserController) AuthEmail(w http.ResponseWriter, r *http.Request) {
+	request := &userSchemas.EmailAuthRequest{}
+	if err := render.Bind(r, request); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	credentials := map[string]string{"token": request.Token}
+	tokens, err := c.userService.SignIn(entity.IdentityProviderEmail, credentials, userSchemas.NewDeviceInfo(r), c.jwtKeys)
+
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	c.mergeGuest(r, tokens)
+
+	c.setRefreshTokenCookie(w, tokens.RefreshToken)
+	render.JSON(w, r, tokens)
+}
+
+// GetOIDCAuthorizationURL godoc
+//
+//	@Summary		Start OIDC sign in
+//	@Description	Returns the URL of the identity provider to redirect the user to. The provider redirects back to the frontend with code and state.
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	userSchemas.OIDCAuthorizationResponse
+//	@Failure		400	{object}	errx.AppError
+//	@Failure		500	{object}	errx.AppError
+//	@Router			/api/v1/auth/oidc [get]
+func (c *UserController) GetOIDCAuthorizationURL(w http.ResponseWriter, r *http.Request) {
+	authorizationURL, err := c.userService.GetOIDCAuthorizationURL()
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, &userSchemas.OIDCAuthorizationResponse{Url: authorizationURL})
+}
+
+// AuthOIDC godoc
+//
+//	@Summary	Auth with OIDC authorization code
+//	@Tags		Auth
+//	@Accept		json
+//	@Produce	json
+//	@Param		request	body		userSchemas.OIDCAuthRequest	true	"Code and state from the redirect"
+//	@Param		X-Guest-Token	header		string	false	"Guest token, its marks are moved to the account"
+//	@Success	200		{object}	userSchemas.TokenResponse
+//	@Failure	400		{object}	errx.AppError
+//	@Failure	401		{object}	errx.AppError
+//	@Failure	403		{object}	errx.AppError
+//	@Failure	500		{object}	errx.AppError
+//	@Router		/api/v1/auth/oidc [post]
+func (c *UserController) AuthOIDC(w http.ResponseWriter, r *http.Request) {
+	request := &userSchemas.OIDCAuthRequest{}
+	if err := render.Bind(r, request); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	credentials := map[string]string{"code": request.Code, "state": request.State}
+	tokens, err := c.userService.SignIn(entity.IdentityProviderOIDC, credentials, userSchemas.NewDeviceInfo(r), c.jwtKeys)
+
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	c.mergeGuest(r, tokens)
+
+	c.setRefreshTokenCookie(w, tokens.RefreshToken)
 	render.JSON(w, r, tokens)
 }
 
@@ -140,37 +275,380 @@ func (c *UserController) UpdateAccessToken(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	tokens, err := c.userService.UpdateAccessToken(requestCookie.Value, c.jwtSecret)
+	tokens, err := c.userService.UpdateAccessToken(requestCookie.Value, userSchemas.NewDeviceInfo(r), c.jwtKeys)
+
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	c.setRefreshTokenCookie(w, tokens.RefreshToken)
+	render.JSON(w, r, tokens)
 
+}
+
+// Logout godoc
+//
+//	@Summary		Logout
+//	@Description	Revokes the refresh token from the cookie and expires the cookie. Succeeds even if the token is missing or expired.
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	bool
+//	@Failure		500	{object}	errx.AppError
+//	@Router			/api/v1/auth/logout [post]
+func (c *UserController) Logout(w http.ResponseWriter, r *http.Request) {
+	var signedRefreshToken string
+	requestCookie, err := r.Cookie("refreshToken")
+	if err == nil {
+		signedRefreshToken = requestCookie.Value
+	}
+
+	err = c.userService.Logout(signedRefreshToken, c.jwtKeys)
 	if err != nil {
 		handleError(w, r, c.log, err)
 		return
 	}
 
-	newCookie := &http.Cookie{
+	c.expireRefreshTokenCookie(w)
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, true)
+}
+
+// GetMe godoc
+//
+//	@Summary	Return profile of the current user
+//	@Tags		Me
+//	@Accept		json
+//	@Produce	json
+//	@Success	200	{object}	userSchemas.ProfileResponseModel
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	404	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/me [get]
+//	@Security	BearerAuth
+func (c *UserController) GetMe(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	user, statistics, err := c.userService.GetProfile(userClaims.Id)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, userSchemas.NewProfileResponseModel(user, statistics))
+}
+
+// UpdateMe godoc
+//
+//	@Summary	Update profile of the current user
+//	@Tags		Me
+//	@Accept		json
+//	@Produce	json
+//	@Param		profile	body		userSchemas.ProfileRequestModel	true	"Profile"
+//	@Success	200		{object}	userSchemas.ProfileResponseModel
+//	@Failure	400		{object}	errx.AppError
+//	@Failure	401		{object}	errx.AppError
+//	@Failure	404		{object}	errx.AppError
+//	@Failure	500		{object}	errx.AppError
+//	@Router		/api/v1/me [put]
+//	@Security	BearerAuth
+func (c *UserController) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	profileRequest := &userSchemas.ProfileRequestModel{}
+	if err := render.Bind(r, profileRequest); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	user, statistics, err := c.userService.UpdateProfile(userClaims.Id, profileRequest)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, userSchemas.NewProfileResponseModel(user, statistics))
+}
+
+// DeleteMe godoc
+//
+//	@Summary	Delete account of the current user
+//	@Tags		Me
+//	@Accept		json
+//	@Produce	json
+//	@Success	200	{object}	bool
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	404	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/me [delete]
+//	@Security	BearerAuth
+func (c *UserController) DeleteMe(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	err := c.userService.DeleteAccount(userClaims.Id)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	c.expireRefreshTokenCookie(w)
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, true)
+}
+
+// GetMySessions godoc
+//
+//	@Summary	Return active sessions of the current user
+//	@Tags		Me
+//	@Accept		json
+//	@Produce	json
+//	@Success	200	{object}	[]userSchemas.SessionResponseModel
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/me/sessions [get]
+//	@Security	BearerAuth
+func (c *UserController) GetMySessions(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	sessions, err := c.userService.GetSessions(userClaims.Id)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	response := make([]*userSchemas.SessionResponseModel, len(sessions))
+	for i := range sessions {
+		response[i] = userSchemas.NewSessionResponseModel(&sessions[i], userClaims.SessionId)
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response)
+}
+
+// RevokeMySession godoc
+//
+//	@Summary	Revoke session of the current user
+//	@Tags		Me
+//	@Accept		json
+//	@Produce	json
+//	@Param		id	path		string	true	"Session ID"
+//	@Success	200	{object}	bool
+//	@Failure	400	{object}	errx.AppError
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	404	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/me/sessions/{id} [delete]
+//	@Security	BearerAuth
+func (c *UserController) RevokeMySession(w http.ResponseWriter, r *http.Request) {
+	sessionId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	err = c.userService.RevokeSession(userClaims.Id, sessionId)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	if sessionId == userClaims.SessionId {
+		c.expireRefreshTokenCookie(w)
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, true)
+}
+
+// RevokeAllMySessions godoc
+//
+//	@Summary		Revoke all sessions of the current user
+//	@Description	Signs the user out on every device, the current one included. Issued access tokens stop working immediately.
+//	@Tags			Me
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	bool
+//	@Failure		401	{object}	errx.AppError
+//	@Failure		500	{object}	errx.AppError
+//	@Router			/api/v1/me/sessions [delete]
+//	@Security		BearerAuth
+func (c *UserController) RevokeAllMySessions(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	err := c.userService.RevokeAllSessions(userClaims.Id)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	c.expireRefreshTokenCookie(w)
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, true)
+}
+
+// GetMyIdentities godoc
+//
+//	@Summary	Return identities linked to the current user
+//	@Tags		Me
+//	@Accept		json
+//	@Produce	json
+//	@Success	200	{object}	[]userSchemas.IdentityResponseModel
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/me/identities [get]
+//	@Security	BearerAuth
+func (c *UserController) GetMyIdentities(w http.ResponseWriter, r *http.Request) {
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	identities, err := c.userService.GetIdentities(userClaims.Id)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	response := make([]*userSchemas.IdentityResponseModel, len(identities))
+	for i := range identities {
+		response[i] = userSchemas.NewIdentityResponseModel(&identities[i])
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, response)
+}
+
+// LinkMyIdentity godoc
+//
+//	@Summary		Link identity to the current user
+//	@Description	Takes the credentials of the provider as they are sent on sign in: telegram, telegram_mini_app, email or oidc.
+//	@Tags			Me
+//	@Accept			json
+//	@Produce		json
+//	@Param			provider	path		string							true	"Identity provider"
+//	@Param			request		body		userSchemas.LinkIdentityRequest	true	"Credentials"
+//	@Success		200			{object}	userSchemas.IdentityResponseModel
+//	@Failure		400			{object}	errx.AppError
+//	@Failure		401			{object}	errx.AppError
+//	@Failure		404			{object}	errx.AppError
+//	@Failure		500			{object}	errx.AppError
+//	@Router			/api/v1/me/identities/{provider} [post]
+//	@Security		BearerAuth
+func (c *UserController) LinkMyIdentity(w http.ResponseWriter, r *http.Request) {
+	request := &userSchemas.LinkIdentityRequest{}
+	if err := render.Bind(r, request); err != nil {
+		errResponse := errx.NewBadRequestError(err)
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	identity, err := c.userService.LinkIdentity(userClaims.Id, chi.URLParam(r, "provider"), request.Credentials)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, userSchemas.NewIdentityResponseModel(identity))
+}
+
+// UnlinkMyIdentity godoc
+//
+//	@Summary	Unlink identity from the current user
+//	@Tags		Me
+//	@Accept		json
+//	@Produce	json
+//	@Param		id	path		string	true	"Identity ID"
+//	@Success	200	{object}	bool
+//	@Failure	400	{object}	errx.AppError
+//	@Failure	401	{object}	errx.AppError
+//	@Failure	404	{object}	errx.AppError
+//	@Failure	500	{object}	errx.AppError
+//	@Router		/api/v1/me/identities/{id} [delete]
+//	@Security	BearerAuth
+func (c *UserController) UnlinkMyIdentity(w http.ResponseWriter, r *http.Request) {
+	identityId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("invalid id"))
+		handleError(w, r, c.log, errResponse)
+		return
+	}
+
+	userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+	err = c.userService.UnlinkIdentity(userClaims.Id, identityId)
+	if err != nil {
+		handleError(w, r, c.log, err)
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, true)
+}
+
+// mergeGuest moves the marks of the guest the request comes from to the user
+// that has just signed in. The sign-in itself does not depend on it.
+func (c *UserController) mergeGuest(r *http.Request, tokens *userSchemas.UserTokens) {
+	guestToken := r.Header.Get(guestTokenHeader)
+	if guestToken == "" {
+		return
+	}
+
+	err := c.guestService.MergeIntoUser(guestToken, tokens.AccessToken.Claims.Id, c.jwtKeys)
+	if err != nil {
+		c.log.Error("Guest merge error", "error", err.Error(), "userId", tokens.AccessToken.Claims.Id.String())
+	}
+}
+
+func (c *UserController) setRefreshTokenCookie(w http.ResponseWriter, refreshToken *userSchemas.RefreshToken) {
+	cookie := c.newRefreshTokenCookie(refreshToken.SignedValue)
+	cookie.Expires = refreshToken.Claims.Exp.Time
+	http.SetCookie(w, cookie)
+}
+
+// expireRefreshTokenCookie must keep the attributes of setRefreshTokenCookie,
+// otherwise browsers treat it as another cookie and keep the old one.
+func (c *UserController) expireRefreshTokenCookie(w http.ResponseWriter) {
+	cookie := c.newRefreshTokenCookie("")
+	cookie.MaxAge = -1
+	http.SetCookie(w, cookie)
+}
+
+func (c *UserController) newRefreshTokenCookie(value string) *http.Cookie {
+	return &http.Cookie{
 		Name:     "refreshToken",
-		Value:    tokens.RefreshToken.SignedValue,
-		Expires:  tokens.RefreshToken.Claims.Exp.Time,
+		Value:    value,
 		Path:     "/",
 		HttpOnly: true,
 		Secure:   true,
 	}
-
-	http.SetCookie(w, newCookie)
-	render.JSON(w, r, tokens)
-
 }
 
 func (c *UserController) AuthMiddleware(required bool) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Requests authenticated by ApiKeyMiddleware already carry claims.
+			if claims, ok := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims); ok && claims.ApiKeyId != uuid.Nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
 			authHeader := r.Header.Get("Authorization")
 			if !required && authHeader == "" {
 				next.ServeHTTP(w, r)
 				return
 			}
 
-			accessTokenClaims, err := c.userService.CheckAuthToken(authHeader, c.jwtSecret)
+			accessTokenClaims, err := c.userService.CheckAuthToken(authHeader, c.jwtKeys)
 
 			if err != nil {
 				handleError(w, r, c.log, err)
@@ -183,17 +661,67 @@ func (c *UserController) AuthMiddleware(required bool) func(http.Handler) http.H
 	}
 }
 
-func (c *UserController) AdminMiddleware(next http.Handler) http.Handler {
+func (c *UserController) RequirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+			if userClaims.HasPermission(permission) {
+				next.ServeHTTP(w, r)
+			} else {
+				err := fmt.Errorf("user with id %s does not have %s permission", userClaims.Id, permission)
+				errResponse := errx.NewForbiddenError(err)
+				handleError(w, r, c.log, errResponse)
+				return
+			}
+		})
+	}
+}
+
+// RequireLocationPermission is RequirePermission for routes of a single
+// location. Users scoped to another location are rejected.
+func (c *UserController) RequireLocationPermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
+
+			locationId, err := uuid.Parse(chi.URLParam(r, "id"))
+			if err != nil {
+				errResponse := errx.NewBadRequestError(fmt.Errorf("invalid location id"))
+				handleError(w, r, c.log, errResponse)
+				return
+			}
+
+			if !userClaims.HasPermission(permission) {
+				err := fmt.Errorf("user with id %s does not have %s permission", userClaims.Id, permission)
+				errResponse := errx.NewForbiddenError(err)
+				handleError(w, r, c.log, errResponse)
+				return
+			}
+
+			if !userClaims.CanManageLocation(locationId) {
+				err := fmt.Errorf("user with id %s is scoped to another location", userClaims.Id)
+				errResponse := errx.NewForbiddenError(err)
+				handleError(w, r, c.log, errResponse)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireAllLocations guards the routes that affect every location, such as
+// the catalogue itself. Users scoped to a location are rejected.
+func (c *UserController) RequireAllLocations(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		userClaims := r.Context().Value("accessTokenClaims").(*userSchemas.AccessTokenClaims)
 
-		if userClaims.Role == "admin" {
-			next.ServeHTTP(w, r)
-		} else {
-			err := fmt.Errorf("user with id %s does not have admin permissions", userClaims.Id)
+		if userClaims.LocationId != uuid.Nil {
+			err := fmt.Errorf("user with id %s is scoped to a location", userClaims.Id)
 			errResponse := errx.NewForbiddenError(err)
 			handleError(w, r, c.log, errResponse)
 			return
 		}
+		next.ServeHTTP(w, r)
 	})
 }
@@ -0,0 +1,52 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	ApiKeyScopeCatalogRead  = "catalog:read"
+	ApiKeyScopeCatalogWrite = "catalog:write"
+	ApiKeyScopeStockWrite   = "stock:write"
+)
+
+// ApiKeyScopePermissions maps the scopes of API keys to the permissions they
+// grant, so routes check keys and users the same way. catalog:read grants no
+// permission, the catalogue is readable by anyone.
+var ApiKeyScopePermissions = map[string][]string{
+	ApiKeyScopeCatalogRead: {},
+	ApiKeyScopeCatalogWrite: {
+		PermissionTeasWrite,
+		PermissionCategoriesWrite,
+		PermissionTagsWrite,
+		PermissionUnitsWrite,
+	},
+	ApiKeyScopeStockWrite: {
+		PermissionStockWrite,
+	},
+}
+
+// ApiKey is a credential of an integration. Only a hash of the key is stored,
+// the prefix is kept to tell keys apart in the admin panel.
+type ApiKey struct {
+	Id         uuid.UUID  `db:"id"`
+	Name       string     `db:"name"`
+	Prefix     string     `db:"prefix"`
+	KeyHash    string     `db:"key_hash"`
+	Scopes     []string   `db:"-"`
+	CreatedBy  *uuid.UUID `db:"created_by"`
+	CreatedAt  time.Time  `db:"created_at"`
+	ExpiresAt  *time.Time `db:"expires_at"`
+	LastUsedAt *time.Time `db:"last_used_at"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+}
+
+func (k *ApiKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	DiscountKindPercent = "percent"
+	DiscountKindFixed   = "fixed"
+)
+
+const (
+	DiscountScopeOrder    = "order"
+	DiscountScopeCategory = "category"
+	DiscountScopeTag      = "tag"
+	DiscountScopeTea      = "tea"
+)
+
+// Discount is a promo code if it has a code and an automatic rule otherwise.
+// The target is the category, the tag or the tea the discount is limited to,
+// depending on the scope. UsageCount is the number of orders it has been
+// redeemed in. A deleted discount is kept for the redemptions report.
+type Discount struct {
+	Id             uuid.UUID  `db:"id"`
+	Name           string     `db:"name"`
+	Code           string     `db:"code"`
+	Kind           string     `db:"kind"`
+	Value          float64    `db:"value"`
+	Scope          string     `db:"scope"`
+	TargetId       *uuid.UUID `db:"target_id"`
+	MinOrderAmount float64    `db:"min_order_amount"`
+	StartsAt       *time.Time `db:"starts_at"`
+	EndsAt         *time.Time `db:"ends_at"`
+	UsageLimit     *int       `db:"usage_limit"`
+	PerUserLimit   *int       `db:"per_user_limit"`
+	IsActive       bool       `db:"is_active"`
+	UsageCount     int        `db:"usage_count"`
+	DeletedAt      *time.Time `db:"deleted_at"`
+	CreatedAt      time.Time  `db:"created_at"`
+	UpdatedAt      time.Time  `db:"updated_at"`
+}
+
+func (d *Discount) IsPromoCode() bool {
+	return d.Code != ""
+}
+
+// IsValidAt tells whether the discount is active and within its validity
+// period at the time.
+func (d *Discount) IsValidAt(t time.Time) bool {
+	if !d.IsActive {
+		return false
+	}
+	if d.StartsAt != nil && t.Before(*d.StartsAt) {
+		return false
+	}
+	return d.EndsAt == nil || t.Before(*d.EndsAt)
+}
+
+// AmountOf returns the discount of the amount it applies to. A fixed
+// discount never exceeds the amount.
+func (d *Discount) AmountOf(amount float64) float64 {
+	if d.Kind == DiscountKindPercent {
+		return RoundPrice(amount * d.Value / 100)
+	}
+	return min(d.Value, amount)
+}
+
+// DiscountUsage counts the orders a discount has been redeemed in, in total
+// and by a single user.
+type DiscountUsage struct {
+	DiscountId uuid.UUID `db:"discount_id"`
+	Total      int       `db:"total"`
+	ByUser     int       `db:"by_user"`
+}
+
+type AppliedDiscount struct {
+	DiscountId uuid.UUID
+	Name       string
+	Code       string
+	Amount     float64
+}
+
+type DiscountRedemption struct {
+	Id           uuid.UUID  `db:"id"`
+	DiscountId   uuid.UUID  `db:"discount_id"`
+	OrderId      uuid.UUID  `db:"order_id"`
+	OrderNumber  uint64     `db:"order_number"`
+	UserId       *uuid.UUID `db:"user_id"`
+	CustomerName string     `db:"customer_name"`
+	Amount       float64    `db:"amount"`
+	CreatedAt    time.Time  `db:"created_at"`
+}
+
+// DiscountReport sums up the redemptions of a discount over a period.
+type DiscountReport struct {
+	DiscountId  uuid.UUID `db:"discount_id"`
+	Name        string    `db:"name"`
+	Code        string    `db:"code"`
+	Redemptions int       `db:"redemptions"`
+	Amount      float64   `db:"amount"`
+}
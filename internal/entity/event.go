@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	EventRegistrationRegistered = "registered"
+	// EventRegistrationWaitlisted is a sign-up for a full event. Waitlisted
+	// users take the places freed by cancellations in the order they signed up.
+	EventRegistrationWaitlisted = "waitlisted"
+)
+
+// Event is a tasting users sign up for. RegistrationStatus is the status of
+// the user the event is loaded for and is empty if the user has not signed up.
+type Event struct {
+	Id                 uuid.UUID  `db:"id"`
+	Title              string     `db:"title"`
+	Description        string     `db:"description"`
+	StartsAt           time.Time  `db:"starts_at"`
+	EndsAt             time.Time  `db:"ends_at"`
+	Capacity           int        `db:"capacity"`
+	Price              float64    `db:"price"`
+	LocationId         *uuid.UUID `db:"location_id"`
+	LocationName       string     `db:"location_name"`
+	LocationAddress    string     `db:"location_address"`
+	RegisteredCount    int        `db:"registered_count"`
+	WaitlistedCount    int        `db:"waitlisted_count"`
+	RegistrationStatus string     `db:"registration_status"`
+	CreatedAt          time.Time  `db:"created_at"`
+	UpdatedAt          time.Time  `db:"updated_at"`
+	Teas               []EventTea `db:"-"`
+}
+
+func (e *Event) SpotsLeft() int {
+	return max(e.Capacity-e.RegisteredCount, 0)
+}
+
+func (e *Event) HasStarted() bool {
+	return !time.Now().Before(e.StartsAt)
+}
+
+type EventTea struct {
+	EventId  uuid.UUID `db:"event_id"`
+	TeaId    uuid.UUID `db:"tea_id"`
+	TeaName  string    `db:"tea_name"`
+	Position int       `db:"position"`
+}
+
+type EventAttendee struct {
+	UserId    uuid.UUID `db:"user_id"`
+	FirstName string    `db:"first_name"`
+	LastName  string    `db:"last_name"`
+	Username  string    `db:"username"`
+	Status    string    `db:"status"`
+	CreatedAt time.Time `db:"created_at"`
+}
@@ -0,0 +1,26 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	GuestMarkFavourite = "favourite"
+	GuestMarkWantToTry = "want_to_try"
+)
+
+// Guest is an anonymous visitor identified by a signed token kept on the
+// device. Its marks are moved to the user account on sign-in.
+type Guest struct {
+	Id         uuid.UUID `db:"id"`
+	CreatedAt  time.Time `db:"created_at"`
+	LastUsedAt time.Time `db:"last_used_at"`
+}
+
+type GuestTeaMark struct {
+	TeaId     uuid.UUID `db:"tea_id"`
+	TeaName   string    `db:"tea_name"`
+	Mark      string    `db:"mark"`
+	CreatedAt time.Time `db:"created_at"`
+}
@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	IdentityProviderTelegram = "telegram"
+	IdentityProviderEmail    = "email"
+	IdentityProviderOIDC     = "oidc"
+
+	// IdentityProviderTelegramMiniApp only names a way of signing in, its
+	// identities are stored as IdentityProviderTelegram.
+	IdentityProviderTelegramMiniApp = "telegram_mini_app"
+)
+
+// Identity links a user to an account of an identity provider. The subject
+// is the account id within the provider: the Telegram user id, the email
+// address or the OIDC "sub" claim.
+type Identity struct {
+	Id         uuid.UUID `db:"id"`
+	UserId     uuid.UUID `db:"user_id"`
+	Provider   string    `db:"provider"`
+	Subject    string    `db:"subject"`
+	Email      string    `db:"email"`
+	CreatedAt  time.Time `db:"created_at"`
+	LastUsedAt time.Time `db:"last_used_at"`
+}
+
+// ExternalIdentity is what an identity provider has verified about the
+// account. The profile fields fill in a new user, Telegram sign-ins also
+// refresh them for an existing one.
+type ExternalIdentity struct {
+	Provider     string
+	Subject      string
+	Email        string
+	FirstName    string
+	LastName     string
+	Username     string
+	PhotoUrl     string
+	LanguageCode string
+	IsPremium    bool
+	// HasLanguageAndPremium tells whether the provider sent the language
+	// and the premium status. The Telegram login widget does not.
+	HasLanguageAndPremium bool
+	TelegramId            uint64
+}
+
+type OIDCLoginState struct {
+	State        string    `db:"state"`
+	Nonce        string    `db:"nonce"`
+	CodeVerifier string    `db:"code_verifier"`
+	CreatedAt    time.Time `db:"created_at"`
+	ExpiresAt    time.Time `db:"expires_at"`
+}
@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	FavouriteListName = "Favourites"
+	WantToTryListName = "Want to try"
+)
+
+type TeaList struct {
+	Id          uuid.UUID     `db:"id"`
+	UserId      uuid.UUID     `db:"user_id"`
+	Name        string        `db:"name"`
+	IsFavourite bool          `db:"is_favourite"`
+	ShareToken  string        `db:"share_token"`
+	OwnerName   string        `db:"owner_name"`
+	ItemsCount  uint64        `db:"items_count"`
+	CreatedAt   time.Time     `db:"created_at"`
+	UpdatedAt   time.Time     `db:"updated_at"`
+	Items       []TeaListItem `db:"-"`
+}
+
+type TeaListItem struct {
+	TeaId     uuid.UUID `db:"tea_id"`
+	TeaName   string    `db:"tea_name"`
+	Position  int       `db:"position"`
+	Note      string    `db:"note"`
+	CreatedAt time.Time `db:"created_at"`
+	Tea       *Tea      `db:"-"`
+}
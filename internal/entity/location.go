@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type Location struct {
+	Id        uuid.UUID `db:"id"`
+	Name      string    `db:"name"`
+	Address   string    `db:"address"`
+	IsDefault bool      `db:"is_default"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// TeaLocationOverride holds the values of a tea that differ in a location.
+// Nil values fall back to the catalogue values of the tea.
+type TeaLocationOverride struct {
+	TeaId      uuid.UUID `db:"tea_id"`
+	LocationId uuid.UUID `db:"location_id"`
+	IsHidden   *bool     `db:"is_hidden"`
+	ServePrice *float64  `db:"serve_price"`
+	UnitPrice  *float64  `db:"unit_price"`
+	Stock      *float64  `db:"stock"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+func (o *TeaLocationOverride) ApplyTo(tea *Tea) {
+	if o.IsHidden != nil {
+		tea.IsHidden = *o.IsHidden
+	}
+	if o.ServePrice != nil {
+		tea.ServePrice = *o.ServePrice
+	}
+	if o.UnitPrice != nil {
+		tea.UnitPrice = *o.UnitPrice
+	}
+	if o.Stock != nil {
+		stock := *o.Stock
+		tea.Stock = &stock
+	}
+}
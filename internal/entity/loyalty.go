@@ -0,0 +1,46 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	LoyaltyKindEarn   = "earn"
+	LoyaltyKindRedeem = "redeem"
+	// LoyaltyKindRefund returns the points redeemed against a cancelled order.
+	LoyaltyKindRefund = "refund"
+	// LoyaltyKindAdjust is a change made by staff with a reason.
+	LoyaltyKindAdjust = "adjust"
+)
+
+// LoyaltyTransaction is an entry of the loyalty ledger of a user. Earned,
+// refunded and added points are positive, redeemed and taken ones negative,
+// so the balance is the sum of the entries.
+type LoyaltyTransaction struct {
+	Id          uuid.UUID  `db:"id"`
+	UserId      uuid.UUID  `db:"user_id"`
+	OrderId     *uuid.UUID `db:"order_id"`
+	OrderNumber *uint64    `db:"order_number"`
+	Kind        string     `db:"kind"`
+	Points      int        `db:"points"`
+	Reason      string     `db:"reason"`
+	CreatedBy   *uuid.UUID `db:"created_by"`
+	CreatedAt   time.Time  `db:"created_at"`
+}
+
+// LoyaltyRule tells how many points the teas of the category earn when the
+// order is served.
+type LoyaltyRule struct {
+	CategoryId    uuid.UUID `db:"category_id"`
+	CategoryName  string    `db:"category_name"`
+	ServePoints   int       `db:"serve_points"`
+	AmountPercent float64   `db:"amount_percent"`
+}
+
+// LoyaltyBalance is the balance of a user along with the discount one point
+// gives.
+type LoyaltyBalance struct {
+	Balance    int
+	PointValue float64
+}
@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	NotificationStatusPending = "pending"
+	NotificationStatusFailed  = "failed"
+)
+
+// Notification is a message waiting in the queue. Delivered notifications
+// are removed, failed ones are kept to see what went wrong.
+type Notification struct {
+	Id            uuid.UUID `db:"id"`
+	UserId        uuid.UUID `db:"user_id"`
+	Recipient     string    `db:"recipient"`
+	Text          string    `db:"text"`
+	Status        string    `db:"status"`
+	Attempts      int       `db:"attempts"`
+	LastError     string    `db:"last_error"`
+	NextAttemptAt time.Time `db:"next_attempt_at"`
+	CreatedAt     time.Time `db:"created_at"`
+}
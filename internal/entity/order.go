@@ -0,0 +1,149 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"math"
+	"slices"
+	"time"
+)
+
+const (
+	// OrderItemKindServe is a brewed serving priced by serve_price.
+	OrderItemKindServe = "serve"
+	// OrderItemKindWeight is loose tea in units of the tea priced by unit_price.
+	OrderItemKindWeight = "weight"
+)
+
+const (
+	OrderStatusNew       = "new"
+	OrderStatusAccepted  = "accepted"
+	OrderStatusBrewing   = "brewing"
+	OrderStatusReady     = "ready"
+	OrderStatusServed    = "served"
+	OrderStatusCancelled = "cancelled"
+)
+
+// ActiveOrderStatuses are the statuses of orders that are still in the queue.
+var ActiveOrderStatuses = []string{
+	OrderStatusNew,
+	OrderStatusAccepted,
+	OrderStatusBrewing,
+	OrderStatusReady,
+}
+
+// orderStatusTransitions lists the statuses an order can move to from each
+// status. Served and cancelled orders are final.
+var orderStatusTransitions = map[string][]string{
+	OrderStatusNew:      {OrderStatusAccepted, OrderStatusCancelled},
+	OrderStatusAccepted: {OrderStatusBrewing, OrderStatusCancelled},
+	OrderStatusBrewing:  {OrderStatusReady, OrderStatusCancelled},
+	OrderStatusReady:    {OrderStatusServed, OrderStatusCancelled},
+}
+
+func IsOrderStatus(status string) bool {
+	_, ok := orderStatusTransitions[status]
+	return ok || status == OrderStatusServed || status == OrderStatusCancelled
+}
+
+const (
+	OrderEventCreated = "order.created"
+	OrderEventUpdated = "order.updated"
+)
+
+// OrderEvent tells the order queue that an order has been placed or has
+// changed its status.
+type OrderEvent struct {
+	Type  string
+	Order *Order
+}
+
+// CartItem keeps the price the tea had when it was put into the cart. The
+// current state of the tea in the location of the cart is loaded alongside to
+// check the item before the order is placed. All items of a cart are of the
+// same location.
+type CartItem struct {
+	Id           uuid.UUID `db:"id"`
+	UserId       uuid.UUID `db:"user_id"`
+	LocationId   uuid.UUID `db:"location_id"`
+	TeaId        uuid.UUID `db:"tea_id"`
+	TeaName      string    `db:"tea_name"`
+	CategoryId   uuid.UUID `db:"category_id"`
+	Kind         string    `db:"kind"`
+	Quantity     float64   `db:"quantity"`
+	Price        float64   `db:"price"`
+	CurrentPrice float64   `db:"current_price"`
+	IsHidden     bool      `db:"is_hidden"`
+	Stock        *float64  `db:"stock"`
+	CreatedAt    time.Time `db:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at"`
+}
+
+func (i *CartItem) Amount() float64 {
+	return RoundPrice(i.Price * i.Quantity)
+}
+
+// Cart is the cart of a user priced with the discounts that apply to it. The
+// prices of the items stay the list prices.
+type Cart struct {
+	Items     []CartItem
+	PromoCode string
+	Subtotal  float64
+	Discounts []AppliedDiscount
+	Discount  float64
+	Total     float64
+}
+
+// Order outlives the account of its user and its location, their ids are
+// cleared then. The total is what the customer pays, the discount sums up the
+// discounts and the redeemed loyalty points.
+type Order struct {
+	Id             uuid.UUID   `db:"id"`
+	Number         uint64      `db:"number"`
+	UserId         uuid.UUID   `db:"user_id"`
+	CustomerName   string      `db:"customer_name"`
+	LocationId     *uuid.UUID  `db:"location_id"`
+	LocationName   string      `db:"location_name"`
+	Status         string      `db:"status"`
+	Total          float64     `db:"total"`
+	Discount       float64     `db:"discount"`
+	RedeemedPoints int         `db:"redeemed_points"`
+	Comment        string      `db:"comment"`
+	CreatedAt      time.Time   `db:"created_at"`
+	UpdatedAt      time.Time   `db:"updated_at"`
+	Items          []OrderItem `db:"-"`
+	// Discounts are the discounts to redeem when the order is placed.
+	Discounts []AppliedDiscount `db:"-"`
+}
+
+func (o *Order) CanMoveTo(status string) bool {
+	return slices.Contains(orderStatusTransitions[o.Status], status)
+}
+
+func (o *Order) IsActive() bool {
+	return slices.Contains(ActiveOrderStatuses, o.Status)
+}
+
+// OrderItem is a snapshot of a cart item. The tea id is lost if the tea is
+// deleted, the name stays.
+type OrderItem struct {
+	Id       uuid.UUID  `db:"id"`
+	OrderId  uuid.UUID  `db:"order_id"`
+	TeaId    *uuid.UUID `db:"tea_id"`
+	TeaName  string     `db:"tea_name"`
+	Kind     string     `db:"kind"`
+	Quantity float64    `db:"quantity"`
+	Price    float64    `db:"price"`
+	Amount   float64    `db:"amount"`
+}
+
+// TeaPrice returns the price of the tea for the kind of an order item.
+func TeaPrice(tea *Tea, kind string) float64 {
+	if kind == OrderItemKindWeight {
+		return tea.UnitPrice
+	}
+	return tea.ServePrice
+}
+
+func RoundPrice(price float64) float64 {
+	return math.Round(price*100) / 100
+}
@@ -0,0 +1,41 @@
+package entity
+
+import "github.com/google/uuid"
+
+const (
+	PermissionTeasWrite       = "teas:write"
+	PermissionTeasVisibility  = "teas:visibility"
+	PermissionStockWrite      = "stock:write"
+	PermissionTagsWrite       = "tags:write"
+	PermissionCategoriesWrite = "categories:write"
+	PermissionUnitsWrite      = "units:write"
+	PermissionReviewsModerate = "reviews:moderate"
+	PermissionUsersManage     = "users:manage"
+	PermissionRolesManage     = "roles:manage"
+	PermissionApiKeysManage   = "api_keys:manage"
+	PermissionOrdersManage    = "orders:manage"
+	PermissionLocationsManage = "locations:manage"
+	PermissionEventsManage    = "events:manage"
+	PermissionLoyaltyAdjust   = "loyalty:adjust"
+	PermissionLoyaltyRules    = "loyalty:rules"
+	PermissionDiscountsManage = "discounts:manage"
+)
+
+const (
+	RoleAdmin  = "admin"
+	RoleUser   = "user"
+	RoleApiKey = "api_key"
+)
+
+type Permission struct {
+	Code        string `db:"code"`
+	Description string `db:"description"`
+}
+
+type Role struct {
+	Id          uuid.UUID `db:"id"`
+	Name        string    `db:"name"`
+	Description string    `db:"description"`
+	IsSystem    bool      `db:"is_system"`
+	Permissions []string  `db:"-"`
+}
@@ -0,0 +1,36 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	SessionRevokeReasonUser       = "user"
+	SessionRevokeReasonRevokeAll  = "revoke_all"
+	SessionRevokeReasonLogout     = "logout"
+	SessionRevokeReasonTokenReuse = "refresh_token_reuse"
+)
+
+type Session struct {
+	Id            uuid.UUID  `db:"id"`
+	UserId        uuid.UUID  `db:"user_id"`
+	UserAgent     string     `db:"user_agent"`
+	IpAddress     string     `db:"ip_address"`
+	CreatedAt     time.Time  `db:"created_at"`
+	LastUsedAt    time.Time  `db:"last_used_at"`
+	ExpiresAt     time.Time  `db:"expires_at"`
+	RevokedAt     *time.Time `db:"revoked_at"`
+	RevokedReason string     `db:"revoked_reason"`
+}
+
+// RefreshToken is a single link of a session's token family. The first token
+// of a session has no parent, every rotation adds a child of the previous one.
+type RefreshToken struct {
+	Id        uuid.UUID  `db:"id"`
+	SessionId uuid.UUID  `db:"session_id"`
+	ParentId  *uuid.UUID `db:"parent_id"`
+	CreatedAt time.Time  `db:"created_at"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	RotatedAt *time.Time `db:"rotated_at"`
+}
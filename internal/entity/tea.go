@@ -14,6 +14,7 @@ type Tea struct {
 	CreatedAt   time.Time `db:"created_at" json:"createdAt"`
 	UpdatedAt   time.Time `db:"updated_at" json:"updatedAt"`
 	IsHidden    bool      `db:"is_hidden" json:"isHidden"`
+	Stock       *float64  `db:"stock" json:"stock,omitempty"`
 	CategoryId  uuid.UUID `db:"category_id" json:"categoryId"`
 	UnitId      uuid.UUID `db:"unit_id" json:"unitId"`
 	Tags        []Tag     `json:"tags,omitempty"`
@@ -26,3 +27,14 @@ type TeaWithRating struct {
 	AverageRating float64 `db:"average_rating, omitempty"`
 	IsFavourite   bool    `db:"is_favourite" json:"isFavourite"`
 }
+
+type TeaComparison struct {
+	TeaWithRating
+	Category *Category
+	Unit     *Unit
+}
+
+// IsAvailable tells whether the tea can be ordered right now.
+func (t *Tea) IsAvailable() bool {
+	return !t.IsHidden && (t.Stock == nil || *t.Stock > 0)
+}
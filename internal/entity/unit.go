@@ -4,6 +4,7 @@ import (
 	"database/sql/driver"
 	"fmt"
 	"github.com/google/uuid"
+	"math"
 )
 
 type WeightUnit int
@@ -75,3 +76,18 @@ func NewUnit(isApiece bool, weightUnit string, value int64) (*Unit, error) {
 		Value:      value,
 	}, nil
 }
+
+// PricePer100Grams normalises the price of the unit to 100 grams.
+// It returns false for apiece units, because they have no comparable weight.
+func (u *Unit) PricePer100Grams(price float64) (float64, bool) {
+	if u.IsApiece || u.Value <= 0 {
+		return 0, false
+	}
+
+	grams := float64(u.Value)
+	if u.WeightUnit == Kilogram {
+		grams *= 1000
+	}
+
+	return math.Round(price/grams*100*100) / 100, true
+}
@@ -6,14 +6,44 @@ import (
 )
 
 type User struct {
-	Id         uuid.UUID `db:"id"`
-	TelegramId uint64    `db:"telegram_id"`
-	FirstName  string    `db:"first_name"`
-	LastName   string    `db:"last_name,omitempty"`
-	Username   string    `db:"username,omitempty"`
-	CreatedAt  time.Time `db:"created_at"`
-	UpdatedAt  time.Time `db:"updated_at"`
-	IsAdmin    bool      `db:"is_admin"`
+	Id               uuid.UUID  `db:"id"`
+	TelegramId       uint64     `db:"telegram_id"`
+	FirstName        string     `db:"first_name"`
+	LastName         string     `db:"last_name,omitempty"`
+	Username         string     `db:"username,omitempty"`
+	PhotoUrl         string     `db:"photo_url"`
+	LanguageCode     string     `db:"language_code"`
+	IsPremium        bool       `db:"is_premium"`
+	CreatedAt        time.Time  `db:"created_at"`
+	UpdatedAt        time.Time  `db:"updated_at"`
+	IsAdmin          bool       `db:"is_admin"`
+	DisplayName      string     `db:"display_name"`
+	Language         string     `db:"language"`
+	NotifyOrders     bool       `db:"notify_orders"`
+	NotifyFavourites bool       `db:"notify_favourites"`
+	IsBlocked        bool       `db:"is_blocked"`
+	BlockedAt        *time.Time `db:"blocked_at"`
+	LocationId       uuid.UUID  `db:"location_id"`
+}
+
+type UserStatistics struct {
+	RatedCount      uint64 `db:"rated_count"`
+	FavouritesCount uint64 `db:"favourites_count"`
+	ListsCount      uint64 `db:"lists_count"`
+}
+
+type UserEvaluation struct {
+	TeaId     uuid.UUID `db:"tea_id"`
+	TeaName   string    `db:"tea_name"`
+	Rating    float64   `db:"rating"`
+	Note      string    `db:"note"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type UserActivity struct {
+	User            *User
+	Statistics      *UserStatistics
+	LastEvaluations []UserEvaluation
 }
 
 func NewEmptyUser(telegramId uint64, firstName, lastName, username string) *User {
@@ -23,6 +53,9 @@ func NewEmptyUser(telegramId uint64, firstName, lastName, username string) *User
 		LastName:   lastName,
 		Username:   username,
 		IsAdmin:    false,
+
+		NotifyOrders:     true,
+		NotifyFavourites: true,
 	}
 	return u
 }
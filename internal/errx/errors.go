@@ -51,3 +51,17 @@ func NewInternalServerError(error error) *AppError {
 		Message: error.Error(),
 	}
 }
+
+func NewTooManyRequestsError(error error) *AppError {
+	return &AppError{
+		Code:    http.StatusTooManyRequests,
+		Message: error.Error(),
+	}
+}
+
+func NewConflictError(error error) *AppError {
+	return &AppError{
+		Code:    http.StatusConflict,
+		Message: error.Error(),
+	}
+}
@@ -0,0 +1,61 @@
+package eventx
+
+import "sync"
+
+// Broker fans events out to the subscribers in the process memory. It is
+// enough for a single instance, a shared bus is needed once the API is
+// scaled out.
+type Broker[T any] struct {
+	mu          sync.RWMutex
+	subscribers map[chan T]struct{}
+	bufferSize  int
+}
+
+func NewBroker[T any](bufferSize int) *Broker[T] {
+	return &Broker[T]{
+		subscribers: make(map[chan T]struct{}),
+		bufferSize:  bufferSize,
+	}
+}
+
+// Subscribe returns a channel with the events published from now on and a
+// function that stops the subscription and closes the channel. The channel is
+// also closed if the subscriber falls behind, see Publish.
+func (b *Broker[T]) Subscribe() (<-chan T, func()) {
+	ch := make(chan T, b.bufferSize)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.remove(ch)
+	}
+	return ch, unsubscribe
+}
+
+// Publish sends the event to every subscriber. A subscriber that does not
+// keep up is dropped and its channel is closed instead of blocking the
+// publisher, so it knows it has missed events and can subscribe again.
+func (b *Broker[T]) Publish(event T) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			b.remove(ch)
+		}
+	}
+}
+
+func (b *Broker[T]) remove(ch chan T) {
+	if _, ok := b.subscribers[ch]; !ok {
+		return
+	}
+	delete(b.subscribers, ch)
+	close(ch)
+}
@@ -0,0 +1,95 @@
+package icsx
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	ContentType = "text/calendar; charset=utf-8"
+
+	timeLayout = "20060102T150405Z"
+	// lineSize is the length in octets RFC 5545 limits content lines to.
+	lineSize = 75
+)
+
+// Event is a VEVENT of an iCalendar file.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Start       time.Time
+	End         time.Time
+	Stamp       time.Time
+}
+
+// Calendar renders an iCalendar file with the events, ready to be imported
+// by calendar apps.
+func Calendar(productId string, events ...Event) []byte {
+	w := &writer{}
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", productId)
+	w.line("CALSCALE", "GREGORIAN")
+	w.line("METHOD", "PUBLISH")
+	for _, event := range events {
+		w.line("BEGIN", "VEVENT")
+		w.line("UID", event.UID)
+		w.line("DTSTAMP", formatTime(event.Stamp))
+		w.line("DTSTART", formatTime(event.Start))
+		w.line("DTEND", formatTime(event.End))
+		w.line("SUMMARY", escape(event.Summary))
+		if event.Description != "" {
+			w.line("DESCRIPTION", escape(event.Description))
+		}
+		if event.Location != "" {
+			w.line("LOCATION", escape(event.Location))
+		}
+		if event.URL != "" {
+			w.line("URL", event.URL)
+		}
+		w.line("END", "VEVENT")
+	}
+	w.line("END", "VCALENDAR")
+	return []byte(w.String())
+}
+
+type writer struct {
+	strings.Builder
+}
+
+// line writes the property folding it into lines of at most 75 octets.
+// Continuation lines start with a space, which counts towards their length.
+func (w *writer) line(name, value string) {
+	content := name + ":" + value
+	size := 0
+	for _, r := range content {
+		runeSize := utf8.RuneLen(r)
+		if size+runeSize > lineSize {
+			w.WriteString("\r\n ")
+			size = 1
+		}
+		w.WriteRune(r)
+		size += runeSize
+	}
+	w.WriteString("\r\n")
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeLayout)
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+func escape(text string) string {
+	return textEscaper.Replace(text)
+}
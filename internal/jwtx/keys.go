@@ -0,0 +1,225 @@
+package jwtx
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+type Config struct {
+	Algorithm    string
+	Secret       string
+	KeysDir      string
+	SigningKeyId string
+}
+
+// KeySet signs tokens with a single active key and verifies them with every
+// loaded key. Asymmetric keys are read from KeysDir, one PEM file per key,
+// and the file name without extension is used as the key ID. Retired keys
+// may be kept there as public keys until the tokens signed by them expire.
+//
+// If the secret is set alongside an asymmetric algorithm, HS256 tokens are
+// still accepted, so switching algorithms does not log everyone out.
+type KeySet struct {
+	method           jwt.SigningMethod
+	signingKeyId     string
+	signingKey       interface{}
+	secret           []byte
+	verificationKeys map[string]crypto.PublicKey
+}
+
+func NewKeySet(cfg Config) (*KeySet, error) {
+	k := &KeySet{
+		verificationKeys: make(map[string]crypto.PublicKey),
+	}
+	if cfg.Secret != "" {
+		k.secret = []byte(cfg.Secret)
+	}
+
+	switch cfg.Algorithm {
+	case "", AlgorithmHS256:
+		if k.secret == nil {
+			return nil, fmt.Errorf("jwt secret key is required for %s", AlgorithmHS256)
+		}
+		k.method = jwt.SigningMethodHS256
+		k.signingKey = k.secret
+		return k, nil
+	case AlgorithmRS256:
+		k.method = jwt.SigningMethodRS256
+	case AlgorithmEdDSA:
+		k.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported jwt algorithm %s", cfg.Algorithm)
+	}
+
+	if cfg.KeysDir == "" {
+		return nil, fmt.Errorf("jwt keys directory is required for %s", cfg.Algorithm)
+	}
+	if cfg.SigningKeyId == "" {
+		return nil, fmt.Errorf("jwt signing key id is required for %s", cfg.Algorithm)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(cfg.KeysDir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range paths {
+		keyId := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		privateKey, publicKey, err := k.loadKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s loading error: %w", keyId, err)
+		}
+
+		k.verificationKeys[keyId] = publicKey
+		if keyId == cfg.SigningKeyId {
+			if privateKey == nil {
+				return nil, fmt.Errorf("jwt signing key %s must be a private key", keyId)
+			}
+			k.signingKeyId = keyId
+			k.signingKey = privateKey
+		}
+	}
+
+	if k.signingKey == nil {
+		return nil, fmt.Errorf("jwt signing key %s is not found in %s", cfg.SigningKeyId, cfg.KeysDir)
+	}
+	return k, nil
+}
+
+func (k *KeySet) loadKey(path string) (interface{}, crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if k.method == jwt.SigningMethodRS256 {
+		if privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+			return privateKey, &privateKey.PublicKey, nil
+		}
+		publicKey, err := jwt.ParseRSAPublicKeyFromPEM(data)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, publicKey, nil
+	}
+
+	if privateKey, err := jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+		return privateKey, privateKey.(ed25519.PrivateKey).Public(), nil
+	}
+	publicKey, err := jwt.ParseEdPublicKeyFromPEM(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	return nil, publicKey, nil
+}
+
+func (k *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.method, claims)
+	if k.signingKeyId != "" {
+		token.Header["kid"] = k.signingKeyId
+	}
+	return token.SignedString(k.signingKey)
+}
+
+func (k *KeySet) Parse(tokenString string, options ...jwt.ParserOption) (*jwt.Token, error) {
+	options = append(options, jwt.WithValidMethods(k.validMethods()))
+	token, err := jwt.Parse(tokenString, k.keyFunc, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if !token.Valid {
+		return nil, fmt.Errorf("token is invalid")
+	}
+	return token, nil
+}
+
+func (k *KeySet) validMethods() []string {
+	methods := []string{k.method.Alg()}
+	if k.method != jwt.SigningMethodHS256 && k.secret != nil {
+		methods = append(methods, AlgorithmHS256)
+	}
+	return methods
+}
+
+func (k *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		if k.secret == nil {
+			return nil, fmt.Errorf("invalid signing method")
+		}
+		return k.secret, nil
+	}
+
+	keyId, _ := token.Header["kid"].(string)
+	publicKey, ok := k.verificationKeys[keyId]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", keyId)
+	}
+	return publicKey, nil
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public verification keys. It is empty for HS256, since
+// a shared secret must never be published.
+func (k *KeySet) JWKS() *JWKS {
+	keyIds := make([]string, 0, len(k.verificationKeys))
+	for keyId := range k.verificationKeys {
+		keyIds = append(keyIds, keyId)
+	}
+	sort.Strings(keyIds)
+
+	jwks := &JWKS{
+		Keys: make([]JWK, 0, len(keyIds)),
+	}
+	for _, keyId := range keyIds {
+		jwk := JWK{
+			Kid: keyId,
+			Use: "sig",
+			Alg: k.method.Alg(),
+		}
+
+		switch publicKey := k.verificationKeys[keyId].(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+		default:
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
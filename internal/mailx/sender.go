@@ -0,0 +1,76 @@
+package mailx
+
+import (
+	"fmt"
+	"github.com/levchenki/tea-api/internal/logx"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+)
+
+type Sender interface {
+	Send(to, subject, body string) error
+}
+
+// LogSender writes mails to the log instead of sending them. It is meant for
+// development, where sign-in links can be copied from the output.
+type LogSender struct {
+	log logx.AppLogger
+}
+
+func NewLogSender(log logx.AppLogger) *LogSender {
+	return &LogSender{
+		log: log,
+	}
+}
+
+func (s *LogSender) Send(to, subject, body string) error {
+	s.log.Info("Mail is not sent, SMTP is not configured",
+		"to", to,
+		"subject", subject,
+		"body", body,
+	)
+	return nil
+}
+
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+type SMTPSender struct {
+	cfg SMTPConfig
+}
+
+func NewSMTPSender(cfg SMTPConfig) *SMTPSender {
+	return &SMTPSender{
+		cfg: cfg,
+	}
+}
+
+func (s *SMTPSender) Send(to, subject, body string) error {
+	if strings.ContainsAny(to, "\r\n") {
+		return fmt.Errorf("invalid recipient %q", to)
+	}
+
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+
+	headers := []string{
+		fmt.Sprintf("From: %s", s.cfg.From),
+		fmt.Sprintf("To: %s", to),
+		fmt.Sprintf("Subject: %s", mime.QEncoding.Encode("utf-8", subject)),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+	}
+	message := strings.Join(headers, "\r\n") + "\r\n\r\n" + body
+
+	addr := net.JoinHostPort(s.cfg.Host, s.cfg.Port)
+	return smtp.SendMail(addr, auth, s.cfg.From, []string{to}, []byte(message))
+}
@@ -0,0 +1,117 @@
+package netx
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// ParseTrustedProxies parses the addresses of the reverse proxies whose
+// X-Real-IP and X-Forwarded-For headers are trusted. Both single IPs and
+// CIDR ranges are accepted.
+func ParseTrustedProxies(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		if strings.Contains(value, "/") {
+			prefix, err := netip.ParsePrefix(value)
+			if err != nil {
+				return nil, fmt.Errorf("trusted proxy %q is not a valid CIDR range", value)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(value)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q is not a valid IP", value)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// MustParseTrustedProxies is ParseTrustedProxies for values that have already
+// been validated, it panics on an invalid one.
+func MustParseTrustedProxies(values []string) []netip.Prefix {
+	prefixes, err := ParseTrustedProxies(values)
+	if err != nil {
+		panic(err)
+	}
+	return prefixes
+}
+
+// RealIP replaces the remote address of requests coming from a trusted proxy
+// with the client IP the proxy reports. Requests from anywhere else keep
+// their remote address, so clients can not pick their IP by sending the
+// headers themselves.
+func RealIP(trustedProxies []netip.Prefix) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if len(trustedProxies) == 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isTrusted(remoteAddr(r), trustedProxies) {
+				if clientIP, ok := forwardedClientIP(r, trustedProxies); ok {
+					r.RemoteAddr = net.JoinHostPort(clientIP.String(), "0")
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// forwardedClientIP prefers X-Real-IP. In X-Forwarded-For every proxy
+// appends the address it got the request from, so the client is the last
+// address that is not a trusted proxy.
+func forwardedClientIP(r *http.Request, trustedProxies []netip.Prefix) (netip.Addr, bool) {
+	if realIP, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+		return realIP.Unmap(), true
+	}
+
+	forwardedFor := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(forwardedFor) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(forwardedFor[i]))
+		if err != nil {
+			return netip.Addr{}, false
+		}
+		addr = addr.Unmap()
+		if !isTrusted(addr, trustedProxies) {
+			return addr, true
+		}
+	}
+	return netip.Addr{}, false
+}
+
+func remoteAddr(r *http.Request) netip.Addr {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}
+	}
+	return addr.Unmap()
+}
+
+func isTrusted(addr netip.Addr, trustedProxies []netip.Prefix) bool {
+	if !addr.IsValid() {
+		return false
+	}
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
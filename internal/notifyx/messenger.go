@@ -0,0 +1,81 @@
+package notifyx
+
+import (
+	"errors"
+	"sync"
+)
+
+// Messenger delivers a text message to a recipient. The recipient is an
+// address in the terms of the messenger, a chat id for Telegram.
+type Messenger interface {
+	Send(recipient, text string) error
+}
+
+// PermanentError means the message will never be delivered, for example
+// because the user has blocked the bot, so there is no point in retrying.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+func IsPermanent(err error) bool {
+	var permanentError *PermanentError
+	return errors.As(err, &permanentError)
+}
+
+type Message struct {
+	Recipient string
+	Text      string
+}
+
+// MemoryMessenger keeps messages in memory instead of sending them. It is
+// meant for tests only, nothing ever clears the messages.
+type MemoryMessenger struct {
+	mu       sync.Mutex
+	messages []Message
+	err      error
+}
+
+func NewMemoryMessenger() *MemoryMessenger {
+	return &MemoryMessenger{
+		messages: make([]Message, 0),
+	}
+}
+
+func (m *MemoryMessenger) Send(recipient, text string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return m.err
+	}
+	m.messages = append(m.messages, Message{
+		Recipient: recipient,
+		Text:      text,
+	})
+	return nil
+}
+
+// FailWith makes every following Send return the error. Nil makes it
+// succeed again.
+func (m *MemoryMessenger) FailWith(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.err = err
+}
+
+func (m *MemoryMessenger) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	messages := make([]Message, len(m.messages))
+	copy(messages, m.messages)
+	return messages
+}
@@ -0,0 +1,76 @@
+package notifyx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	telegramApiURL         = "https://api.telegram.org"
+	telegramRequestTimeout = 10 * time.Second
+)
+
+// TelegramMessenger sends messages through the Telegram Bot API. Users get
+// them only after they have started the bot, until then Telegram answers
+// with 403 and the message is dropped.
+type TelegramMessenger struct {
+	botToken string
+	client   *http.Client
+}
+
+func NewTelegramMessenger(botToken string) *TelegramMessenger {
+	return &TelegramMessenger{
+		botToken: botToken,
+		client: &http.Client{
+			Timeout: telegramRequestTimeout,
+		},
+	}
+}
+
+type telegramResponse struct {
+	Ok          bool   `json:"ok"`
+	ErrorCode   int    `json:"error_code"`
+	Description string `json:"description"`
+}
+
+func (m *TelegramMessenger) Send(recipient, text string) error {
+	body, err := json.Marshal(map[string]string{
+		"chat_id": recipient,
+		"text":    text,
+	})
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("%s/bot%s/sendMessage", telegramApiURL, m.botToken)
+	resp, err := m.client.Post(endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		// The url of the request holds the bot token, keep it out of the logs.
+		var urlError *url.Error
+		if errors.As(err, &urlError) {
+			err = urlError.Err
+		}
+		return fmt.Errorf("telegram request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	result := telegramResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	if err != nil {
+		return fmt.Errorf("telegram responded with status %d: %w", resp.StatusCode, err)
+	}
+	if result.Ok {
+		return nil
+	}
+
+	err = fmt.Errorf("telegram responded with %d: %s", result.ErrorCode, result.Description)
+	if result.ErrorCode == http.StatusBadRequest || result.ErrorCode == http.StatusForbidden {
+		return &PermanentError{Err: err}
+	}
+	return err
+}
@@ -0,0 +1,63 @@
+package ratex
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Policy allows Limit requests per Period. Short bursts up to Limit are
+// accepted, after that requests are spread evenly over the period.
+// A policy with a zero limit does not restrict anything.
+type Policy struct {
+	Limit  int
+	Period time.Duration
+}
+
+// ParsePolicy parses policies written as "<limit>/<period>", e.g. "10/1m".
+// "off" and an empty string give a policy without a limit.
+func ParsePolicy(s string) (Policy, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "off" {
+		return Policy{}, nil
+	}
+
+	rawLimit, rawPeriod, ok := strings.Cut(s, "/")
+	if !ok {
+		return Policy{}, fmt.Errorf("rate limit policy %q must look like <limit>/<period>", s)
+	}
+
+	limit, err := strconv.Atoi(rawLimit)
+	if err != nil || limit < 0 {
+		return Policy{}, fmt.Errorf("rate limit policy %q has invalid limit", s)
+	}
+
+	period, err := time.ParseDuration(rawPeriod)
+	if err != nil || period <= 0 {
+		return Policy{}, fmt.Errorf("rate limit policy %q has invalid period", s)
+	}
+
+	return Policy{Limit: limit, Period: period}, nil
+}
+
+// MustParsePolicy is ParsePolicy for policies that have already been
+// validated, it panics on an invalid one.
+func MustParsePolicy(s string) Policy {
+	policy, err := ParsePolicy(s)
+	if err != nil {
+		panic(err)
+	}
+	return policy
+}
+
+func (p Policy) IsDisabled() bool {
+	return p.Limit <= 0
+}
+
+func (p Policy) String() string {
+	if p.IsDisabled() {
+		return "off"
+	}
+	return fmt.Sprintf("%d/%s", p.Limit, p.Period)
+}
@@ -0,0 +1,87 @@
+package ratex
+
+import (
+	"sync"
+	"time"
+)
+
+// Store keeps the request budget of every client. MemoryStore is enough for
+// a single instance, a shared store is needed once the API is scaled out.
+type Store interface {
+	// Allow takes one request from the budget of the key and reports whether
+	// it was available. If not, retryAfter tells when the next one will be.
+	Allow(key string, policy Policy) (allowed bool, retryAfter time.Duration, err error)
+}
+
+const memoryStoreCleanupInterval = time.Minute
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	period    time.Duration
+}
+
+// MemoryStore is a token bucket store kept in the process memory.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	cleanedAt time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:   make(map[string]*bucket),
+		cleanedAt: time.Now(),
+	}
+}
+
+func (s *MemoryStore) Allow(key string, policy Policy) (bool, time.Duration, error) {
+	if policy.IsDisabled() {
+		return true, 0, nil
+	}
+
+	now := time.Now()
+	capacity := float64(policy.Limit)
+	tokensPerSecond := capacity / policy.Period.Seconds()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cleanup(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{
+			tokens:    capacity,
+			updatedAt: now,
+		}
+		s.buckets[key] = b
+	}
+	b.period = policy.Period
+
+	b.tokens = min(capacity, b.tokens+now.Sub(b.updatedAt).Seconds()*tokensPerSecond)
+	b.updatedAt = now
+
+	if b.tokens < 1 {
+		retryAfter := time.Duration((1 - b.tokens) / tokensPerSecond * float64(time.Second))
+		return false, retryAfter, nil
+	}
+
+	b.tokens--
+	return true, 0, nil
+}
+
+// cleanup drops buckets that have been idle long enough to refill completely,
+// they are no different from new ones.
+func (s *MemoryStore) cleanup(now time.Time) {
+	if now.Sub(s.cleanedAt) < memoryStoreCleanupInterval {
+		return
+	}
+	s.cleanedAt = now
+
+	for key, b := range s.buckets {
+		if now.Sub(b.updatedAt) > b.period {
+			delete(s.buckets, key)
+		}
+	}
+}
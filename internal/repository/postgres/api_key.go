@@ -0,0 +1,205 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/levchenki/tea-api/internal/entity"
+)
+
+type ApiKeyRepository struct {
+	db *sqlx.DB
+}
+
+func NewApiKeyRepository(db *sqlx.DB) *ApiKeyRepository {
+	return &ApiKeyRepository{
+		db: db,
+	}
+}
+
+const selectApiKeyQuery = `
+	select id,
+		   name,
+		   prefix,
+		   key_hash,
+		   created_by,
+		   created_at,
+		   expires_at,
+		   last_used_at,
+		   revoked_at
+	from api_keys`
+
+func (r *ApiKeyRepository) GetAll() ([]entity.ApiKey, error) {
+	apiKeys := make([]entity.ApiKey, 0)
+	err := r.db.Select(&apiKeys, selectApiKeyQuery+" order by created_at desc")
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.fillScopes(apiKeys)
+	if err != nil {
+		return nil, err
+	}
+	return apiKeys, nil
+}
+
+func (r *ApiKeyRepository) GetById(id uuid.UUID) (*entity.ApiKey, error) {
+	return r.getOne("id = $1", id)
+}
+
+func (r *ApiKeyRepository) GetByHash(keyHash string) (*entity.ApiKey, error) {
+	return r.getOne("key_hash = $1", keyHash)
+}
+
+func (r *ApiKeyRepository) getOne(whereClause string, arg interface{}) (*entity.ApiKey, error) {
+	apiKey := entity.ApiKey{}
+	err := r.db.Get(&apiKey, selectApiKeyQuery+" where "+whereClause, arg)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	apiKeys := []entity.ApiKey{apiKey}
+	err = r.fillScopes(apiKeys)
+	if err != nil {
+		return nil, err
+	}
+	return &apiKeys[0], nil
+}
+
+func (r *ApiKeyRepository) fillScopes(apiKeys []entity.ApiKey) error {
+	if len(apiKeys) == 0 {
+		return nil
+	}
+
+	apiKeyIds := make([]uuid.UUID, len(apiKeys))
+	for i := range apiKeys {
+		apiKeyIds[i] = apiKeys[i].Id
+		apiKeys[i].Scopes = make([]string, 0)
+	}
+
+	query, args, err := sqlx.In(`
+		select api_key_id, scope
+		from api_keys_scopes
+		where api_key_id in (?)
+		order by scope`, apiKeyIds)
+	if err != nil {
+		return err
+	}
+	query = r.db.Rebind(query)
+
+	rows, err := r.db.Queryx(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	scopesByApiKeyId := make(map[uuid.UUID][]string)
+	for rows.Next() {
+		var (
+			apiKeyId uuid.UUID
+			scope    string
+		)
+		err = rows.Scan(&apiKeyId, &scope)
+		if err != nil {
+			return err
+		}
+		scopesByApiKeyId[apiKeyId] = append(scopesByApiKeyId[apiKeyId], scope)
+	}
+
+	for i := range apiKeys {
+		if scopes, ok := scopesByApiKeyId[apiKeys[i].Id]; ok {
+			apiKeys[i].Scopes = scopes
+		}
+	}
+	return nil
+}
+
+func (r *ApiKeyRepository) Create(apiKey *entity.ApiKey) (*entity.ApiKey, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	var id uuid.UUID
+	err = tx.Get(&id, `
+		insert into api_keys (name, prefix, key_hash, created_by, expires_at)
+		values ($1, $2, $3, $4, $5)
+		returning id`,
+		apiKey.Name, apiKey.Prefix, apiKey.KeyHash, apiKey.CreatedBy, apiKey.ExpiresAt)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return nil, errRollback
+		}
+		return nil, err
+	}
+
+	apiKeyScopes := make([]map[string]interface{}, 0, len(apiKey.Scopes))
+	for _, scope := range apiKey.Scopes {
+		apiKeyScopes = append(apiKeyScopes, map[string]interface{}{
+			"api_key_id": id.String(),
+			"scope":      scope,
+		})
+	}
+
+	_, err = tx.NamedExec(`
+		insert into api_keys_scopes (api_key_id, scope)
+		values (:api_key_id, :scope)`, apiKeyScopes)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return nil, errRollback
+		}
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return r.GetById(id)
+}
+
+func (r *ApiKeyRepository) Revoke(id uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		update api_keys
+		set revoked_at = now()
+		where id = $1
+		  and revoked_at is null`, id)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Touch updates last_used_at at most once a minute, so a busy integration
+// does not write on every request.
+func (r *ApiKeyRepository) Touch(id uuid.UUID) error {
+	_, err := r.db.Exec(`
+		update api_keys
+		set last_used_at = now()
+		where id = $1
+		  and (last_used_at is null or last_used_at < now() - interval '1 minute')`, id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/levchenki/tea-api/internal/entity"
+)
+
+type CartRepository struct {
+	db *sqlx.DB
+}
+
+func NewCartRepository(db *sqlx.DB) *CartRepository {
+	return &CartRepository{
+		db: db,
+	}
+}
+
+// selectCartItemQuery loads the teas with the values of the location of the
+// cart, see teaColumn.
+const selectCartItemQuery = `
+	select ci.id,
+		   ci.user_id,
+		   ci.location_id,
+		   ci.tea_id,
+		   t.name                                                as tea_name,
+		   t.category_id,
+		   ci.kind,
+		   ci.quantity,
+		   ci.price,
+		   case
+			   when ci.kind = 'weight' then coalesce(tlo.unit_price, t.unit_price)
+			   else coalesce(tlo.serve_price, t.serve_price) end as current_price,
+		   coalesce(tlo.is_hidden, t.is_hidden)                  as is_hidden,
+		   coalesce(tlo.stock, t.stock)                          as stock,
+		   ci.created_at,
+		   ci.updated_at
+	from cart_items ci
+			 join teas t on t.id = ci.tea_id
+			 left join tea_location_overrides tlo on tlo.tea_id = ci.tea_id and tlo.location_id = ci.location_id`
+
+func (r *CartRepository) GetAllByUserId(userId uuid.UUID) ([]entity.CartItem, error) {
+	items := make([]entity.CartItem, 0)
+	err := r.db.Select(&items, selectCartItemQuery+`
+		where ci.user_id = $1
+		order by ci.created_at`, userId)
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *CartRepository) GetById(userId, id uuid.UUID) (*entity.CartItem, error) {
+	item := &entity.CartItem{}
+	err := r.db.Get(item, selectCartItemQuery+`
+		where ci.user_id = $1
+		  and ci.id = $2`, userId, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return item, nil
+}
+
+// Save puts the tea into the cart or replaces the quantity and the price if
+// the cart already has it in the same kind.
+func (r *CartRepository) Save(item *entity.CartItem) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.NamedExec(`
+		insert into cart_items (user_id, location_id, tea_id, kind, quantity, price)
+		values (:user_id, :location_id, :tea_id, :kind, :quantity, :price)
+		on conflict (user_id, tea_id, kind) do update
+			set quantity   = excluded.quantity,
+				price      = excluded.price,
+				updated_at = now()`, item)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// RefreshPrices replaces the prices in the cart of the user with the current
+// prices of the teas in the location of the cart.
+func (r *CartRepository) RefreshPrices(userId uuid.UUID) error {
+	_, err := r.db.Exec(`
+		update cart_items ci
+		set price      = (select case
+									 when ci.kind = 'weight' then coalesce(tlo.unit_price, t.unit_price)
+									 else coalesce(tlo.serve_price, t.serve_price) end
+						  from teas t
+								   left join tea_location_overrides tlo
+											 on tlo.tea_id = t.id and tlo.location_id = ci.location_id
+						  where t.id = ci.tea_id),
+			updated_at = now()
+		where ci.user_id = $1`, userId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *CartRepository) Delete(userId, id uuid.UUID) error {
+	_, err := r.db.Exec("delete from cart_items where user_id = $1 and id = $2", userId, id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *CartRepository) Clear(userId uuid.UUID) error {
+	_, err := r.db.Exec("delete from cart_items where user_id = $1", userId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,332 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/schemas/discountSchemas"
+	"strings"
+	"time"
+)
+
+type DiscountRepository struct {
+	db *sqlx.DB
+}
+
+func NewDiscountRepository(db *sqlx.DB) *DiscountRepository {
+	return &DiscountRepository{
+		db: db,
+	}
+}
+
+const selectDiscountQuery = `
+	select d.id,
+		   d.name,
+		   coalesce(d.code, '')                                                       as code,
+		   d.kind,
+		   d.value,
+		   d.scope,
+		   d.target_id,
+		   d.min_order_amount,
+		   d.starts_at,
+		   d.ends_at,
+		   d.usage_limit,
+		   d.per_user_limit,
+		   d.is_active,
+		   d.deleted_at,
+		   (select count(*) from discount_redemptions dr where dr.discount_id = d.id) as usage_count,
+		   d.created_at,
+		   d.updated_at
+	from discounts d`
+
+func (r *DiscountRepository) GetAll() ([]entity.Discount, error) {
+	discounts := make([]entity.Discount, 0)
+	err := r.db.Select(&discounts, selectDiscountQuery+`
+		where d.deleted_at is null
+		order by d.created_at desc`)
+	if err != nil {
+		return nil, err
+	}
+	return discounts, nil
+}
+
+// GetById returns deleted discounts too, their redemptions are still
+// reported.
+func (r *DiscountRepository) GetById(id uuid.UUID) (*entity.Discount, error) {
+	discount := entity.Discount{}
+	err := r.db.Get(&discount, selectDiscountQuery+" where d.id = $1", id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &discount, nil
+}
+
+func (r *DiscountRepository) GetByCode(code string) (*entity.Discount, error) {
+	discount := entity.Discount{}
+	err := r.db.Get(&discount, selectDiscountQuery+`
+		where lower(d.code) = lower($1)
+		  and d.deleted_at is null`, code)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &discount, nil
+}
+
+func (r *DiscountRepository) ExistsByCode(existedId uuid.UUID, code string) (bool, error) {
+	var exists bool
+	err := r.db.Get(&exists, `
+		select exists(select 1
+					  from discounts
+					  where id != $1
+						and lower(code) = lower($2)
+						and deleted_at is null)`, existedId, code)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+// GetAutomatic returns the active automatic rules that are within their
+// validity period at the time.
+func (r *DiscountRepository) GetAutomatic(at time.Time) ([]entity.Discount, error) {
+	discounts := make([]entity.Discount, 0)
+	err := r.db.Select(&discounts, selectDiscountQuery+`
+		where d.code is null
+		  and d.is_active
+		  and d.deleted_at is null
+		  and (d.starts_at is null or d.starts_at <= $1)
+		  and (d.ends_at is null or d.ends_at > $1)
+		order by d.created_at`, at)
+	if err != nil {
+		return nil, err
+	}
+	return discounts, nil
+}
+
+func (r *DiscountRepository) Create(discount *entity.Discount) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := r.db.Get(&id, `
+		insert into discounts (name, code, kind, value, scope, target_id, min_order_amount,
+							   starts_at, ends_at, usage_limit, per_user_limit, is_active)
+		values ($1, nullif($2, ''), $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		returning id`,
+		discount.Name, discount.Code, discount.Kind, discount.Value, discount.Scope, discount.TargetId,
+		discount.MinOrderAmount, discount.StartsAt, discount.EndsAt, discount.UsageLimit, discount.PerUserLimit,
+		discount.IsActive)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return id, nil
+}
+
+func (r *DiscountRepository) Update(discount *entity.Discount) error {
+	_, err := r.db.Exec(`
+		update discounts
+		set name             = $1,
+			code             = nullif($2, ''),
+			kind             = $3,
+			value            = $4,
+			scope            = $5,
+			target_id        = $6,
+			min_order_amount = $7,
+			starts_at        = $8,
+			ends_at          = $9,
+			usage_limit      = $10,
+			per_user_limit   = $11,
+			is_active        = $12,
+			updated_at       = now()
+		where id = $13`,
+		discount.Name, discount.Code, discount.Kind, discount.Value, discount.Scope, discount.TargetId,
+		discount.MinOrderAmount, discount.StartsAt, discount.EndsAt, discount.UsageLimit, discount.PerUserLimit,
+		discount.IsActive, discount.Id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Delete marks the discount deleted and takes it out of the carts. Its
+// redemptions stay, so the report keeps matching the discounted orders.
+func (r *DiscountRepository) Delete(id uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		update discounts
+		set is_active  = false,
+			deleted_at = now(),
+			updated_at = now()
+		where id = $1`, id)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	_, err = tx.Exec("delete from cart_promo_codes where discount_id = $1", id)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetUsage counts the redemptions of the discounts, in total and by the
+// user.
+func (r *DiscountRepository) GetUsage(ids []uuid.UUID, userId uuid.UUID) (map[uuid.UUID]entity.DiscountUsage, error) {
+	result := make(map[uuid.UUID]entity.DiscountUsage, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	query, args, err := sqlx.In(`
+		select discount_id,
+			   count(*)                              as total,
+			   count(*) filter ( where user_id = ? ) as by_user
+		from discount_redemptions
+		where discount_id in (?)
+		group by discount_id`, userId, ids)
+	if err != nil {
+		return nil, err
+	}
+	query = r.db.Rebind(query)
+
+	usages := make([]entity.DiscountUsage, 0)
+	err = r.db.Select(&usages, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, usage := range usages {
+		result[usage.DiscountId] = usage
+	}
+	return result, nil
+}
+
+// GetCartPromoCode returns the promo code the user has entered for the cart.
+func (r *DiscountRepository) GetCartPromoCode(userId uuid.UUID) (*entity.Discount, error) {
+	discount := entity.Discount{}
+	err := r.db.Get(&discount, selectDiscountQuery+`
+		join cart_promo_codes cpc on cpc.discount_id = d.id
+		where cpc.user_id = $1
+		  and d.deleted_at is null`, userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &discount, nil
+}
+
+func (r *DiscountRepository) SetCartPromoCode(userId, discountId uuid.UUID) error {
+	_, err := r.db.Exec(`
+		insert into cart_promo_codes (user_id, discount_id)
+		values ($1, $2)
+		on conflict (user_id) do update set discount_id = excluded.discount_id,
+											created_at  = now()`, userId, discountId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *DiscountRepository) DeleteCartPromoCode(userId uuid.UUID) error {
+	_, err := r.db.Exec("delete from cart_promo_codes where user_id = $1", userId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetReport sums up the redemptions of every discount made in the period,
+// the discounts without redemptions are included with zeroes unless they are
+// deleted.
+func (r *DiscountRepository) GetReport(filters *discountSchemas.ReportFilters) ([]entity.DiscountReport, error) {
+	conditions := make([]string, 0, 2)
+	args := make([]interface{}, 0, 2)
+	if filters.From != nil {
+		conditions = append(conditions, "dr.created_at >= ?")
+		args = append(args, *filters.From)
+	}
+	if filters.To != nil {
+		conditions = append(conditions, "dr.created_at < ?")
+		args = append(args, *filters.To)
+	}
+
+	joinCondition := "dr.discount_id = d.id"
+	if len(conditions) > 0 {
+		joinCondition += " and " + strings.Join(conditions, " and ")
+	}
+
+	query := r.db.Rebind(`
+		select d.id                        as discount_id,
+			   d.name,
+			   coalesce(d.code, '')        as code,
+			   count(dr.id)                as redemptions,
+			   coalesce(sum(dr.amount), 0) as amount
+		from discounts d
+				 left join discount_redemptions dr on ` + joinCondition + `
+		group by d.id
+		having d.deleted_at is null
+			or count(dr.id) > 0
+		order by amount desc, d.name`)
+
+	reports := make([]entity.DiscountReport, 0)
+	err := r.db.Select(&reports, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+func (r *DiscountRepository) GetRedemptions(discountId uuid.UUID, filters *discountSchemas.RedemptionFilters) ([]entity.DiscountRedemption, uint64, error) {
+	filters.Offset = filters.Limit * (filters.Page - 1)
+
+	redemptions := make([]entity.DiscountRedemption, 0)
+	err := r.db.Select(&redemptions, `
+		select dr.id,
+			   dr.discount_id,
+			   dr.order_id,
+			   o.number                                        as order_number,
+			   dr.user_id,
+			   trim(concat_ws(' ', u.first_name, u.last_name)) as customer_name,
+			   dr.amount,
+			   dr.created_at
+		from discount_redemptions dr
+				 join orders o on o.id = dr.order_id
+				 left join users u on u.id = dr.user_id
+		where dr.discount_id = $1
+		order by dr.created_at desc
+		limit $2 offset $3`, discountId, filters.Limit, filters.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total uint64
+	err = r.db.Get(&total, "select count(*) from discount_redemptions where discount_id = $1", discountId)
+	if err != nil {
+		return nil, 0, err
+	}
+	return redemptions, total, nil
+}
@@ -0,0 +1,441 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/schemas/eventSchemas"
+	"strings"
+)
+
+type EventRepository struct {
+	db *sqlx.DB
+}
+
+func NewEventRepository(db *sqlx.DB) *EventRepository {
+	return &EventRepository{
+		db: db,
+	}
+}
+
+// selectEventQuery takes the id of the user to load the registration status
+// for as the only parameter.
+const selectEventQuery = `
+	select e.id,
+		   e.title,
+		   coalesce(e.description, '') as description,
+		   e.starts_at,
+		   e.ends_at,
+		   e.capacity,
+		   e.price,
+		   e.location_id,
+		   coalesce(l.name, '')        as location_name,
+		   coalesce(l.address, '')     as location_address,
+		   c.registered_count,
+		   c.waitlisted_count,
+		   coalesce(er.status, '')     as registration_status,
+		   e.created_at,
+		   e.updated_at
+	from events e
+			 left join locations l on l.id = e.location_id
+			 left join event_registrations er on er.event_id = e.id and er.user_id = ?
+			 left join lateral (select count(*) filter ( where status = 'registered' ) as registered_count,
+									   count(*) filter ( where status = 'waitlisted' ) as waitlisted_count
+								from event_registrations
+								where event_id = e.id) c on true`
+
+func (r *EventRepository) GetAll(filters *eventSchemas.Filters) ([]entity.Event, uint64, error) {
+	filters.Offset = filters.Limit * (filters.Page - 1)
+
+	conditions := make([]string, 0, 2)
+	args := make([]interface{}, 0, 1)
+	if filters.IsPast {
+		conditions = append(conditions, "e.ends_at <= now()")
+	} else {
+		conditions = append(conditions, "e.ends_at > now()")
+	}
+	if filters.LocationId != uuid.Nil {
+		conditions = append(conditions, "e.location_id = ?")
+		args = append(args, filters.LocationId)
+	}
+	whereClause := " where " + strings.Join(conditions, " and ")
+
+	// Upcoming events go from the nearest, past ones from the latest.
+	orderClause := " order by e.starts_at, e.title"
+	if filters.IsPast {
+		orderClause = " order by e.starts_at desc, e.title"
+	}
+
+	selectArgs := append([]interface{}{filters.UserId}, args...)
+	selectArgs = append(selectArgs, filters.Limit, filters.Offset)
+	events := make([]entity.Event, 0)
+	err := r.db.Select(&events, r.db.Rebind(selectEventQuery+whereClause+orderClause+" limit ? offset ?"), selectArgs...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total uint64
+	err = r.db.Get(&total, r.db.Rebind("select count(*) from events e"+whereClause), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = r.fillTeas(events)
+	if err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
+
+func (r *EventRepository) GetById(id, userId uuid.UUID) (*entity.Event, error) {
+	event := entity.Event{}
+	err := r.db.Get(&event, r.db.Rebind(selectEventQuery+" where e.id = ?"), userId, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	events := []entity.Event{event}
+	err = r.fillTeas(events)
+	if err != nil {
+		return nil, err
+	}
+	return &events[0], nil
+}
+
+func (r *EventRepository) Create(event *entity.Event, teaIds []uuid.UUID) (uuid.UUID, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	var id uuid.UUID
+	err = tx.Get(&id, `
+		insert into events (title, description, starts_at, ends_at, capacity, price, location_id)
+		values ($1, nullif($2, ''), $3, $4, $5, $6, $7)
+		returning id`,
+		event.Title, event.Description, event.StartsAt, event.EndsAt, event.Capacity, event.Price, event.LocationId)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return uuid.Nil, errRollback
+		}
+		return uuid.Nil, err
+	}
+
+	err = r.insertTeas(tx, id, teaIds)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return id, nil
+}
+
+// Update replaces the event and its teas. If the capacity has grown, the
+// waitlisted users take the new places. Users registered over a reduced
+// capacity keep their places.
+func (r *EventRepository) Update(event *entity.Event, teaIds []uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		update events
+		set title       = $1,
+			description = nullif($2, ''),
+			starts_at   = $3,
+			ends_at     = $4,
+			capacity    = $5,
+			price       = $6,
+			location_id = $7,
+			updated_at  = now()
+		where id = $8`,
+		event.Title, event.Description, event.StartsAt, event.EndsAt, event.Capacity, event.Price, event.LocationId, event.Id)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	_, err = tx.Exec("delete from event_teas where event_id = $1", event.Id)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	err = r.insertTeas(tx, event.Id, teaIds)
+	if err != nil {
+		return err
+	}
+
+	err = r.promoteWaitlisted(tx, event.Id)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *EventRepository) Delete(id uuid.UUID) error {
+	_, err := r.db.Exec("delete from events where id = $1", id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Register signs the user up for the event, or puts the user on the waitlist
+// if the event is full, and returns the status of the registration. Signing
+// up again keeps the existing registration.
+func (r *EventRepository) Register(eventId, userId uuid.UUID) (string, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return "", err
+	}
+
+	// The lock on the event keeps concurrent sign-ups from overbooking it.
+	var capacity int
+	err = tx.Get(&capacity, "select capacity from events where id = $1 for update", eventId)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return "", errRollback
+		}
+		return "", err
+	}
+
+	var status string
+	err = tx.Get(&status, "select status from event_registrations where event_id = $1 and user_id = $2", eventId, userId)
+	if err == nil {
+		err = tx.Rollback()
+		if err != nil {
+			return "", err
+		}
+		return status, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return "", errRollback
+		}
+		return "", err
+	}
+
+	var registeredCount int
+	err = tx.Get(&registeredCount, `
+		select count(*)
+		from event_registrations
+		where event_id = $1
+		  and status = $2`, eventId, entity.EventRegistrationRegistered)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return "", errRollback
+		}
+		return "", err
+	}
+
+	status = entity.EventRegistrationRegistered
+	if registeredCount >= capacity {
+		status = entity.EventRegistrationWaitlisted
+	}
+
+	_, err = tx.Exec(`
+		insert into event_registrations (event_id, user_id, status)
+		values ($1, $2, $3)`, eventId, userId, status)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return "", errRollback
+		}
+		return "", err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return "", err
+	}
+	return status, nil
+}
+
+// Cancel removes the registration of the user and gives the freed place to
+// the first waitlisted user. It returns false if the user has not signed up.
+func (r *EventRepository) Cancel(eventId, userId uuid.UUID) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec("select 1 from events where id = $1 for update", eventId)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return false, errRollback
+		}
+		return false, err
+	}
+
+	result, err := tx.Exec("delete from event_registrations where event_id = $1 and user_id = $2", eventId, userId)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return false, errRollback
+		}
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return false, errRollback
+		}
+		return false, err
+	}
+	if rowsAffected == 0 {
+		err = tx.Rollback()
+		if err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+
+	err = r.promoteWaitlisted(tx, eventId)
+	if err != nil {
+		return false, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetAttendees returns the registered users in the order they signed up,
+// followed by the waitlist.
+func (r *EventRepository) GetAttendees(eventId uuid.UUID) ([]entity.EventAttendee, error) {
+	attendees := make([]entity.EventAttendee, 0)
+	err := r.db.Select(&attendees, `
+		select er.user_id,
+			   u.first_name,
+			   coalesce(u.last_name, '') as last_name,
+			   coalesce(u.username, '')  as username,
+			   er.status,
+			   er.created_at
+		from event_registrations er
+				 join users u on u.id = er.user_id
+		where er.event_id = $1
+		order by er.status = 'waitlisted', er.created_at`, eventId)
+	if err != nil {
+		return nil, err
+	}
+	return attendees, nil
+}
+
+// promoteWaitlisted moves as many waitlisted users as there are free places
+// to the registered ones, the earliest sign-ups first.
+func (r *EventRepository) promoteWaitlisted(tx *sqlx.Tx, eventId uuid.UUID) error {
+	_, err := tx.Exec(`
+		update event_registrations er
+		set status     = 'registered',
+			updated_at = now()
+		from (select user_id
+			  from event_registrations
+			  where event_id = $1
+				and status = 'waitlisted'
+			  order by created_at
+			  limit greatest((select capacity from events where id = $1) -
+							 (select count(*)
+							  from event_registrations
+							  where event_id = $1
+								and status = 'registered'), 0)) w
+		where er.event_id = $1
+		  and er.user_id = w.user_id`, eventId)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *EventRepository) insertTeas(tx *sqlx.Tx, eventId uuid.UUID, teaIds []uuid.UUID) error {
+	for i, teaId := range teaIds {
+		_, err := tx.Exec(`
+			insert into event_teas (event_id, tea_id, position)
+			values ($1, $2, $3)`, eventId, teaId, i+1)
+		if err != nil {
+			errRollback := tx.Rollback()
+			if errRollback != nil {
+				return errRollback
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *EventRepository) fillTeas(events []entity.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	eventIds := make([]uuid.UUID, len(events))
+	for i := range events {
+		eventIds[i] = events[i].Id
+		events[i].Teas = make([]entity.EventTea, 0)
+	}
+
+	query, args, err := sqlx.In(`
+		select et.event_id,
+			   et.tea_id,
+			   t.name as tea_name,
+			   et.position
+		from event_teas et
+				 join teas t on t.id = et.tea_id
+		where et.event_id in (?)
+		order by et.position`, eventIds)
+	if err != nil {
+		return err
+	}
+	query = r.db.Rebind(query)
+
+	teas := make([]entity.EventTea, 0)
+	err = r.db.Select(&teas, query, args...)
+	if err != nil {
+		return err
+	}
+
+	teasByEventId := make(map[uuid.UUID][]entity.EventTea)
+	for _, tea := range teas {
+		teasByEventId[tea.EventId] = append(teasByEventId[tea.EventId], tea)
+	}
+
+	for i := range events {
+		if eventTeas, ok := teasByEventId[events[i].Id]; ok {
+			events[i].Teas = eventTeas
+		}
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/levchenki/tea-api/internal/entity"
+	"time"
+)
+
+type GuestRepository struct {
+	db *sqlx.DB
+}
+
+func NewGuestRepository(db *sqlx.DB) *GuestRepository {
+	return &GuestRepository{
+		db: db,
+	}
+}
+
+// Create deletes guests that have not been seen for longer than ttl, their
+// tokens are expired anyway, and adds a new one.
+func (r *GuestRepository) Create(ttl time.Duration) (*entity.Guest, error) {
+	_, err := r.db.Exec(`
+		delete
+		from guests
+		where last_used_at < $1`, time.Now().Add(-ttl))
+	if err != nil {
+		return nil, err
+	}
+
+	guest := &entity.Guest{}
+	err = r.db.Get(guest, `
+		insert into guests default values
+		returning id, created_at, last_used_at`)
+	if err != nil {
+		return nil, err
+	}
+	return guest, nil
+}
+
+func (r *GuestRepository) GetById(id uuid.UUID) (*entity.Guest, error) {
+	guest := &entity.Guest{}
+	err := r.db.Get(guest, `
+		select id, created_at, last_used_at
+		from guests
+		where id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return guest, nil
+}
+
+// Touch updates last_used_at at most once a minute.
+func (r *GuestRepository) Touch(id uuid.UUID) error {
+	_, err := r.db.Exec(`
+		update guests
+		set last_used_at = now()
+		where id = $1
+		  and last_used_at < now() - interval '1 minute'`, id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *GuestRepository) GetMarks(guestId uuid.UUID) ([]entity.GuestTeaMark, error) {
+	marks := make([]entity.GuestTeaMark, 0)
+	err := r.db.Select(&marks, `
+		select gtm.tea_id,
+			   t.name as tea_name,
+			   gtm.mark,
+			   gtm.created_at
+		from guest_tea_marks gtm
+				 join teas t on t.id = gtm.tea_id
+		where gtm.guest_id = $1
+		order by gtm.created_at`, guestId)
+	if err != nil {
+		return nil, err
+	}
+	return marks, nil
+}
+
+func (r *GuestRepository) SetMark(guestId, teaId uuid.UUID, mark string) error {
+	_, err := r.db.Exec(`
+		insert into guest_tea_marks (guest_id, tea_id, mark)
+		values ($1, $2, $3)
+		on conflict do nothing`, guestId, teaId, mark)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *GuestRepository) RemoveMark(guestId, teaId uuid.UUID, mark string) error {
+	_, err := r.db.Exec(`
+		delete
+		from guest_tea_marks
+		where guest_id = $1
+		  and tea_id = $2
+		  and mark = $3`, guestId, teaId, mark)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// MergeIntoUser appends the marks of the guest to the favourites and the
+// "want to try" lists of the user and deletes the guest. Lists are only
+// created for the marks the guest actually has.
+func (r *GuestRepository) MergeIntoUser(guestId, userId uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	marks := make([]string, 0)
+	err = tx.Select(&marks, `
+		select distinct mark
+		from guest_tea_marks
+		where guest_id = $1`, guestId)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	for _, mark := range marks {
+		var listId uuid.UUID
+		switch mark {
+		case entity.GuestMarkFavourite:
+			listId, err = r.ensureList(tx, userId, entity.FavouriteListName, true)
+		case entity.GuestMarkWantToTry:
+			listId, err = r.ensureList(tx, userId, entity.WantToTryListName, false)
+		default:
+			continue
+		}
+		if err != nil {
+			errRollback := tx.Rollback()
+			if errRollback != nil {
+				return errRollback
+			}
+			return err
+		}
+
+		_, err = tx.Exec(`
+			insert into tea_list_items (list_id, tea_id, position, created_at)
+			select $1,
+				   gtm.tea_id,
+				   coalesce((select max(position) from tea_list_items where list_id = $1), 0)
+					   + row_number() over (order by gtm.created_at),
+				   gtm.created_at
+			from guest_tea_marks gtm
+			where gtm.guest_id = $2
+			  and gtm.mark = $3
+			  and not exists(select 1
+							 from tea_list_items tli
+							 where tli.list_id = $1
+							   and tli.tea_id = gtm.tea_id)
+			on conflict do nothing`, listId, guestId, mark)
+		if err != nil {
+			errRollback := tx.Rollback()
+			if errRollback != nil {
+				return errRollback
+			}
+			return err
+		}
+	}
+
+	_, err = tx.Exec("delete from guests where id = $1", guestId)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *GuestRepository) ensureList(tx *sqlx.Tx, userId uuid.UUID, name string, isFavourite bool) (uuid.UUID, error) {
+	// List names are unique per user regardless of the case, see ListService.
+	_, err := tx.Exec(`
+		insert into tea_lists (user_id, name, is_favourite)
+		select $1, $2, $3
+		where not exists(select 1
+						 from tea_lists
+						 where user_id = $1
+						   and (is_favourite and $3 or not $3 and lower(name) = lower($2)))
+		on conflict do nothing`, userId, name, isFavourite)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	var listId uuid.UUID
+	if isFavourite {
+		err = tx.Get(&listId, "select id from tea_lists where user_id = $1 and is_favourite", userId)
+	} else {
+		err = tx.Get(&listId, `
+			select id
+			from tea_lists
+			where user_id = $1
+			  and lower(name) = lower($2)
+			order by is_favourite, created_at
+			limit 1`, userId, name)
+	}
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return listId, nil
+}
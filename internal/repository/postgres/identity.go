@@ -0,0 +1,277 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/levchenki/tea-api/internal/entity"
+	"time"
+)
+
+type IdentityRepository struct {
+	db *sqlx.DB
+}
+
+func NewIdentityRepository(db *sqlx.DB) *IdentityRepository {
+	return &IdentityRepository{
+		db: db,
+	}
+}
+
+const selectIdentityQuery = `
+	select id,
+		   user_id,
+		   provider,
+		   subject,
+		   coalesce(email, '') as email,
+		   created_at,
+		   last_used_at
+	from identities`
+
+func (r *IdentityRepository) GetById(id uuid.UUID) (*entity.Identity, error) {
+	identity := &entity.Identity{}
+	err := r.db.Get(identity, selectIdentityQuery+" where id = $1", id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return identity, nil
+}
+
+func (r *IdentityRepository) GetByProviderSubject(provider, subject string) (*entity.Identity, error) {
+	identity := &entity.Identity{}
+	err := r.db.Get(identity, selectIdentityQuery+`
+		where provider = $1
+		  and subject = $2`, provider, subject)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return identity, nil
+}
+
+func (r *IdentityRepository) GetAllByUserId(userId uuid.UUID) ([]entity.Identity, error) {
+	identities := make([]entity.Identity, 0)
+	err := r.db.Select(&identities, selectIdentityQuery+`
+		where user_id = $1
+		order by created_at`, userId)
+	if err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+func (r *IdentityRepository) Create(identity *entity.Identity) (*entity.Identity, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	var id uuid.UUID
+	err = tx.Get(&id, `
+		insert into identities (user_id, provider, subject, email)
+		values ($1, $2, $3, nullif($4, ''))
+		returning id`,
+		identity.UserId, identity.Provider, identity.Subject, identity.Email)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return nil, errRollback
+		}
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return r.GetById(id)
+}
+
+// Touch marks the identity as used and refreshes the email, since the
+// provider may have changed it since the last sign in.
+func (r *IdentityRepository) Touch(id uuid.UUID, email string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		update identities
+		set email        = coalesce(nullif($1, ''), email),
+			last_used_at = now()
+		where id = $2`, email, id)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *IdentityRepository) Delete(id uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("delete from identities where id = $1", id)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *IdentityRepository) CreateEmailLoginToken(tokenHash, email string, expiresAt time.Time) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("delete from email_login_tokens where expires_at < now()")
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	_, err = tx.Exec(`
+		insert into email_login_tokens (token_hash, email, expires_at)
+		values ($1, $2, $3)`, tokenHash, email, expiresAt)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ConsumeEmailLoginToken marks the token as used and returns its email. It
+// returns an empty string if the token is unknown, expired or already used.
+func (r *IdentityRepository) ConsumeEmailLoginToken(tokenHash string) (string, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return "", err
+	}
+
+	var email string
+	err = tx.Get(&email, `
+		update email_login_tokens
+		set used_at = now()
+		where token_hash = $1
+		  and used_at is null
+		  and expires_at > now()
+		returning email`, tokenHash)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return "", errRollback
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return "", err
+	}
+	return email, nil
+}
+
+func (r *IdentityRepository) CreateOIDCLoginState(state *entity.OIDCLoginState) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("delete from oidc_login_states where expires_at < now()")
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	_, err = tx.NamedExec(`
+		insert into oidc_login_states (state, nonce, code_verifier, expires_at)
+		values (:state, :nonce, :code_verifier, :expires_at)`, state)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ConsumeOIDCLoginState deletes the state and returns it, so every state can
+// be used once. It returns nil if the state is unknown or expired.
+func (r *IdentityRepository) ConsumeOIDCLoginState(state string) (*entity.OIDCLoginState, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	loginState := &entity.OIDCLoginState{}
+	err = tx.Get(loginState, `
+		delete
+		from oidc_login_states
+		where state = $1
+		  and expires_at > now()
+		returning state, nonce, code_verifier, created_at, expires_at`, state)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return nil, errRollback
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return loginState, nil
+}
@@ -0,0 +1,408 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/levchenki/tea-api/internal/entity"
+)
+
+type ListRepository struct {
+	db *sqlx.DB
+}
+
+func NewListRepository(db *sqlx.DB) *ListRepository {
+	return &ListRepository{
+		db: db,
+	}
+}
+
+func (r *ListRepository) GetAllByUserId(userId uuid.UUID) ([]entity.TeaList, error) {
+	lists := make([]entity.TeaList, 0)
+	err := r.db.Select(&lists, `
+		select tl.id,
+			   tl.user_id,
+			   tl.name,
+			   tl.is_favourite,
+			   coalesce(tl.share_token, '')                                as share_token,
+			   (select count(*) from tea_list_items where list_id = tl.id) as items_count,
+			   tl.created_at,
+			   tl.updated_at
+		from tea_lists tl
+		where tl.user_id = $1
+		order by tl.is_favourite desc, tl.created_at`, userId)
+	if err != nil {
+		return nil, err
+	}
+	return lists, nil
+}
+
+func (r *ListRepository) GetById(id uuid.UUID) (*entity.TeaList, error) {
+	list := &entity.TeaList{}
+	err := r.db.Get(list, `
+		select tl.id,
+			   tl.user_id,
+			   tl.name,
+			   tl.is_favourite,
+			   coalesce(tl.share_token, '')                                as share_token,
+			   (select count(*) from tea_list_items where list_id = tl.id) as items_count,
+			   tl.created_at,
+			   tl.updated_at
+		from tea_lists tl
+		where tl.id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *ListRepository) GetByShareToken(token string) (*entity.TeaList, error) {
+	list := &entity.TeaList{}
+	err := r.db.Get(list, `
+		select tl.id,
+			   tl.user_id,
+			   tl.name,
+			   tl.is_favourite,
+			   coalesce(tl.share_token, '')                                as share_token,
+			   coalesce(u.first_name, '')                                  as owner_name,
+			   (select count(*) from tea_list_items where list_id = tl.id) as items_count,
+			   tl.created_at,
+			   tl.updated_at
+		from tea_lists tl
+				 join users u on u.id = tl.user_id
+		where tl.share_token = $1`, token)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *ListRepository) GetItems(listId uuid.UUID) ([]entity.TeaListItem, error) {
+	items := make([]entity.TeaListItem, 0)
+	err := r.db.Select(&items, `
+		select tli.tea_id,
+			   t.name                  as tea_name,
+			   tli.position,
+			   coalesce(tli.note, '') as note,
+			   tli.created_at
+		from tea_list_items tli
+				 join teas t on t.id = tli.tea_id
+		where tli.list_id = $1
+		order by tli.position, tli.created_at`, listId)
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *ListRepository) CreateFavourite(userId uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		insert into tea_lists (user_id, name, is_favourite)
+		values ($1, $2, true)
+		on conflict (user_id) where is_favourite do nothing`, userId, entity.FavouriteListName)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ListRepository) Create(list *entity.TeaList) (*entity.TeaList, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.NamedQuery(`
+		insert into tea_lists (user_id, name)
+		values (:user_id, :name)
+		returning id, user_id, name, is_favourite, coalesce(share_token, '') as share_token, created_at, updated_at`, list)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return nil, errRollback
+		}
+		return nil, err
+	}
+
+	createdList := &entity.TeaList{}
+	if rows.Next() {
+		err := rows.StructScan(createdList)
+		if err != nil {
+			rows.Close()
+			errRollback := tx.Rollback()
+			if errRollback != nil {
+				return nil, errRollback
+			}
+			return nil, err
+		}
+	}
+	rows.Close()
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return createdList, nil
+}
+
+func (r *ListRepository) Update(list *entity.TeaList) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		update tea_lists
+		set name       = $1,
+			updated_at = now()
+		where id = $2`, list.Name, list.Id)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ListRepository) Delete(id uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("delete from tea_list_items where list_id = $1", id)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	_, err = tx.Exec("delete from tea_lists where id = $1", id)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ListRepository) SetShareToken(id uuid.UUID, token string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		update tea_lists
+		set share_token = nullif($1, ''),
+			shared_at   = case when $1 = '' then null else now() end
+		where id = $2`, token, id)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ListRepository) ExistsByName(userId, existedId uuid.UUID, name string) (bool, error) {
+	var exists bool
+	err := r.db.Get(&exists,
+		"select exists(select 1 from tea_lists where user_id = $1 and id != $2 and lower(name) = lower($3))",
+		userId, existedId, name)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+func (r *ListRepository) ItemExists(listId, teaId uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.Get(&exists,
+		"select exists(select 1 from tea_list_items where list_id = $1 and tea_id = $2)", listId, teaId)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+func (r *ListRepository) AddItem(listId, teaId uuid.UUID, note string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		insert into tea_list_items (list_id, tea_id, position, note)
+		values ($1,
+				$2,
+				coalesce((select max(position) from tea_list_items where list_id = $1), 0) + 1,
+				nullif($3, ''))
+		on conflict (list_id, tea_id) do update
+			set note = coalesce(excluded.note, tea_list_items.note)`, listId, teaId, note)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	err = r.touch(tx, listId)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ListRepository) UpdateItemNote(listId, teaId uuid.UUID, note string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		update tea_list_items
+		set note = nullif($1, '')
+		where list_id = $2
+		  and tea_id = $3`, note, listId, teaId)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	err = r.touch(tx, listId)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ListRepository) RemoveItem(listId, teaId uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("delete from tea_list_items where list_id = $1 and tea_id = $2", listId, teaId)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	err = r.touch(tx, listId)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ListRepository) Reorder(listId uuid.UUID, teaIds []uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	for i, teaId := range teaIds {
+		_, err = tx.Exec(`
+			update tea_list_items
+			set position = $1
+			where list_id = $2
+			  and tea_id = $3`, i+1, listId, teaId)
+		if err != nil {
+			errRollback := tx.Rollback()
+			if errRollback != nil {
+				return errRollback
+			}
+			return err
+		}
+	}
+
+	err = r.touch(tx, listId)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ListRepository) touch(tx *sqlx.Tx, listId uuid.UUID) error {
+	_, err := tx.Exec("update tea_lists set updated_at = now() where id = $1", listId)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+	return nil
+}
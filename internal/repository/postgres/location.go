@@ -0,0 +1,211 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/levchenki/tea-api/internal/entity"
+)
+
+type LocationRepository struct {
+	db *sqlx.DB
+}
+
+func NewLocationRepository(db *sqlx.DB) *LocationRepository {
+	return &LocationRepository{
+		db: db,
+	}
+}
+
+const selectLocationQuery = `
+	select id,
+		   name,
+		   coalesce(address, '') as address,
+		   is_default,
+		   created_at,
+		   updated_at
+	from locations`
+
+func (r *LocationRepository) GetAll() ([]entity.Location, error) {
+	locations := make([]entity.Location, 0)
+	err := r.db.Select(&locations, selectLocationQuery+" order by is_default desc, name")
+	if err != nil {
+		return nil, err
+	}
+	return locations, nil
+}
+
+func (r *LocationRepository) GetById(id uuid.UUID) (*entity.Location, error) {
+	location := entity.Location{}
+	err := r.db.Get(&location, selectLocationQuery+" where id = $1", id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &location, nil
+}
+
+func (r *LocationRepository) GetDefault() (*entity.Location, error) {
+	location := entity.Location{}
+	err := r.db.Get(&location, selectLocationQuery+" where is_default")
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &location, nil
+}
+
+func (r *LocationRepository) Exists(id uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.Get(&exists, "select exists(select 1 from locations where id = $1)", id)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+func (r *LocationRepository) ExistsByName(existedId uuid.UUID, name string) (bool, error) {
+	var exists bool
+	err := r.db.Get(&exists, "select exists(select 1 from locations where lower(name) = lower($1) and id <> $2)", name, existedId)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+func (r *LocationRepository) Create(location *entity.Location) (*entity.Location, error) {
+	var id uuid.UUID
+	err := r.db.Get(&id, `
+		insert into locations (name, address)
+		values ($1, nullif($2, ''))
+		returning id`, location.Name, location.Address)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetById(id)
+}
+
+func (r *LocationRepository) Update(location *entity.Location) (*entity.Location, error) {
+	_, err := r.db.Exec(`
+		update locations
+		set name       = $1,
+			address    = nullif($2, ''),
+			updated_at = now()
+		where id = $3`, location.Name, location.Address, location.Id)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetById(location.Id)
+}
+
+func (r *LocationRepository) Delete(id uuid.UUID) error {
+	_, err := r.db.Exec("delete from locations where id = $1 and not is_default", id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *LocationRepository) GetOverride(teaId, locationId uuid.UUID) (*entity.TeaLocationOverride, error) {
+	override := entity.TeaLocationOverride{}
+	err := r.db.Get(&override, `
+		select tea_id,
+			   location_id,
+			   is_hidden,
+			   serve_price,
+			   unit_price,
+			   stock,
+			   updated_at
+		from tea_location_overrides
+		where tea_id = $1
+		  and location_id = $2`, teaId, locationId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &override, nil
+}
+
+func (r *LocationRepository) SetVisibility(teaId, locationId uuid.UUID, isHidden *bool) error {
+	return r.saveOverride(`
+		insert into tea_location_overrides (tea_id, location_id, is_hidden)
+		values ($1, $2, $3)
+		on conflict (tea_id, location_id) do update
+			set is_hidden  = excluded.is_hidden,
+				updated_at = now()`, teaId, locationId, isHidden)
+}
+
+func (r *LocationRepository) SetStock(teaId, locationId uuid.UUID, stock *float64) error {
+	return r.saveOverride(`
+		insert into tea_location_overrides (tea_id, location_id, stock)
+		values ($1, $2, $3)
+		on conflict (tea_id, location_id) do update
+			set stock      = excluded.stock,
+				updated_at = now()`, teaId, locationId, stock)
+}
+
+func (r *LocationRepository) SetPrices(teaId, locationId uuid.UUID, servePrice, unitPrice *float64) error {
+	return r.saveOverride(`
+		insert into tea_location_overrides (tea_id, location_id, serve_price, unit_price)
+		values ($1, $2, $3, $4)
+		on conflict (tea_id, location_id) do update
+			set serve_price = excluded.serve_price,
+				unit_price  = excluded.unit_price,
+				updated_at  = now()`, teaId, locationId, servePrice, unitPrice)
+}
+
+func (r *LocationRepository) DeleteOverride(teaId, locationId uuid.UUID) error {
+	_, err := r.db.Exec("delete from tea_location_overrides where tea_id = $1 and location_id = $2", teaId, locationId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// saveOverride runs the upsert and drops the override if it no longer
+// overrides anything.
+func (r *LocationRepository) saveOverride(query string, teaId, locationId uuid.UUID, args ...interface{}) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(query, append([]interface{}{teaId, locationId}, args...)...)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	_, err = tx.Exec(`
+		delete
+		from tea_location_overrides
+		where tea_id = $1
+		  and location_id = $2
+		  and is_hidden is null
+		  and serve_price is null
+		  and unit_price is null
+		  and stock is null`, teaId, locationId)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/schemas/loyaltySchemas"
+)
+
+type LoyaltyRepository struct {
+	db *sqlx.DB
+}
+
+func NewLoyaltyRepository(db *sqlx.DB) *LoyaltyRepository {
+	return &LoyaltyRepository{
+		db: db,
+	}
+}
+
+func (r *LoyaltyRepository) GetBalance(userId uuid.UUID) (int, error) {
+	var balance int
+	err := r.db.Get(&balance, "select coalesce(sum(points), 0) from loyalty_transactions where user_id = $1", userId)
+	if err != nil {
+		return 0, err
+	}
+	return balance, nil
+}
+
+func (r *LoyaltyRepository) GetHistory(userId uuid.UUID, filters *loyaltySchemas.Filters) ([]entity.LoyaltyTransaction, uint64, error) {
+	filters.Offset = filters.Limit * (filters.Page - 1)
+
+	transactions := make([]entity.LoyaltyTransaction, 0)
+	err := r.db.Select(&transactions, `
+		select lt.id,
+			   lt.user_id,
+			   lt.order_id,
+			   o.number                  as order_number,
+			   lt.kind,
+			   lt.points,
+			   coalesce(lt.reason, '') as reason,
+			   lt.created_by,
+			   lt.created_at
+		from loyalty_transactions lt
+				 left join orders o on o.id = lt.order_id
+		where lt.user_id = $1
+		order by lt.created_at desc
+		limit $2 offset $3`, userId, filters.Limit, filters.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total uint64
+	err = r.db.Get(&total, "select count(*) from loyalty_transactions where user_id = $1", userId)
+	if err != nil {
+		return nil, 0, err
+	}
+	return transactions, total, nil
+}
+
+// Adjust adds the points to the balance of the user, or takes them if they
+// are negative. It returns false if the balance would go below zero.
+func (r *LoyaltyRepository) Adjust(userId uuid.UUID, points int, reason string, createdBy uuid.UUID) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.Exec("select 1 from users where id = $1 for update", userId)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return false, errRollback
+		}
+		return false, err
+	}
+
+	result, err := tx.Exec(`
+		insert into loyalty_transactions (user_id, kind, points, reason, created_by)
+		select $1, $2, $3, $4, $5
+		where (select coalesce(sum(points), 0)
+			   from loyalty_transactions
+			   where user_id = $1) + $3 >= 0`,
+		userId, entity.LoyaltyKindAdjust, points, reason, createdBy)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return false, errRollback
+		}
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return false, errRollback
+		}
+		return false, err
+	}
+	if rowsAffected == 0 {
+		err = tx.Rollback()
+		if err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+const selectLoyaltyRuleQuery = `
+	select id                     as category_id,
+		   name                   as category_name,
+		   loyalty_serve_points   as serve_points,
+		   loyalty_amount_percent as amount_percent
+	from categories`
+
+func (r *LoyaltyRepository) GetRules() ([]entity.LoyaltyRule, error) {
+	rules := make([]entity.LoyaltyRule, 0)
+	err := r.db.Select(&rules, selectLoyaltyRuleQuery+" order by name")
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *LoyaltyRepository) GetRule(categoryId uuid.UUID) (*entity.LoyaltyRule, error) {
+	rule := entity.LoyaltyRule{}
+	err := r.db.Get(&rule, selectLoyaltyRuleQuery+" where id = $1", categoryId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *LoyaltyRepository) SetRule(rule *entity.LoyaltyRule) error {
+	_, err := r.db.Exec(`
+		update categories
+		set loyalty_serve_points   = $1,
+			loyalty_amount_percent = $2
+		where id = $3`, rule.ServePoints, rule.AmountPercent, rule.CategoryId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/levchenki/tea-api/internal/entity"
+	"time"
+)
+
+type NotificationRepository struct {
+	db *sqlx.DB
+}
+
+func NewNotificationRepository(db *sqlx.DB) *NotificationRepository {
+	return &NotificationRepository{
+		db: db,
+	}
+}
+
+// EnqueueOrderNotification queues the message for the user unless they have
+// turned order notifications off or have no Telegram account.
+func (r *NotificationRepository) EnqueueOrderNotification(userId uuid.UUID, text string) error {
+	_, err := r.db.Exec(`
+		insert into notifications (user_id, recipient, text)
+		select u.id, u.telegram_id::text, $2
+		from users u
+		where u.id = $1
+		  and u.notify_orders
+		  and not u.is_blocked
+		  and u.telegram_id is not null`, userId, text)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// EnqueueFavouriteNotifications queues the message for every user who has
+// the tea in favourites and has not turned favourite notifications off.
+func (r *NotificationRepository) EnqueueFavouriteNotifications(teaId uuid.UUID, text string) error {
+	_, err := r.db.Exec(`
+		insert into notifications (user_id, recipient, text)
+		select distinct u.id, u.telegram_id::text, $2
+		from tea_list_items tli
+				 join tea_lists tl on tl.id = tli.list_id
+				 join users u on u.id = tl.user_id
+		where tli.tea_id = $1
+		  and tl.is_favourite
+		  and u.notify_favourites
+		  and not u.is_blocked
+		  and u.telegram_id is not null`, teaId, text)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// ClaimDue takes the notifications whose attempt is due and counts the
+// attempt. The claimed notifications are not due again until the lease ends,
+// so a crashed worker does not lose them and parallel workers do not send
+// them twice.
+func (r *NotificationRepository) ClaimDue(limit int, lease time.Duration) ([]entity.Notification, error) {
+	notifications := make([]entity.Notification, 0)
+	err := r.db.Select(&notifications, `
+		update notifications
+		set attempts        = attempts + 1,
+			next_attempt_at = now() + $2 * interval '1 second'
+		where id in (select id
+					 from notifications
+					 where status = $3
+					   and next_attempt_at <= now()
+					 order by next_attempt_at
+					 limit $1 for update skip locked)
+		returning id,
+			user_id,
+			recipient,
+			text,
+			status,
+			attempts,
+			coalesce(last_error, '') as last_error,
+			next_attempt_at,
+			created_at`, limit, lease.Seconds(), entity.NotificationStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (r *NotificationRepository) Delete(id uuid.UUID) error {
+	_, err := r.db.Exec("delete from notifications where id = $1", id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *NotificationRepository) Reschedule(id uuid.UUID, delay time.Duration, lastError string) error {
+	_, err := r.db.Exec(`
+		update notifications
+		set next_attempt_at = now() + $1 * interval '1 second',
+			last_error      = $2
+		where id = $3`, delay.Seconds(), lastError, id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *NotificationRepository) MarkFailed(id uuid.UUID, lastError string) error {
+	_, err := r.db.Exec(`
+		update notifications
+		set status     = $1,
+			last_error = $2
+		where id = $3`, entity.NotificationStatusFailed, lastError, id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
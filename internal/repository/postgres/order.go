@@ -0,0 +1,627 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/schemas/orderSchemas"
+)
+
+type OrderRepository struct {
+	db *sqlx.DB
+}
+
+func NewOrderRepository(db *sqlx.DB) *OrderRepository {
+	return &OrderRepository{
+		db: db,
+	}
+}
+
+const selectOrderQuery = `
+	select o.id,
+		   o.number,
+		   o.user_id,
+		   trim(concat_ws(' ', u.first_name, u.last_name)) as customer_name,
+		   o.location_id,
+		   coalesce(l.name, '')                            as location_name,
+		   o.status,
+		   o.total,
+		   o.discount,
+		   o.redeemed_points,
+		   coalesce(o.comment, '') as comment,
+		   o.created_at,
+		   o.updated_at
+	from orders o
+			 left join users u on u.id = o.user_id
+			 left join locations l on l.id = o.location_id`
+
+func (r *OrderRepository) GetAllByUserId(userId uuid.UUID, filters *orderSchemas.Filters) ([]entity.Order, uint64, error) {
+	filters.Offset = filters.Limit * (filters.Page - 1)
+
+	orders := make([]entity.Order, 0)
+	err := r.db.Select(&orders, selectOrderQuery+`
+		where o.user_id = $1
+		order by o.created_at desc
+		limit $2 offset $3`, userId, filters.Limit, filters.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total uint64
+	err = r.db.Get(&total, "select count(*) from orders where user_id = $1", userId)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = r.fillItems(orders)
+	if err != nil {
+		return nil, 0, err
+	}
+	return orders, total, nil
+}
+
+func (r *OrderRepository) GetById(id uuid.UUID) (*entity.Order, error) {
+	order := entity.Order{}
+	err := r.db.Get(&order, selectOrderQuery+" where o.id = $1", id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	orders := []entity.Order{order}
+	err = r.fillItems(orders)
+	if err != nil {
+		return nil, err
+	}
+	return &orders[0], nil
+}
+
+// GetActive returns the orders waiting in the queue of the location, the
+// oldest first. The nil location id gives the queues of all locations.
+func (r *OrderRepository) GetActive(locationId uuid.UUID) ([]entity.Order, error) {
+	query := selectOrderQuery + " where o.status in (?)"
+	queryArgs := []interface{}{entity.ActiveOrderStatuses}
+	if locationId != uuid.Nil {
+		query += " and o.location_id = ?"
+		queryArgs = append(queryArgs, locationId)
+	}
+	query += " order by o.created_at"
+
+	query, args, err := sqlx.In(query, queryArgs...)
+	if err != nil {
+		return nil, err
+	}
+	query = r.db.Rebind(query)
+
+	orders := make([]entity.Order, 0)
+	err = r.db.Select(&orders, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.fillItems(orders)
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// SetStatus moves the order from one status to another. It returns false
+// without changing anything if the order is no longer in the expected
+// status. Weight items of a cancelled order go back to the stock of the teas
+// in the location of the order and the redeemed points to the customer, its
+// discounts are freed up. A served order earns points.
+func (r *OrderRepository) SetStatus(id uuid.UUID, from, to string) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, err
+	}
+
+	result, err := tx.Exec(`
+		update orders
+		set status     = $1,
+			updated_at = now()
+		where id = $2
+		  and status = $3`, to, id, from)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return false, errRollback
+		}
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return false, errRollback
+		}
+		return false, err
+	}
+	if rowsAffected == 0 {
+		err = tx.Rollback()
+		if err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+
+	if to == entity.OrderStatusCancelled {
+		err = r.restoreStock(tx, id)
+		if err != nil {
+			return false, err
+		}
+
+		err = r.refundLoyaltyPoints(tx, id)
+		if err != nil {
+			return false, err
+		}
+
+		// A cancelled order does not count against the usage limits.
+		_, err = tx.Exec("delete from discount_redemptions where order_id = $1", id)
+		if err != nil {
+			errRollback := tx.Rollback()
+			if errRollback != nil {
+				return false, errRollback
+			}
+			return false, err
+		}
+	}
+
+	if to == entity.OrderStatusServed {
+		err = r.earnLoyaltyPoints(tx, id)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// redeemLoyaltyPoints takes the points of the order from the balance of the
+// customer. It returns false if the balance is not enough.
+func (r *OrderRepository) redeemLoyaltyPoints(tx *sqlx.Tx, orderId uuid.UUID, order *entity.Order) (bool, error) {
+	// The lock on the user keeps concurrent orders from spending the same
+	// points twice.
+	_, err := tx.Exec("select 1 from users where id = $1 for update", order.UserId)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return false, errRollback
+		}
+		return false, err
+	}
+
+	result, err := tx.Exec(`
+		insert into loyalty_transactions (user_id, order_id, kind, points)
+		select $1, $2, $3, -$4::integer
+		where (select coalesce(sum(points), 0)
+			   from loyalty_transactions
+			   where user_id = $1) >= $4`,
+		order.UserId, orderId, entity.LoyaltyKindRedeem, order.RedeemedPoints)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return false, errRollback
+		}
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return false, errRollback
+		}
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+// redeemDiscount records the discount of the order. It returns false if the
+// discount is no longer active or valid, or its usage limits are reached.
+func (r *OrderRepository) redeemDiscount(tx *sqlx.Tx, orderId, userId uuid.UUID, discount *entity.AppliedDiscount) (bool, error) {
+	// The lock on the discount keeps concurrent orders from going over the
+	// usage limits.
+	_, err := tx.Exec("select 1 from discounts where id = $1 for update", discount.DiscountId)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return false, errRollback
+		}
+		return false, err
+	}
+
+	result, err := tx.Exec(`
+		insert into discount_redemptions (discount_id, order_id, user_id, amount)
+		select d.id, $2, $3, $4
+		from discounts d
+		where d.id = $1
+		  and d.is_active
+		  and d.deleted_at is null
+		  and (d.starts_at is null or d.starts_at <= now())
+		  and (d.ends_at is null or d.ends_at > now())
+		  and (d.usage_limit is null or
+			   (select count(*) from discount_redemptions where discount_id = d.id) < d.usage_limit)
+		  and (d.per_user_limit is null or
+			   (select count(*) from discount_redemptions where discount_id = d.id and user_id = $3) < d.per_user_limit)`,
+		discount.DiscountId, orderId, userId, discount.Amount)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return false, errRollback
+		}
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return false, errRollback
+		}
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+// restoreStock puts the weight items of the order back to the stock they were
+// taken from, see takeStock.
+func (r *OrderRepository) restoreStock(tx *sqlx.Tx, orderId uuid.UUID) error {
+	// The teas are locked in the same order as by Create to avoid deadlocks.
+	_, err := tx.Exec(`
+		select 1
+		from teas
+		where id in (select tea_id from order_items where order_id = $1 and kind = $2)
+		order by id
+		for update`, orderId, entity.OrderItemKindWeight)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	_, err = tx.Exec(`
+		update teas t
+		set stock      = t.stock + oi.quantity,
+			updated_at = now()
+		from (select tea_id, sum(quantity) as quantity
+			  from order_items
+			  where order_id = $1
+				and kind = $2
+				and tea_id is not null
+			  group by tea_id) oi
+		where t.id = oi.tea_id
+		  and t.stock is not null
+		  and not exists(select 1
+						 from tea_location_overrides tlo
+								  join orders o on o.location_id = tlo.location_id
+						 where o.id = $1
+						   and tlo.tea_id = t.id
+						   and tlo.stock is not null)`, orderId, entity.OrderItemKindWeight)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	_, err = tx.Exec(`
+		update tea_location_overrides tlo
+		set stock      = tlo.stock + oi.quantity,
+			updated_at = now()
+		from (select tea_id, sum(quantity) as quantity
+			  from order_items
+			  where order_id = $1
+				and kind = $2
+				and tea_id is not null
+			  group by tea_id) oi,
+			 orders o
+		where o.id = $1
+		  and tlo.location_id = o.location_id
+		  and tlo.tea_id = oi.tea_id
+		  and tlo.stock is not null`, orderId, entity.OrderItemKindWeight)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *OrderRepository) refundLoyaltyPoints(tx *sqlx.Tx, orderId uuid.UUID) error {
+	_, err := tx.Exec(`
+		insert into loyalty_transactions (user_id, order_id, kind, points)
+		select user_id, order_id, $2, -points
+		from loyalty_transactions
+		where order_id = $1
+		  and kind = $3
+		on conflict do nothing`, orderId, entity.LoyaltyKindRefund, entity.LoyaltyKindRedeem)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+	return nil
+}
+
+// earnLoyaltyPoints credits the customer with the points the order earns by
+// the rules of the categories of its teas. Serve stamps are whole, like on
+// the paper card, unless nothing was paid for the order at all. The amount
+// percent is earned on the part of the order paid with money only.
+func (r *OrderRepository) earnLoyaltyPoints(tx *sqlx.Tx, orderId uuid.UUID) error {
+	_, err := tx.Exec(`
+		insert into loyalty_transactions (user_id, order_id, kind, points)
+		select o.user_id, o.id, $2, (e.stamps + e.amount_points)::integer
+		from orders o
+				 cross join lateral (select case
+												when o.total > 0 then floor(coalesce(sum(oi.quantity * c.loyalty_serve_points)
+																					 filter ( where oi.kind = 'serve' ), 0))
+												else 0 end                                             as stamps,
+											floor(coalesce(sum(oi.amount * c.loyalty_amount_percent / 100), 0) *
+												  coalesce(o.total / nullif(o.total + o.discount, 0), 0)) as amount_points
+									 from order_items oi
+											  join teas t on t.id = oi.tea_id
+											  join categories c on c.id = t.category_id
+									 where oi.order_id = o.id) e
+		where o.id = $1
+		  and o.user_id is not null
+		  and e.stamps + e.amount_points > 0
+		on conflict do nothing`, orderId, entity.LoyaltyKindEarn)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *OrderRepository) fillItems(orders []entity.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	orderIds := make([]uuid.UUID, len(orders))
+	for i := range orders {
+		orderIds[i] = orders[i].Id
+		orders[i].Items = make([]entity.OrderItem, 0)
+	}
+
+	query, args, err := sqlx.In(`
+		select id,
+			   order_id,
+			   tea_id,
+			   tea_name,
+			   kind,
+			   quantity,
+			   price,
+			   amount
+		from order_items
+		where order_id in (?)
+		order by tea_name, kind`, orderIds)
+	if err != nil {
+		return err
+	}
+	query = r.db.Rebind(query)
+
+	items := make([]entity.OrderItem, 0)
+	err = r.db.Select(&items, query, args...)
+	if err != nil {
+		return err
+	}
+
+	itemsByOrderId := make(map[uuid.UUID][]entity.OrderItem)
+	for _, item := range items {
+		itemsByOrderId[item.OrderId] = append(itemsByOrderId[item.OrderId], item)
+	}
+
+	for i := range orders {
+		if orderItems, ok := itemsByOrderId[orders[i].Id]; ok {
+			orders[i].Items = orderItems
+		}
+	}
+	return nil
+}
+
+// Create places the order, takes the weight items from the stock of the teas
+// in the location of the order, redeems the loyalty points and the discounts and empties the cart of the
+// user. It returns false without placing anything if a tea has become hidden
+// or does not have enough stock left, if the user does not have the points
+// any more or if a discount can no longer be used.
+func (r *OrderRepository) Create(order *entity.Order) (uuid.UUID, bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return uuid.Nil, false, err
+	}
+
+	for _, item := range order.Items {
+		ok, err := r.takeStock(tx, *order.LocationId, &item)
+		if err != nil {
+			return uuid.Nil, false, err
+		}
+		if !ok {
+			err = tx.Rollback()
+			if err != nil {
+				return uuid.Nil, false, err
+			}
+			return uuid.Nil, false, nil
+		}
+	}
+
+	var id uuid.UUID
+	err = tx.Get(&id, `
+		insert into orders (user_id, location_id, status, total, discount, redeemed_points, comment)
+		values ($1, $2, $3, $4, $5, $6, nullif($7, ''))
+		returning id`,
+		order.UserId, order.LocationId, order.Status, order.Total, order.Discount, order.RedeemedPoints, order.Comment)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return uuid.Nil, false, errRollback
+		}
+		return uuid.Nil, false, err
+	}
+
+	orderItems := make([]map[string]interface{}, 0, len(order.Items))
+	for _, item := range order.Items {
+		orderItems = append(orderItems, map[string]interface{}{
+			"order_id": id.String(),
+			"tea_id":   item.TeaId,
+			"tea_name": item.TeaName,
+			"kind":     item.Kind,
+			"quantity": item.Quantity,
+			"price":    item.Price,
+			"amount":   item.Amount,
+		})
+	}
+
+	_, err = tx.NamedExec(`
+		insert into order_items (order_id, tea_id, tea_name, kind, quantity, price, amount)
+		values (:order_id, :tea_id, :tea_name, :kind, :quantity, :price, :amount)`, orderItems)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return uuid.Nil, false, errRollback
+		}
+		return uuid.Nil, false, err
+	}
+
+	if order.RedeemedPoints > 0 {
+		ok, err := r.redeemLoyaltyPoints(tx, id, order)
+		if err != nil {
+			return uuid.Nil, false, err
+		}
+		if !ok {
+			err = tx.Rollback()
+			if err != nil {
+				return uuid.Nil, false, err
+			}
+			return uuid.Nil, false, nil
+		}
+	}
+
+	for _, discount := range order.Discounts {
+		ok, err := r.redeemDiscount(tx, id, order.UserId, &discount)
+		if err != nil {
+			return uuid.Nil, false, err
+		}
+		if !ok {
+			err = tx.Rollback()
+			if err != nil {
+				return uuid.Nil, false, err
+			}
+			return uuid.Nil, false, nil
+		}
+	}
+
+	_, err = tx.Exec("delete from cart_items where user_id = $1", order.UserId)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return uuid.Nil, false, errRollback
+		}
+		return uuid.Nil, false, err
+	}
+
+	_, err = tx.Exec("delete from cart_promo_codes where user_id = $1", order.UserId)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return uuid.Nil, false, errRollback
+		}
+		return uuid.Nil, false, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return uuid.Nil, false, err
+	}
+	return id, true, nil
+}
+
+// takeStock checks the tea of the item against its values in the location and
+// takes a weight item from the stock. The stock of the location override is
+// used if it has one, the catalogue stock otherwise. It returns false if the
+// tea has become hidden in the location or does not have enough stock left.
+func (r *OrderRepository) takeStock(tx *sqlx.Tx, locationId uuid.UUID, item *entity.OrderItem) (bool, error) {
+	tea := struct {
+		IsHidden       bool     `db:"is_hidden"`
+		Stock          *float64 `db:"stock"`
+		OverridesStock bool     `db:"overrides_stock"`
+	}{}
+	// The lock on the tea keeps concurrent orders from taking the same stock
+	// twice, whichever of the stocks it is.
+	err := tx.Get(&tea, `
+		select coalesce(tlo.is_hidden, t.is_hidden) as is_hidden,
+			   coalesce(tlo.stock, t.stock)         as stock,
+			   tlo.stock is not null                as overrides_stock
+		from teas t
+				 left join tea_location_overrides tlo on tlo.tea_id = t.id and tlo.location_id = $2
+		where t.id = $1
+		for update of t`, item.TeaId, locationId)
+	if err != nil {
+		// The tea has been deleted since it was put into the cart.
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return false, errRollback
+		}
+		return false, err
+	}
+
+	if tea.IsHidden {
+		return false, nil
+	}
+	if tea.Stock == nil {
+		return true, nil
+	}
+	if *tea.Stock <= 0 || item.Kind == entity.OrderItemKindWeight && *tea.Stock < item.Quantity {
+		return false, nil
+	}
+	if item.Kind != entity.OrderItemKindWeight {
+		return true, nil
+	}
+
+	if tea.OverridesStock {
+		_, err = tx.Exec(`
+			update tea_location_overrides
+			set stock      = stock - $1,
+				updated_at = now()
+			where tea_id = $2
+			  and location_id = $3`, item.Quantity, item.TeaId, locationId)
+	} else {
+		_, err = tx.Exec(`
+			update teas
+			set stock      = stock - $1,
+				updated_at = now()
+			where id = $2`, item.Quantity, item.TeaId)
+	}
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return false, errRollback
+		}
+		return false, err
+	}
+	return true, nil
+}
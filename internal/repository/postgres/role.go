@@ -0,0 +1,344 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/levchenki/tea-api/internal/entity"
+)
+
+type RoleRepository struct {
+	db *sqlx.DB
+}
+
+func NewRoleRepository(db *sqlx.DB) *RoleRepository {
+	return &RoleRepository{
+		db: db,
+	}
+}
+
+func (r *RoleRepository) GetAllPermissions() ([]entity.Permission, error) {
+	permissions := make([]entity.Permission, 0)
+	err := r.db.Select(&permissions,
+		"select code, coalesce(description, '') as description from permissions order by code")
+	if err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+func (r *RoleRepository) GetAll() ([]entity.Role, error) {
+	roles := make([]entity.Role, 0)
+	err := r.db.Select(&roles, `
+		select id,
+			   name,
+			   coalesce(description, '') as description,
+			   is_system
+		from roles
+		order by is_system desc, name`)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.fillPermissions(roles)
+	if err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (r *RoleRepository) GetById(id uuid.UUID) (*entity.Role, error) {
+	return r.getOne("where id = $1", id)
+}
+
+func (r *RoleRepository) GetByName(name string) (*entity.Role, error) {
+	return r.getOne("where name = $1", name)
+}
+
+func (r *RoleRepository) getOne(whereClause string, arg interface{}) (*entity.Role, error) {
+	role := entity.Role{}
+	err := r.db.Get(&role, `
+		select id,
+			   name,
+			   coalesce(description, '') as description,
+			   is_system
+		from roles `+whereClause, arg)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	roles := []entity.Role{role}
+	err = r.fillPermissions(roles)
+	if err != nil {
+		return nil, err
+	}
+	return &roles[0], nil
+}
+
+func (r *RoleRepository) GetByUserId(userId uuid.UUID) ([]entity.Role, error) {
+	roles := make([]entity.Role, 0)
+	err := r.db.Select(&roles, `
+		select r.id,
+			   r.name,
+			   coalesce(r.description, '') as description,
+			   r.is_system
+		from roles r
+				 join users_roles ur on r.id = ur.role_id
+		where ur.user_id = $1
+		order by r.name`, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.fillPermissions(roles)
+	if err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (r *RoleRepository) fillPermissions(roles []entity.Role) error {
+	if len(roles) == 0 {
+		return nil
+	}
+
+	roleIds := make([]uuid.UUID, len(roles))
+	for i := range roles {
+		roleIds[i] = roles[i].Id
+		roles[i].Permissions = make([]string, 0)
+	}
+
+	query, args, err := sqlx.In(`
+		select role_id, permission_code
+		from roles_permissions
+		where role_id in (?)
+		order by permission_code`, roleIds)
+	if err != nil {
+		return err
+	}
+	query = r.db.Rebind(query)
+
+	rows, err := r.db.Queryx(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	permissionsByRoleId := make(map[uuid.UUID][]string)
+	for rows.Next() {
+		var (
+			roleId     uuid.UUID
+			permission string
+		)
+		err = rows.Scan(&roleId, &permission)
+		if err != nil {
+			return err
+		}
+		permissionsByRoleId[roleId] = append(permissionsByRoleId[roleId], permission)
+	}
+
+	for i := range roles {
+		if permissions, ok := permissionsByRoleId[roles[i].Id]; ok {
+			roles[i].Permissions = permissions
+		}
+	}
+	return nil
+}
+
+func (r *RoleRepository) Create(role *entity.Role) (*entity.Role, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	var id uuid.UUID
+	err = tx.Get(&id, `
+		insert into roles (name, description)
+		values ($1, nullif($2, ''))
+		returning id`, role.Name, role.Description)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return nil, errRollback
+		}
+		return nil, err
+	}
+
+	err = r.insertPermissions(tx, id, role.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return r.GetById(id)
+}
+
+func (r *RoleRepository) Update(role *entity.Role) (*entity.Role, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+		update roles
+		set name        = $1,
+			description = nullif($2, '')
+		where id = $3`, role.Name, role.Description, role.Id)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return nil, errRollback
+		}
+		return nil, err
+	}
+
+	_, err = tx.Exec("delete from roles_permissions where role_id = $1", role.Id)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return nil, errRollback
+		}
+		return nil, err
+	}
+
+	err = r.insertPermissions(tx, role.Id, role.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return r.GetById(role.Id)
+}
+
+func (r *RoleRepository) insertPermissions(tx *sqlx.Tx, roleId uuid.UUID, permissions []string) error {
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	rolePermissions := make([]map[string]interface{}, 0, len(permissions))
+	for _, permission := range permissions {
+		rolePermissions = append(rolePermissions, map[string]interface{}{
+			"role_id":         roleId.String(),
+			"permission_code": permission,
+		})
+	}
+
+	_, err := tx.NamedExec(`
+		insert into roles_permissions (role_id, permission_code)
+		values (:role_id, :permission_code)`, rolePermissions)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *RoleRepository) Delete(id uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("delete from roles where id = $1", id)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *RoleRepository) ExistsByName(existedId uuid.UUID, name string) (bool, error) {
+	var exists bool
+	err := r.db.Get(&exists, "select exists(select 1 from roles where id != $1 and name = $2)", existedId, name)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+func (r *RoleRepository) CountExistingPermissions(codes []string) (int, error) {
+	if len(codes) == 0 {
+		return 0, nil
+	}
+
+	query, args, err := sqlx.In("select count(*) from permissions where code in (?)", codes)
+	if err != nil {
+		return 0, err
+	}
+	query = r.db.Rebind(query)
+
+	var count int
+	err = r.db.Get(&count, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *RoleRepository) AssignToUser(userId, roleId uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		insert into users_roles (user_id, role_id)
+		values ($1, $2)
+		on conflict do nothing`, userId, roleId)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *RoleRepository) UnassignFromUser(userId, roleId uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("delete from users_roles where user_id = $1 and role_id = $2", userId, roleId)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
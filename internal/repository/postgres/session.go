@@ -0,0 +1,280 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/levchenki/tea-api/internal/entity"
+)
+
+type SessionRepository struct {
+	db *sqlx.DB
+}
+
+func NewSessionRepository(db *sqlx.DB) *SessionRepository {
+	return &SessionRepository{
+		db: db,
+	}
+}
+
+const selectSessionQuery = `
+	select id,
+		   user_id,
+		   coalesce(user_agent, '')     as user_agent,
+		   coalesce(ip_address, '')     as ip_address,
+		   created_at,
+		   last_used_at,
+		   expires_at,
+		   revoked_at,
+		   coalesce(revoked_reason, '') as revoked_reason
+	from sessions`
+
+func (r *SessionRepository) Create(session *entity.Session, refreshToken *entity.RefreshToken) (*entity.Session, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+
+	var id uuid.UUID
+	err = tx.Get(&id, `
+		insert into sessions (user_id, user_agent, ip_address, expires_at)
+		values ($1, nullif($2, ''), nullif($3, ''), $4)
+		returning id`,
+		session.UserId, session.UserAgent, session.IpAddress, session.ExpiresAt)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return nil, errRollback
+		}
+		return nil, err
+	}
+
+	refreshToken.SessionId = id
+	err = r.insertRefreshToken(tx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	return r.GetById(id)
+}
+
+func (r *SessionRepository) GetById(id uuid.UUID) (*entity.Session, error) {
+	session := &entity.Session{}
+	err := r.db.Get(session, selectSessionQuery+" where id = $1", id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return session, nil
+}
+
+func (r *SessionRepository) GetActiveById(id uuid.UUID) (*entity.Session, error) {
+	session := &entity.Session{}
+	err := r.db.Get(session, selectSessionQuery+`
+		where id = $1
+		  and revoked_at is null
+		  and expires_at > now()`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return session, nil
+}
+
+func (r *SessionRepository) GetAllActiveByUserId(userId uuid.UUID) ([]entity.Session, error) {
+	sessions := make([]entity.Session, 0)
+	err := r.db.Select(&sessions, selectSessionQuery+`
+		where user_id = $1
+		  and revoked_at is null
+		  and expires_at > now()
+		order by last_used_at desc`, userId)
+	if err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+func (r *SessionRepository) GetRefreshToken(userId, id uuid.UUID) (*entity.RefreshToken, error) {
+	refreshToken := &entity.RefreshToken{}
+	err := r.db.Get(refreshToken, `
+		select rt.id,
+			   rt.session_id,
+			   rt.parent_id,
+			   rt.created_at,
+			   rt.expires_at,
+			   rt.rotated_at
+		from refresh_tokens rt
+				 join sessions s on s.id = rt.session_id
+		where rt.id = $1
+		  and s.user_id = $2`, id, userId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return refreshToken, nil
+}
+
+func (r *SessionRepository) GetLatestRefreshToken(sessionId uuid.UUID) (*entity.RefreshToken, error) {
+	refreshToken := &entity.RefreshToken{}
+	err := r.db.Get(refreshToken, `
+		select id,
+			   session_id,
+			   parent_id,
+			   created_at,
+			   expires_at,
+			   rotated_at
+		from refresh_tokens
+		where session_id = $1
+		  and rotated_at is null`, sessionId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return refreshToken, nil
+}
+
+// Rotate replaces the latest refresh token of the session with its child.
+// It returns false if the parent has already been rotated by a concurrent
+// request, in which case nothing is changed.
+func (r *SessionRepository) Rotate(session *entity.Session, refreshToken *entity.RefreshToken) (bool, error) {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return false, err
+	}
+
+	result, err := tx.Exec(`
+		update refresh_tokens
+		set rotated_at = now()
+		where id = $1
+		  and rotated_at is null`, refreshToken.ParentId)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return false, errRollback
+		}
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return false, errRollback
+		}
+		return false, err
+	}
+	if rowsAffected == 0 {
+		err = tx.Rollback()
+		if err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+
+	err = r.insertRefreshToken(tx, refreshToken)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = tx.NamedExec(`
+		update sessions
+		set user_agent   = coalesce(nullif(:user_agent, ''), user_agent),
+			ip_address   = coalesce(nullif(:ip_address, ''), ip_address),
+			expires_at   = :expires_at,
+			last_used_at = now()
+		where id = :id`, session)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return false, errRollback
+		}
+		return false, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *SessionRepository) insertRefreshToken(tx *sqlx.Tx, refreshToken *entity.RefreshToken) error {
+	_, err := tx.NamedExec(`
+		insert into refresh_tokens (id, session_id, parent_id, expires_at)
+		values (:id, :session_id, :parent_id, :expires_at)`, refreshToken)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *SessionRepository) Revoke(id uuid.UUID, reason string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		update sessions
+		set revoked_at     = now(),
+			revoked_reason = $1
+		where id = $2
+		  and revoked_at is null`, reason, id)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *SessionRepository) RevokeAllByUserId(userId uuid.UUID, reason string) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		update sessions
+		set revoked_at     = now(),
+			revoked_reason = $1
+		where user_id = $2
+		  and revoked_at is null`, reason, userId)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -32,6 +32,7 @@ func (r *TeaRepository) GetById(id uuid.UUID) (*entity.TeaWithRating, error) {
 			   t.created_at,
 			   t.updated_at,
 			   is_hidden,
+			   stock,
 			   category_id,
 			   unit_id,
 			   round(coalesce((select avg(rating) from evaluations where tea_id = t.id), 0), 2) as average_rating
@@ -52,11 +53,13 @@ func (r *TeaRepository) GetById(id uuid.UUID) (*entity.TeaWithRating, error) {
 func (r *TeaRepository) GetByIdWithUser(id uuid.UUID, userId uuid.UUID) (*entity.TeaWithRating, error) {
 	tea := entity.TeaWithRating{}
 	query := `
-		with favourites as (select tea_id,
-								   user_id,
+		with favourites as (select tli.tea_id,
+								   tl.user_id,
 								   true as is_favourite
-							from users_favourite_teas
-							where user_id = $1)
+							from tea_list_items tli
+									 join tea_lists tl on tl.id = tli.list_id
+							where tl.user_id = $1
+							  and tl.is_favourite)
 		select t.id,
 			   name,
 			   serve_price,
@@ -65,6 +68,7 @@ func (r *TeaRepository) GetByIdWithUser(id uuid.UUID, userId uuid.UUID) (*entity
 			   t.created_at,
 			   t.updated_at,
 			   is_hidden,
+			   stock,
 			   category_id,
 			   unit_id,
 			   coalesce(rating, 0)                                                              as rating,
@@ -87,6 +91,38 @@ func (r *TeaRepository) GetByIdWithUser(id uuid.UUID, userId uuid.UUID) (*entity
 	return &tea, nil
 }
 
+func (r *TeaRepository) GetAllByIds(ids []uuid.UUID) ([]entity.Tea, error) {
+	teas := make([]entity.Tea, 0, len(ids))
+	if len(ids) == 0 {
+		return teas, nil
+	}
+
+	query, args, err := sqlx.In(`
+		select t.id,
+			   t.name,
+			   t.serve_price,
+			   t.unit_price,
+			   coalesce(t.description, '') as description,
+			   t.created_at,
+			   t.updated_at,
+			   t.is_hidden,
+			   t.stock,
+			   t.category_id,
+			   t.unit_id
+		from teas t
+		where t.id in (?)`, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	query = r.db.Rebind(query)
+	err = r.db.Select(&teas, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return teas, nil
+}
+
 func (r *TeaRepository) GetAll(filters *teaSchemas.Filters) ([]entity.TeaWithRating, uint64, error) {
 	teas := make([]entity.TeaWithRating, 0)
 
@@ -117,11 +153,13 @@ func (r *TeaRepository) prepareCountQuery(filters *teaSchemas.Filters) (string,
 	isNotEmptyUser := filters.UserId != uuid.Nil
 	if isNotEmptyUser {
 		countQuery = `
-		with favourites as (select tea_id,
-								   user_id,
+		with favourites as (select tli.tea_id,
+								   tl.user_id,
 								   true as is_favourite
-							from users_favourite_teas
-							where user_id = :user_id)
+							from tea_list_items tli
+									 join tea_lists tl on tl.id = tli.list_id
+							where tl.user_id = :user_id
+							  and tl.is_favourite)
 		select count(distinct t.id)
 		from teas t
 				 left join favourites on t.id = favourites.tea_id`
@@ -143,23 +181,26 @@ func (r *TeaRepository) prepareMinMaxPricesQuery(filters *teaSchemas.Filters) (s
 	isNotEmptyUser := filters.UserId != uuid.Nil
 	if isNotEmptyUser {
 		minMaxQuery = `
-		with favourites as (select tea_id,
-								   user_id,
+		with favourites as (select tli.tea_id,
+								   tl.user_id,
 								   true as is_favourite
-							from users_favourite_teas
-							where user_id = :user_id)
+							from tea_list_items tli
+									 join tea_lists tl on tl.id = tli.list_id
+							where tl.user_id = :user_id
+							  and tl.is_favourite)
 		select
-			coalesce(min(t.serve_price), 0) as minServePrice,
-			coalesce(max(t.serve_price), 0) as maxServePrice
+			coalesce(min(%[1]s), 0) as minServePrice,
+			coalesce(max(%[1]s), 0) as maxServePrice
 		from teas t
 			 left join favourites on t.id = favourites.tea_id`
 	} else {
 		minMaxQuery = `
 			select
-			    coalesce(min(t.serve_price), 0) as min,
-			    coalesce(max(t.serve_price), 0) as max
+			    coalesce(min(%[1]s), 0) as min,
+			    coalesce(max(%[1]s), 0) as max
 			from teas t`
 	}
+	minMaxQuery = fmt.Sprintf(minMaxQuery, teaColumn(filters, "serve_price"))
 
 	priceFilters := *filters
 	priceFilters.MinServePrice = 0
@@ -181,19 +222,22 @@ func (r *TeaRepository) prepareSelectAllQuery(filters *teaSchemas.Filters) (stri
 	isNotEmptyUser := filters.UserId != uuid.Nil
 	if isNotEmptyUser {
 		getAllQuery = `
-		with favourites as (select tea_id,
-								   user_id,
+		with favourites as (select tli.tea_id,
+								   tl.user_id,
 								   true as is_favourite
-							from users_favourite_teas
-							where user_id = :user_id)
+							from tea_list_items tli
+									 join tea_lists tl on tl.id = tli.list_id
+							where tl.user_id = :user_id
+							  and tl.is_favourite)
 		select distinct t.id,
 						t.name,
-						t.serve_price,
-						t.unit_price,
+						%[1]s                                                                  as serve_price,
+						%[2]s                                                                  as unit_price,
 						coalesce(t.description, '')                                            as description,
 						t.created_at,
 						t.updated_at,
-						t.is_hidden,
+						%[3]s                                                                  as is_hidden,
+						%[4]s                                                                  as stock,
 						t.category_id,
 						t.unit_id,
 						coalesce(e.rating, 0)                                                  as rating,
@@ -210,17 +254,24 @@ func (r *TeaRepository) prepareSelectAllQuery(filters *teaSchemas.Filters) (stri
 		select distinct 
 			t.id,
 			t.name,
-			t.serve_price,
-			t.unit_price,
+			%[1]s as serve_price,
+			%[2]s as unit_price,
 			coalesce(t.description, '') as description,
 			t.created_at,
 			t.updated_at,
-			t.is_hidden,
+			%[3]s as is_hidden,
+			%[4]s as stock,
 			t.category_id,
 			t.unit_id,
 		   	round(coalesce((select avg(rating) from evaluations where tea_id = t.id), 0), 2) as average_rating
 		from teas t`
 	}
+	getAllQuery = fmt.Sprintf(getAllQuery,
+		teaColumn(filters, "serve_price"),
+		teaColumn(filters, "unit_price"),
+		teaColumn(filters, "is_hidden"),
+		teaColumn(filters, "stock"),
+	)
 
 	getAllQuery, whereClause := r.selectAllWhereClause(getAllQuery, filters)
 
@@ -253,6 +304,10 @@ func (r *TeaRepository) prepareSelectAllQuery(filters *teaSchemas.Filters) (stri
 func (r *TeaRepository) selectAllWhereClause(getQuery string, filters *teaSchemas.Filters) (string, string) {
 	var whereStmt string
 	filterStatements := make([]string, 0, 10)
+	if filters.LocationId != uuid.Nil {
+		getQuery += " left join tea_location_overrides tlo on tlo.tea_id = t.id and tlo.location_id = :location_id"
+	}
+
 	if filters.CategoryId != uuid.Nil {
 		categoryStmt := "t.category_id = :category_id"
 		filterStatements = append(filterStatements, categoryStmt)
@@ -271,15 +326,15 @@ func (r *TeaRepository) selectAllWhereClause(getQuery string, filters *teaSchema
 	}
 
 	if filters.MinServePrice != 0 && filters.MaxServePrice != 0 {
-		servePriceStmt := "t.serve_price between :min_serve_price and :max_serve_price"
+		servePriceStmt := teaColumn(filters, "serve_price") + " between :min_serve_price and :max_serve_price"
 		filterStatements = append(filterStatements, servePriceStmt)
 	}
 
 	if filters.IsOnlyHidden {
-		isHiddenStmt := "t.is_hidden is true"
+		isHiddenStmt := teaColumn(filters, "is_hidden") + " is true"
 		filterStatements = append(filterStatements, isHiddenStmt)
 	} else {
-		isHiddenStmt := "t.is_hidden is false"
+		isHiddenStmt := teaColumn(filters, "is_hidden") + " is false"
 		filterStatements = append(filterStatements, isHiddenStmt)
 	}
 
@@ -288,6 +343,15 @@ func (r *TeaRepository) selectAllWhereClause(getQuery string, filters *teaSchema
 		filterStatements = append(filterStatements, isFavouriteStmt)
 	}
 
+	if filters.ListId != uuid.Nil {
+		listStmt := `t.id in (select tli.tea_id
+							  from tea_list_items tli
+									   join tea_lists tl on tl.id = tli.list_id
+							  where tli.list_id = :list_id
+								and tl.user_id = :user_id)`
+		filterStatements = append(filterStatements, listStmt)
+	}
+
 	if len(filterStatements) > 0 {
 		whereStmt = fmt.Sprintf(" where %s", strings.Join(filterStatements, " and "))
 	}
@@ -295,6 +359,15 @@ func (r *TeaRepository) selectAllWhereClause(getQuery string, filters *teaSchema
 	return getQuery, whereStmt
 }
 
+// teaColumn returns the value of the column in the location of the filters.
+// Without a location the catalogue value is used.
+func teaColumn(filters *teaSchemas.Filters, column string) string {
+	if filters.LocationId == uuid.Nil {
+		return "t." + column
+	}
+	return fmt.Sprintf("coalesce(tlo.%s, t.%s)", column, column)
+}
+
 func (r *TeaRepository) bindParams(query string, args ...interface{}) (string, []interface{}, error) {
 	query, args, err := sqlx.Named(query, args)
 	if err != nil {
@@ -367,7 +440,8 @@ func (r *TeaRepository) insertTea(inputTea *entity.Tea, tx *sqlx.Tx) (*entity.Te
 			coalesce(description, '') as description,
 			category_id,
 		    unit_id,
-			is_hidden`, inputTea)
+			is_hidden,
+			stock`, inputTea)
 	if err != nil {
 		return nil, err
 	}
@@ -506,7 +580,8 @@ func (r *TeaRepository) updateTea(id uuid.UUID, inputTea *teaSchemas.RequestMode
 			coalesce(description, '') as description,
 			category_id,
 		    unit_id,
-			is_hidden
+			is_hidden,
+			stock
 		`, tea)
 
 	if err != nil {
@@ -541,7 +616,7 @@ func (r *TeaRepository) Delete(id uuid.UUID) error {
 		return err
 	}
 
-	err = r.deleteFavourite(tx, id)
+	err = r.deleteListItems(tx, id)
 	if err != nil {
 		return err
 	}
@@ -588,8 +663,8 @@ func (r *TeaRepository) deleteEvaluations(tx *sqlx.Tx, teaId uuid.UUID) error {
 	return nil
 }
 
-func (r *TeaRepository) deleteFavourite(tx *sqlx.Tx, teaId uuid.UUID) error {
-	_, err := tx.Exec("delete from users_favourite_teas where tea_id = $1", teaId)
+func (r *TeaRepository) deleteListItems(tx *sqlx.Tx, teaId uuid.UUID) error {
+	_, err := tx.Exec("delete from tea_list_items where tea_id = $1", teaId)
 
 	if err != nil {
 		errRollback := tx.Rollback()
@@ -673,6 +748,50 @@ func (r *TeaRepository) DeleteEvaluation(userId, teaId uuid.UUID) error {
 	return nil
 }
 
+func (r *TeaRepository) SetVisibility(id uuid.UUID, isHidden bool) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("update teas set is_hidden = $1, updated_at = now() where id = $2", isHidden, id)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *TeaRepository) SetStock(id uuid.UUID, stock *float64) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec("update teas set stock = $1, updated_at = now() where id = $2", stock, id)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 func (r *TeaRepository) ExistsByCategoryId(categoryId uuid.UUID) (bool, error) {
 	var exists bool
 	err := r.db.Get(&exists, "select exists(select 1 from teas where category_id = $1)", categoryId)
@@ -706,8 +825,26 @@ func (r *TeaRepository) SetFavourite(id, userId uuid.UUID) error {
 		return err
 	}
 	_, err = tx.Exec(`
-		insert into users_favourite_teas (tea_id, user_id)
-		values ($1, $2)
+		insert into tea_lists (user_id, name, is_favourite)
+		values ($1, $2, true)
+		on conflict (user_id) where is_favourite do nothing`, userId, entity.FavouriteListName)
+
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	_, err = tx.Exec(`
+		insert into tea_list_items (list_id, tea_id, position)
+		select tl.id,
+			   $1,
+			   coalesce((select max(position) from tea_list_items where list_id = tl.id), 0) + 1
+		from tea_lists tl
+		where tl.user_id = $2
+		  and tl.is_favourite
 		on conflict do nothing`, id, userId)
 
 	if err != nil {
@@ -729,7 +866,11 @@ func (r *TeaRepository) RemoveFavourite(id, userId uuid.UUID) error {
 	if err != nil {
 		return err
 	}
-	_, err = tx.Exec("delete from users_favourite_teas where tea_id = $1 and user_id = $2", id, userId)
+	_, err = tx.Exec(`
+		delete
+		from tea_list_items
+		where tea_id = $1
+		  and list_id in (select id from tea_lists where user_id = $2 and is_favourite)`, id, userId)
 	if err != nil {
 		errRollback := tx.Rollback()
 		if errRollback != nil {
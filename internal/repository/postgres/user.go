@@ -1,9 +1,14 @@
 package postgres
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/schemas/userSchemas"
+	"strings"
 )
 
 type UserRepository struct {
@@ -14,28 +19,108 @@ func NewUserRepository(db *sqlx.DB) *UserRepository {
 	return &UserRepository{db}
 }
 
-func (r *UserRepository) Create(user *entity.User) error {
+// Create inserts the user together with the identity it signed up with, so
+// a user can never exist without a way to sign in.
+func (r *UserRepository) Create(user *entity.User, identity *entity.Identity) (uuid.UUID, error) {
 	tx, err := r.db.Beginx()
 	if err != nil {
-		return err
+		return uuid.Nil, err
 	}
 
-	_, err = tx.NamedExec(`
+	err = releaseUsername(tx, uuid.Nil, user.Username)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return uuid.Nil, errRollback
+		}
+		return uuid.Nil, err
+	}
+
+	var id uuid.UUID
+	err = tx.Get(&id, `
 		insert into users (telegram_id,
 						   username,
 						   created_at,
 						   updated_at,
 						   first_name,
 						   last_name,
-		                   is_admin)
-		values (:telegram_id,
-				:username,
+						   photo_url,
+						   language_code,
+						   is_premium)
+		values (nullif($1::bigint, 0),
+				nullif($2, ''),
 				now(),
 				now(),
-				:first_name,
-				:last_name,
-		        :is_admin)
-		`, &user)
+				$3,
+				nullif($4, ''),
+				nullif($5, ''),
+				nullif($6, ''),
+				$7)
+		returning id`,
+		user.TelegramId, user.Username, user.FirstName, user.LastName,
+		user.PhotoUrl, user.LanguageCode, user.IsPremium)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return uuid.Nil, errRollback
+		}
+		return uuid.Nil, err
+	}
+
+	_, err = tx.Exec(`
+		insert into identities (user_id, provider, subject, email)
+		values ($1, $2, $3, nullif($4, ''))`,
+		id, identity.Provider, identity.Subject, identity.Email)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return uuid.Nil, errRollback
+		}
+		return uuid.Nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return id, nil
+}
+
+// UpdateTelegramProfile overwrites the profile fields that come from
+// Telegram. The language and the premium status are kept unless they have
+// been sent. The row is left untouched if nothing has changed.
+func (r *UserRepository) UpdateTelegramProfile(user *entity.User, withLanguageAndPremium bool) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	err = releaseUsername(tx, user.Id, user.Username)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	_, err = tx.Exec(`
+		update users
+		set first_name    = $2,
+			last_name     = nullif($3, ''),
+			username      = nullif($4, ''),
+			photo_url     = nullif($5, ''),
+			language_code = case when $8 then nullif($6, '') else language_code end,
+			is_premium    = case when $8 then $7 else is_premium end,
+			updated_at    = now()
+		where id = $1
+		  and (first_name, last_name, username, photo_url, language_code, is_premium)
+			is distinct from
+			  ($2, nullif($3, ''), nullif($4, ''), nullif($5, ''),
+			   case when $8 then nullif($6, '') else language_code end,
+			   case when $8 then $7 else is_premium end)`,
+		user.Id, user.FirstName, user.LastName, user.Username, user.PhotoUrl,
+		user.LanguageCode, user.IsPremium, withLanguageAndPremium)
 	if err != nil {
 		errRollback := tx.Rollback()
 		if errRollback != nil {
@@ -51,47 +136,86 @@ func (r *UserRepository) Create(user *entity.User) error {
 	return nil
 }
 
-func (r *UserRepository) Exists(telegramId uint64) (bool, error) {
-	var exists bool
-	err := r.db.Get(&exists,
-		"select exists(select 1 from users where telegram_id = $1)", &telegramId)
+// releaseUsername takes the username away from other users. Telegram
+// usernames are unique, so whoever still has it in our table changed it on
+// Telegram since their last sign-in, and their row is stale.
+func releaseUsername(tx *sqlx.Tx, userId uuid.UUID, username string) error {
+	if username == "" {
+		return nil
+	}
+
+	_, err := tx.Exec(`
+		update users
+		set username   = null,
+			updated_at = now()
+		where lower(username) = lower($1)
+		  and id <> $2`, username, userId)
 	if err != nil {
-		return false, err
+		return err
 	}
-	return exists, nil
+	return nil
 }
 
-func (r *UserRepository) GetByTelegramId(telegramId uint64) (*entity.User, error) {
-	user := entity.User{}
-	err := r.db.Get(&user, `
+const selectUserQuery = `
 	select
 		u.id,
-		u.telegram_id,
+		coalesce(u.telegram_id, 0) as telegram_id,
 		coalesce(u.first_name, '') as first_name,
 		coalesce(u.last_name, '') as last_name,
 		coalesce(u.username, '') as username,
+		coalesce(u.photo_url, '') as photo_url,
+		coalesce(u.language_code, '') as language_code,
+		u.is_premium,
 		u.created_at,
 		u.updated_at,
-		u.is_admin
-	from users u where telegram_id = $1 limit 1`, &telegramId)
+		exists(select 1
+			   from users_roles ur
+						join roles r on r.id = ur.role_id
+			   where ur.user_id = u.id
+				 and r.name = 'admin') as is_admin,
+		coalesce(u.display_name, '') as display_name,
+		coalesce(u.language, '') as language,
+		u.notify_orders,
+		u.notify_favourites,
+		u.is_blocked,
+		u.blocked_at,
+		u.location_id
+	from users u`
+
+func (r *UserRepository) GetById(id uuid.UUID) (*entity.User, error) {
+	return r.getOne("u.id = $1", id)
+}
+
+func (r *UserRepository) GetByTelegramId(telegramId uint64) (*entity.User, error) {
+	return r.getOne("u.telegram_id = $1", telegramId)
+}
+
+func (r *UserRepository) getOne(whereClause string, arg interface{}) (*entity.User, error) {
+	user := &entity.User{}
+	err := r.db.Get(user, selectUserQuery+" where "+whereClause+" limit 1", arg)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
 		return nil, err
 	}
-	return &user, nil
+	return user, nil
 }
 
-func (r *UserRepository) SaveRefreshToken(userId, refreshTokenId uuid.UUID) error {
+func (r *UserRepository) UpdateProfile(user *entity.User) error {
 	tx, err := r.db.Beginx()
 	if err != nil {
 		return err
 	}
-	_, err = tx.Exec(
-		`
+
+	_, err = tx.NamedExec(`
 		update users
-		set refresh_token_id = $1,
-		    updated_at = now()
-		where id = $2`, refreshTokenId, userId,
-	)
+		set display_name      = nullif(:display_name, ''),
+			language          = nullif(:language, ''),
+			notify_orders     = :notify_orders,
+			notify_favourites = :notify_favourites,
+			updated_at        = now()
+		where id = :id`, user)
 	if err != nil {
 		errRollback := tx.Rollback()
 		if errRollback != nil {
@@ -99,6 +223,7 @@ func (r *UserRepository) SaveRefreshToken(userId, refreshTokenId uuid.UUID) erro
 		}
 		return err
 	}
+
 	err = tx.Commit()
 	if err != nil {
 		return err
@@ -106,30 +231,242 @@ func (r *UserRepository) SaveRefreshToken(userId, refreshTokenId uuid.UUID) erro
 	return nil
 }
 
-func (r *UserRepository) IsRefreshTokenExists(userId, refreshTokenId uuid.UUID) (bool, error) {
-	var exists bool
-	err := r.db.Get(&exists, "select exists(select 1 from users where id = $1 and refresh_token_id = $2)", userId, refreshTokenId)
+func (r *UserRepository) GetStatistics(id uuid.UUID) (*entity.UserStatistics, error) {
+	statistics := &entity.UserStatistics{}
+	err := r.db.Get(statistics, `
+		select (select count(*) from evaluations where user_id = $1) as rated_count,
+			   (select count(*)
+				from tea_list_items tli
+						 join tea_lists tl on tl.id = tli.list_id
+				where tl.user_id = $1
+				  and tl.is_favourite)                               as favourites_count,
+			   (select count(*)
+				from tea_lists
+				where user_id = $1
+				  and not is_favourite)                              as lists_count`, id)
 	if err != nil {
-		return false, err
+		if errors.Is(err, sql.ErrNoRows) {
+			return statistics, nil
+		}
+		return nil, err
 	}
-	return exists, nil
+	return statistics, nil
 }
 
-func (r *UserRepository) GetById(id uuid.UUID) (*entity.User, error) {
-	user := &entity.User{}
-	err := r.db.Get(user, `
-	select
-		u.id,
-		u.telegram_id,
-		coalesce(u.first_name, '') as first_name,
-		coalesce(u.last_name, '') as last_name,
-		coalesce(u.username, '') as username,
-		u.created_at,
-		u.updated_at,
-		u.is_admin
-	from users u where id = $1 limit 1`, id)
+func (r *UserRepository) Delete(id uuid.UUID) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	queries := []string{
+		"update evaluations set user_id = null, note = null, updated_at = now() where user_id = $1",
+		"delete from tea_list_items where list_id in (select id from tea_lists where user_id = $1)",
+		"delete from tea_lists where user_id = $1",
+		"delete from users where id = $1",
+	}
+	for _, query := range queries {
+		_, err = tx.Exec(query, id)
+		if err != nil {
+			errRollback := tx.Rollback()
+			if errRollback != nil {
+				return errRollback
+			}
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *UserRepository) GetAll(filters *userSchemas.Filters) ([]entity.User, uint64, error) {
+	users := make([]entity.User, 0)
+
+	whereClause := r.selectAllWhereClause(filters)
+
+	filters.Offset = filters.Limit * (filters.Page - 1)
+	query, args, err := r.bindParams(`
+		select u.id,
+			   coalesce(u.telegram_id, 0)   as telegram_id,
+			   coalesce(u.first_name, '')   as first_name,
+			   coalesce(u.last_name, '')    as last_name,
+			   coalesce(u.username, '')     as username,
+			   coalesce(u.photo_url, '')    as photo_url,
+			   coalesce(u.language_code, '') as language_code,
+			   u.is_premium,
+			   u.created_at,
+			   u.updated_at,
+			   exists(select 1
+					  from users_roles ur
+							   join roles r on r.id = ur.role_id
+					  where ur.user_id = u.id
+						and r.name = 'admin')   as is_admin,
+			   coalesce(u.display_name, '') as display_name,
+			   coalesce(u.language, '')     as language,
+			   u.notify_orders,
+			   u.notify_favourites,
+			   u.is_blocked,
+			   u.blocked_at,
+			   u.location_id
+		from users u`+whereClause+`
+		order by u.created_at desc
+		limit :limit offset :offset`, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	err = r.db.Select(&users, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	countQuery, countArgs, err := r.bindParams("select count(*) from users u"+whereClause, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total uint64
+	err = r.db.Get(&total, countQuery, countArgs...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+func (r *UserRepository) selectAllWhereClause(filters *userSchemas.Filters) string {
+	filterStatements := make([]string, 0, 3)
+
+	if filters.Search != "" {
+		searchStmt := `(lower(u.username) like '%' || lower(:search) || '%'
+			or lower(u.first_name) like '%' || lower(:search) || '%'
+			or lower(u.last_name) like '%' || lower(:search) || '%'`
+		if filters.TelegramId != 0 {
+			searchStmt += " or u.telegram_id = :telegram_id"
+		}
+		searchStmt += ")"
+		filterStatements = append(filterStatements, searchStmt)
+	}
+
+	if filters.IsAdmin != nil {
+		isAdminStmt := `exists(select 1
+							   from users_roles ur
+										join roles r on r.id = ur.role_id
+							   where ur.user_id = u.id
+								 and r.name = 'admin') = :is_admin`
+		filterStatements = append(filterStatements, isAdminStmt)
+	}
+
+	if filters.IsBlocked != nil {
+		filterStatements = append(filterStatements, "u.is_blocked = :is_blocked")
+	}
+
+	if len(filterStatements) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" where %s", strings.Join(filterStatements, " and "))
+}
+
+func (r *UserRepository) bindParams(query string, arg interface{}) (string, []interface{}, error) {
+	query, args, err := sqlx.Named(query, arg)
+	if err != nil {
+		return "", nil, err
+	}
+
+	query = r.db.Rebind(query)
+	return query, args, nil
+}
+
+func (r *UserRepository) GetLastEvaluations(id uuid.UUID, limit uint64) ([]entity.UserEvaluation, error) {
+	evaluations := make([]entity.UserEvaluation, 0)
+	err := r.db.Select(&evaluations, `
+		select e.tea_id,
+			   t.name               as tea_name,
+			   e.rating,
+			   coalesce(e.note, '') as note,
+			   e.updated_at
+		from evaluations e
+				 join teas t on t.id = e.tea_id
+		where e.user_id = $1
+		order by e.updated_at desc
+		limit $2`, id, limit)
 	if err != nil {
 		return nil, err
 	}
-	return user, nil
+	return evaluations, nil
+}
+
+func (r *UserRepository) SetTelegramId(id uuid.UUID, telegramId uint64) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		update users
+		set telegram_id = nullif($1::bigint, 0),
+			updated_at  = now()
+		where id = $2`, telegramId, id)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *UserRepository) SetBlocked(id uuid.UUID, isBlocked bool) error {
+	tx, err := r.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		update users
+		set is_blocked = $1,
+			blocked_at = case when $1 then now() end,
+			updated_at = now()
+		where id = $2`, isBlocked, id)
+	if err != nil {
+		errRollback := tx.Rollback()
+		if errRollback != nil {
+			return errRollback
+		}
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetLocation scopes the user to the location. The nil id lifts the scope.
+func (r *UserRepository) SetLocation(id, locationId uuid.UUID) error {
+	var location *uuid.UUID
+	if locationId != uuid.Nil {
+		location = &locationId
+	}
+
+	_, err := r.db.Exec(`
+		update users
+		set location_id = $1,
+			updated_at  = now()
+		where id = $2`, location, id)
+	if err != nil {
+		return err
+	}
+	return nil
 }
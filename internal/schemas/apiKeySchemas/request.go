@@ -0,0 +1,49 @@
+package apiKeySchemas
+
+import (
+	"fmt"
+	"github.com/levchenki/tea-api/internal/entity"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type RequestModel struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+func (rm *RequestModel) Bind(r *http.Request) error {
+	rm.Name = strings.TrimSpace(rm.Name)
+	if rm.Name == "" {
+		return fmt.Errorf("name is a required field")
+	}
+	if len(rm.Name) > 255 {
+		return fmt.Errorf("name must be at most 255 characters long")
+	}
+
+	if len(rm.Scopes) == 0 {
+		return fmt.Errorf("scopes must contain at least one scope")
+	}
+
+	uniqueScopes := make([]string, 0, len(rm.Scopes))
+	seen := make(map[string]struct{}, len(rm.Scopes))
+	for _, scope := range rm.Scopes {
+		scope = strings.TrimSpace(scope)
+		if _, ok := entity.ApiKeyScopePermissions[scope]; !ok {
+			return fmt.Errorf("unknown scope %q", scope)
+		}
+		if _, ok := seen[scope]; ok {
+			continue
+		}
+		seen[scope] = struct{}{}
+		uniqueScopes = append(uniqueScopes, scope)
+	}
+	rm.Scopes = uniqueScopes
+
+	if rm.ExpiresAt != nil && !rm.ExpiresAt.After(time.Now()) {
+		return fmt.Errorf("expiresAt must be in the future")
+	}
+	return nil
+}
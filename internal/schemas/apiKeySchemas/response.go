@@ -0,0 +1,52 @@
+package apiKeySchemas
+
+import (
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"time"
+)
+
+type ResponseModel struct {
+	Id         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  *uuid.UUID `json:"createdBy,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+func NewApiKeyResponseModel(apiKey *entity.ApiKey) *ResponseModel {
+	scopes := apiKey.Scopes
+	if scopes == nil {
+		scopes = make([]string, 0)
+	}
+
+	return &ResponseModel{
+		Id:         apiKey.Id,
+		Name:       apiKey.Name,
+		Prefix:     apiKey.Prefix,
+		Scopes:     scopes,
+		CreatedBy:  apiKey.CreatedBy,
+		CreatedAt:  apiKey.CreatedAt,
+		ExpiresAt:  apiKey.ExpiresAt,
+		LastUsedAt: apiKey.LastUsedAt,
+		RevokedAt:  apiKey.RevokedAt,
+	}
+}
+
+// CreatedResponseModel is returned once on creation. The key itself is not
+// stored and can not be shown again.
+type CreatedResponseModel struct {
+	ResponseModel
+	Key string `json:"key"`
+}
+
+func NewCreatedResponseModel(apiKey *entity.ApiKey, key string) *CreatedResponseModel {
+	return &CreatedResponseModel{
+		ResponseModel: *NewApiKeyResponseModel(apiKey),
+		Key:           key,
+	}
+}
@@ -0,0 +1,60 @@
+package discountSchemas
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ReportFilters limit the report to the redemptions made in the period.
+type ReportFilters struct {
+	From *time.Time
+	To   *time.Time
+}
+
+func (f *ReportFilters) Validate(r *http.Request) error {
+	query := r.URL.Query()
+	for name, value := range map[string]**time.Time{"from": &f.From, "to": &f.To} {
+		valueStr := query.Get(name)
+		if valueStr == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, valueStr)
+		if err != nil {
+			return fmt.Errorf("invalid %s value: %s", name, valueStr)
+		}
+		*value = &t
+	}
+
+	if f.From != nil && f.To != nil && !f.To.After(*f.From) {
+		return fmt.Errorf("to must be after from")
+	}
+	return nil
+}
+
+type RedemptionFilters struct {
+	Limit  uint64 `db:"limit"`
+	Page   uint64 `db:"page"`
+	Offset uint64 `db:"offset"`
+}
+
+func (f *RedemptionFilters) Validate(r *http.Request) error {
+	query := r.URL.Query()
+	limit, err := strconv.ParseUint(query.Get("limit"), 10, 64)
+	if err != nil {
+		limit = 20
+	}
+	page, err := strconv.ParseUint(query.Get("page"), 10, 64)
+	if err != nil {
+		page = 1
+	}
+
+	if page == 0 {
+		return fmt.Errorf("the page can not be equal to 0")
+	}
+
+	f.Limit = limit
+	f.Page = page
+	return nil
+}
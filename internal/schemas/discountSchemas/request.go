@@ -0,0 +1,106 @@
+package discountSchemas
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var codePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,32}$`)
+
+// RequestModel creates a promo code if the code is given and an automatic
+// rule otherwise. The target is required for every scope but order.
+type RequestModel struct {
+	Name           string     `json:"name"`
+	Code           string     `json:"code,omitempty"`
+	Kind           string     `json:"kind" enums:"percent,fixed"`
+	Value          float64    `json:"value"`
+	Scope          string     `json:"scope,omitempty" enums:"order,category,tag,tea" default:"order"`
+	TargetId       *uuid.UUID `json:"targetId,omitempty"`
+	MinOrderAmount float64    `json:"minOrderAmount,omitempty"`
+	StartsAt       *time.Time `json:"startsAt,omitempty"`
+	EndsAt         *time.Time `json:"endsAt,omitempty"`
+	UsageLimit     *int       `json:"usageLimit,omitempty"`
+	PerUserLimit   *int       `json:"perUserLimit,omitempty"`
+	IsActive       *bool      `json:"isActive,omitempty" default:"true"`
+}
+
+func (rm *RequestModel) Bind(r *http.Request) error {
+	rm.Name = strings.TrimSpace(rm.Name)
+	rm.Code = NormalizeCode(rm.Code)
+	if rm.Scope == "" {
+		rm.Scope = entity.DiscountScopeOrder
+	}
+
+	if rm.Name == "" {
+		return fmt.Errorf("name is a required field")
+	}
+	if utf8.RuneCountInString(rm.Name) > 128 {
+		return fmt.Errorf("name must be at most 128 characters long")
+	}
+	if rm.Code != "" && !codePattern.MatchString(rm.Code) {
+		return fmt.Errorf("code must be 3 to 32 letters, digits, dashes or underscores")
+	}
+
+	switch rm.Kind {
+	case entity.DiscountKindPercent:
+		if rm.Value <= 0 || rm.Value > 100 {
+			return fmt.Errorf("value of a percent discount must be greater than 0 and at most 100")
+		}
+	case entity.DiscountKindFixed:
+		if rm.Value <= 0 {
+			return fmt.Errorf("value must be greater than 0")
+		}
+	default:
+		return fmt.Errorf("kind must be %s or %s", entity.DiscountKindPercent, entity.DiscountKindFixed)
+	}
+
+	switch rm.Scope {
+	case entity.DiscountScopeOrder:
+		if rm.TargetId != nil {
+			return fmt.Errorf("targetId can not be set for the %s scope", rm.Scope)
+		}
+	case entity.DiscountScopeCategory, entity.DiscountScopeTag, entity.DiscountScopeTea:
+		if rm.TargetId == nil || *rm.TargetId == uuid.Nil {
+			return fmt.Errorf("targetId is a required field for the %s scope", rm.Scope)
+		}
+	default:
+		return fmt.Errorf("unknown scope %s", rm.Scope)
+	}
+
+	if rm.MinOrderAmount < 0 {
+		return fmt.Errorf("minOrderAmount can not be negative")
+	}
+	if rm.StartsAt != nil && rm.EndsAt != nil && !rm.EndsAt.After(*rm.StartsAt) {
+		return fmt.Errorf("endsAt must be after startsAt")
+	}
+	if rm.UsageLimit != nil && *rm.UsageLimit <= 0 {
+		return fmt.Errorf("usageLimit must be greater than 0")
+	}
+	if rm.PerUserLimit != nil && *rm.PerUserLimit <= 0 {
+		return fmt.Errorf("perUserLimit must be greater than 0")
+	}
+	return nil
+}
+
+type PromoCodeRequestModel struct {
+	Code string `json:"code"`
+}
+
+func (rm *PromoCodeRequestModel) Bind(r *http.Request) error {
+	rm.Code = NormalizeCode(rm.Code)
+	if rm.Code == "" {
+		return fmt.Errorf("code is a required field")
+	}
+	return nil
+}
+
+// NormalizeCode makes codes case-insensitive, they are stored upper-case.
+func NormalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
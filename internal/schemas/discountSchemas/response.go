@@ -0,0 +1,85 @@
+package discountSchemas
+
+import (
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"time"
+)
+
+type ResponseModel struct {
+	Id             uuid.UUID  `json:"id"`
+	Name           string     `json:"name"`
+	Code           string     `json:"code,omitempty"`
+	Kind           string     `json:"kind" enums:"percent,fixed"`
+	Value          float64    `json:"value"`
+	Scope          string     `json:"scope" enums:"order,category,tag,tea"`
+	TargetId       *uuid.UUID `json:"targetId,omitempty"`
+	MinOrderAmount float64    `json:"minOrderAmount"`
+	StartsAt       *time.Time `json:"startsAt,omitempty"`
+	EndsAt         *time.Time `json:"endsAt,omitempty"`
+	UsageLimit     *int       `json:"usageLimit,omitempty"`
+	PerUserLimit   *int       `json:"perUserLimit,omitempty"`
+	IsActive       bool       `json:"isActive"`
+	UsageCount     int        `json:"usageCount"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+func NewResponseModel(discount *entity.Discount) *ResponseModel {
+	return &ResponseModel{
+		Id:             discount.Id,
+		Name:           discount.Name,
+		Code:           discount.Code,
+		Kind:           discount.Kind,
+		Value:          discount.Value,
+		Scope:          discount.Scope,
+		TargetId:       discount.TargetId,
+		MinOrderAmount: discount.MinOrderAmount,
+		StartsAt:       discount.StartsAt,
+		EndsAt:         discount.EndsAt,
+		UsageLimit:     discount.UsageLimit,
+		PerUserLimit:   discount.PerUserLimit,
+		IsActive:       discount.IsActive,
+		UsageCount:     discount.UsageCount,
+		CreatedAt:      discount.CreatedAt,
+		UpdatedAt:      discount.UpdatedAt,
+	}
+}
+
+type RedemptionResponseModel struct {
+	OrderId      uuid.UUID  `json:"orderId"`
+	OrderNumber  uint64     `json:"orderNumber"`
+	UserId       *uuid.UUID `json:"userId,omitempty"`
+	CustomerName string     `json:"customerName,omitempty"`
+	Amount       float64    `json:"amount"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+func NewRedemptionResponseModel(redemption *entity.DiscountRedemption) *RedemptionResponseModel {
+	return &RedemptionResponseModel{
+		OrderId:      redemption.OrderId,
+		OrderNumber:  redemption.OrderNumber,
+		UserId:       redemption.UserId,
+		CustomerName: redemption.CustomerName,
+		Amount:       redemption.Amount,
+		CreatedAt:    redemption.CreatedAt,
+	}
+}
+
+type ReportResponseModel struct {
+	DiscountId  uuid.UUID `json:"discountId"`
+	Name        string    `json:"name"`
+	Code        string    `json:"code,omitempty"`
+	Redemptions int       `json:"redemptions"`
+	Amount      float64   `json:"amount"`
+}
+
+func NewReportResponseModel(report *entity.DiscountReport) *ReportResponseModel {
+	return &ReportResponseModel{
+		DiscountId:  report.DiscountId,
+		Name:        report.Name,
+		Code:        report.Code,
+		Redemptions: report.Redemptions,
+		Amount:      report.Amount,
+	}
+}
@@ -0,0 +1,50 @@
+package eventSchemas
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/schemas/teaSchemas"
+	"net/http"
+	"strconv"
+)
+
+type Filters struct {
+	Limit      uint64    `db:"limit"`
+	Page       uint64    `db:"page"`
+	Offset     uint64    `db:"offset"`
+	IsPast     bool      `db:"is_past"`
+	LocationId uuid.UUID `db:"location_id"`
+	UserId     uuid.UUID `db:"user_id"`
+}
+
+func (f *Filters) Validate(r *http.Request) error {
+	query := r.URL.Query()
+	limit, err := strconv.ParseUint(query.Get("limit"), 10, 64)
+	if err != nil {
+		limit = 20
+	}
+	page, err := strconv.ParseUint(query.Get("page"), 10, 64)
+	if err != nil {
+		page = 1
+	}
+
+	if page == 0 {
+		return fmt.Errorf("the page can not be equal to 0")
+	}
+
+	isPast, err := strconv.ParseBool(query.Get("isPast"))
+	if err != nil {
+		isPast = false
+	}
+
+	locationId, err := teaSchemas.ParseLocationId(r)
+	if err != nil {
+		return err
+	}
+
+	f.Limit = limit
+	f.Page = page
+	f.IsPast = isPast
+	f.LocationId = locationId
+	return nil
+}
@@ -0,0 +1,56 @@
+package eventSchemas
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type RequestModel struct {
+	Title       string      `json:"title"`
+	Description string      `json:"description,omitempty"`
+	StartsAt    time.Time   `json:"startsAt"`
+	EndsAt      time.Time   `json:"endsAt"`
+	Capacity    int         `json:"capacity"`
+	Price       float64     `json:"price"`
+	LocationId  *uuid.UUID  `json:"locationId,omitempty"`
+	TeaIds      []uuid.UUID `json:"teaIds"`
+}
+
+func (rm *RequestModel) Bind(r *http.Request) error {
+	rm.Title = strings.TrimSpace(rm.Title)
+	rm.Description = strings.TrimSpace(rm.Description)
+	if rm.Title == "" {
+		return fmt.Errorf("title is a required field")
+	}
+	if utf8.RuneCountInString(rm.Title) > 128 {
+		return fmt.Errorf("title must be at most 128 characters long")
+	}
+	if utf8.RuneCountInString(rm.Description) > 2000 {
+		return fmt.Errorf("description must be at most 2000 characters long")
+	}
+	if rm.StartsAt.IsZero() {
+		return fmt.Errorf("startsAt is a required field")
+	}
+	if !rm.EndsAt.After(rm.StartsAt) {
+		return fmt.Errorf("endsAt must be after startsAt")
+	}
+	if rm.Capacity <= 0 {
+		return fmt.Errorf("capacity must be greater than 0")
+	}
+	if rm.Price < 0 {
+		return fmt.Errorf("price can not be negative")
+	}
+
+	seen := make(map[uuid.UUID]struct{}, len(rm.TeaIds))
+	for _, teaId := range rm.TeaIds {
+		if _, ok := seen[teaId]; ok {
+			return fmt.Errorf("tea with id %s is duplicated", teaId.String())
+		}
+		seen[teaId] = struct{}{}
+	}
+	return nil
+}
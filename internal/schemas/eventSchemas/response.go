@@ -0,0 +1,89 @@
+package eventSchemas
+
+import (
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"time"
+)
+
+type TeaResponseModel struct {
+	TeaId   uuid.UUID `json:"teaId"`
+	TeaName string    `json:"teaName"`
+}
+
+type LocationResponseModel struct {
+	Id      uuid.UUID `json:"id"`
+	Name    string    `json:"name"`
+	Address string    `json:"address,omitempty"`
+}
+
+type ResponseModel struct {
+	Id                 uuid.UUID              `json:"id"`
+	Title              string                 `json:"title"`
+	Description        string                 `json:"description,omitempty"`
+	StartsAt           time.Time              `json:"startsAt"`
+	EndsAt             time.Time              `json:"endsAt"`
+	Capacity           int                    `json:"capacity"`
+	Price              float64                `json:"price"`
+	Location           *LocationResponseModel `json:"location,omitempty"`
+	RegisteredCount    int                    `json:"registeredCount"`
+	WaitlistedCount    int                    `json:"waitlistedCount"`
+	SpotsLeft          int                    `json:"spotsLeft"`
+	RegistrationStatus string                 `json:"registrationStatus,omitempty" enums:"registered,waitlisted"`
+	Teas               []*TeaResponseModel    `json:"teas"`
+}
+
+func NewResponseModel(event *entity.Event) *ResponseModel {
+	teas := make([]*TeaResponseModel, len(event.Teas))
+	for i := range event.Teas {
+		teas[i] = &TeaResponseModel{
+			TeaId:   event.Teas[i].TeaId,
+			TeaName: event.Teas[i].TeaName,
+		}
+	}
+
+	var location *LocationResponseModel
+	if event.LocationId != nil {
+		location = &LocationResponseModel{
+			Id:      *event.LocationId,
+			Name:    event.LocationName,
+			Address: event.LocationAddress,
+		}
+	}
+
+	return &ResponseModel{
+		Id:                 event.Id,
+		Title:              event.Title,
+		Description:        event.Description,
+		StartsAt:           event.StartsAt,
+		EndsAt:             event.EndsAt,
+		Capacity:           event.Capacity,
+		Price:              event.Price,
+		Location:           location,
+		RegisteredCount:    event.RegisteredCount,
+		WaitlistedCount:    event.WaitlistedCount,
+		SpotsLeft:          event.SpotsLeft(),
+		RegistrationStatus: event.RegistrationStatus,
+		Teas:               teas,
+	}
+}
+
+type AttendeeResponseModel struct {
+	UserId    uuid.UUID `json:"userId"`
+	FirstName string    `json:"firstName"`
+	LastName  string    `json:"lastName,omitempty"`
+	Username  string    `json:"username,omitempty"`
+	Status    string    `json:"status" enums:"registered,waitlisted"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func NewAttendeeResponseModel(attendee *entity.EventAttendee) *AttendeeResponseModel {
+	return &AttendeeResponseModel{
+		UserId:    attendee.UserId,
+		FirstName: attendee.FirstName,
+		LastName:  attendee.LastName,
+		Username:  attendee.Username,
+		Status:    attendee.Status,
+		CreatedAt: attendee.CreatedAt,
+	}
+}
@@ -0,0 +1,27 @@
+package guestSchemas
+
+import (
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"time"
+)
+
+type TokenResponse struct {
+	GuestToken string `json:"guestToken"`
+}
+
+type MarkResponseModel struct {
+	TeaId     uuid.UUID `json:"teaId"`
+	TeaName   string    `json:"teaName"`
+	Mark      string    `json:"mark" enums:"favourite,want_to_try"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func NewMarkResponseModel(mark *entity.GuestTeaMark) *MarkResponseModel {
+	return &MarkResponseModel{
+		TeaId:     mark.TeaId,
+		TeaName:   mark.TeaName,
+		Mark:      mark.Mark,
+		CreatedAt: mark.CreatedAt,
+	}
+}
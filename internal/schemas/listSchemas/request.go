@@ -0,0 +1,62 @@
+package listSchemas
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"net/http"
+	"strings"
+)
+
+type RequestModel struct {
+	Name string `json:"name"`
+}
+
+func (rm *RequestModel) Bind(r *http.Request) error {
+	rm.Name = strings.TrimSpace(rm.Name)
+	if rm.Name == "" {
+		return fmt.Errorf("name is a required field")
+	}
+	if len(rm.Name) > 255 {
+		return fmt.Errorf("name must be at most 255 characters long")
+	}
+	return nil
+}
+
+type ItemRequestModel struct {
+	TeaId uuid.UUID `json:"teaId"`
+	Note  string    `json:"note,omitempty"`
+}
+
+func (rm *ItemRequestModel) Bind(r *http.Request) error {
+	if rm.TeaId == uuid.Nil {
+		return fmt.Errorf("teaId is a required field")
+	}
+	return nil
+}
+
+type ItemNoteRequestModel struct {
+	Note string `json:"note"`
+}
+
+func (rm *ItemNoteRequestModel) Bind(r *http.Request) error {
+	return nil
+}
+
+type OrderRequestModel struct {
+	TeaIds []uuid.UUID `json:"teaIds"`
+}
+
+func (rm *OrderRequestModel) Bind(r *http.Request) error {
+	if len(rm.TeaIds) == 0 {
+		return fmt.Errorf("teaIds is a required field")
+	}
+
+	seen := make(map[uuid.UUID]struct{}, len(rm.TeaIds))
+	for _, teaId := range rm.TeaIds {
+		if _, ok := seen[teaId]; ok {
+			return fmt.Errorf("tea with id %s is duplicated", teaId.String())
+		}
+		seen[teaId] = struct{}{}
+	}
+	return nil
+}
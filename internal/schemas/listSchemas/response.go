@@ -0,0 +1,111 @@
+package listSchemas
+
+import (
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/schemas/teaSchemas"
+	"time"
+)
+
+type ResponseModel struct {
+	Id          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	IsFavourite bool      `json:"isFavourite,omitempty"`
+	ShareToken  string    `json:"shareToken,omitempty"`
+	ItemsCount  uint64    `json:"itemsCount"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func NewResponseModel(list *entity.TeaList) *ResponseModel {
+	return &ResponseModel{
+		Id:          list.Id,
+		Name:        list.Name,
+		IsFavourite: list.IsFavourite,
+		ShareToken:  list.ShareToken,
+		ItemsCount:  list.ItemsCount,
+		CreatedAt:   list.CreatedAt,
+		UpdatedAt:   list.UpdatedAt,
+	}
+}
+
+type ItemResponseModel struct {
+	TeaId     uuid.UUID `json:"teaId"`
+	TeaName   string    `json:"teaName"`
+	Position  int       `json:"position"`
+	Note      string    `json:"note,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func NewItemResponseModel(item *entity.TeaListItem) *ItemResponseModel {
+	return &ItemResponseModel{
+		TeaId:     item.TeaId,
+		TeaName:   item.TeaName,
+		Position:  item.Position,
+		Note:      item.Note,
+		CreatedAt: item.CreatedAt,
+	}
+}
+
+type WithItemsResponseModel struct {
+	ResponseModel
+	Items []*ItemResponseModel `json:"items"`
+}
+
+func NewWithItemsResponseModel(list *entity.TeaList) *WithItemsResponseModel {
+	items := make([]*ItemResponseModel, len(list.Items))
+	for i := range list.Items {
+		items[i] = NewItemResponseModel(&list.Items[i])
+	}
+	return &WithItemsResponseModel{
+		ResponseModel: *NewResponseModel(list),
+		Items:         items,
+	}
+}
+
+type ShareResponseModel struct {
+	Token string `json:"token"`
+	Link  string `json:"link,omitempty"`
+}
+
+func NewShareResponseModel(token, link string) *ShareResponseModel {
+	return &ShareResponseModel{
+		Token: token,
+		Link:  link,
+	}
+}
+
+type SharedItemResponseModel struct {
+	Tea      *teaSchemas.ResponseModel `json:"tea"`
+	Position int                       `json:"position"`
+	Note     string                    `json:"note,omitempty"`
+}
+
+type SharedResponseModel struct {
+	Name        string                     `json:"name"`
+	IsFavourite bool                       `json:"isFavourite,omitempty"`
+	OwnerName   string                     `json:"ownerName,omitempty"`
+	Link        string                     `json:"link,omitempty"`
+	Items       []*SharedItemResponseModel `json:"items"`
+}
+
+func NewSharedResponseModel(list *entity.TeaList, link string) *SharedResponseModel {
+	items := make([]*SharedItemResponseModel, 0, len(list.Items))
+	for _, item := range list.Items {
+		if item.Tea == nil {
+			continue
+		}
+		items = append(items, &SharedItemResponseModel{
+			Tea:      teaSchemas.NewTeaResponseModel(item.Tea),
+			Position: item.Position,
+			Note:     item.Note,
+		})
+	}
+	return &SharedResponseModel{
+		Name:        list.Name,
+		IsFavourite: list.IsFavourite,
+		OwnerName:   list.OwnerName,
+		Link:        link,
+		Items:       items,
+	}
+}
@@ -0,0 +1,75 @@
+package locationSchemas
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+type RequestModel struct {
+	Name    string `json:"name"`
+	Address string `json:"address,omitempty"`
+}
+
+func (rm *RequestModel) Bind(r *http.Request) error {
+	rm.Name = strings.TrimSpace(rm.Name)
+	rm.Address = strings.TrimSpace(rm.Address)
+	if rm.Name == "" {
+		return fmt.Errorf("name is a required field")
+	}
+	if utf8.RuneCountInString(rm.Name) > 64 {
+		return fmt.Errorf("name must be at most 64 characters long")
+	}
+	if utf8.RuneCountInString(rm.Address) > 256 {
+		return fmt.Errorf("address must be at most 256 characters long")
+	}
+	return nil
+}
+
+// VisibilityRequestModel overrides the visibility of the tea in the location.
+// Null falls back to the catalogue value, as in the other override models.
+type VisibilityRequestModel struct {
+	IsHidden *bool `json:"isHidden"`
+}
+
+func (rm *VisibilityRequestModel) Bind(r *http.Request) error {
+	return nil
+}
+
+type StockRequestModel struct {
+	Stock *float64 `json:"stock"`
+}
+
+func (rm *StockRequestModel) Bind(r *http.Request) error {
+	if rm.Stock != nil && *rm.Stock < 0 {
+		return fmt.Errorf("stock can not be negative")
+	}
+	return nil
+}
+
+type PricesRequestModel struct {
+	ServePrice *float64 `json:"servePrice"`
+	UnitPrice  *float64 `json:"unitPrice"`
+}
+
+func (rm *PricesRequestModel) Bind(r *http.Request) error {
+	if rm.ServePrice != nil && *rm.ServePrice < 0 {
+		return fmt.Errorf("servePrice can not be negative")
+	}
+	if rm.UnitPrice != nil && *rm.UnitPrice < 0 {
+		return fmt.Errorf("unitPrice can not be negative")
+	}
+	return nil
+}
+
+// UserLocationRequestModel scopes the user to the location. Null lifts the
+// scope.
+type UserLocationRequestModel struct {
+	LocationId *uuid.UUID `json:"locationId"`
+}
+
+func (rm *UserLocationRequestModel) Bind(r *http.Request) error {
+	return nil
+}
@@ -0,0 +1,44 @@
+package locationSchemas
+
+import (
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+)
+
+type ResponseModel struct {
+	Id        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Address   string    `json:"address,omitempty"`
+	IsDefault bool      `json:"isDefault"`
+}
+
+func NewResponseModel(location *entity.Location) *ResponseModel {
+	return &ResponseModel{
+		Id:        location.Id,
+		Name:      location.Name,
+		Address:   location.Address,
+		IsDefault: location.IsDefault,
+	}
+}
+
+// OverrideResponseModel shows only the overridden values, nulls fall back
+// to the catalogue.
+type OverrideResponseModel struct {
+	TeaId      uuid.UUID `json:"teaId"`
+	LocationId uuid.UUID `json:"locationId"`
+	IsHidden   *bool     `json:"isHidden"`
+	ServePrice *float64  `json:"servePrice"`
+	UnitPrice  *float64  `json:"unitPrice"`
+	Stock      *float64  `json:"stock"`
+}
+
+func NewOverrideResponseModel(override *entity.TeaLocationOverride) *OverrideResponseModel {
+	return &OverrideResponseModel{
+		TeaId:      override.TeaId,
+		LocationId: override.LocationId,
+		IsHidden:   override.IsHidden,
+		ServePrice: override.ServePrice,
+		UnitPrice:  override.UnitPrice,
+		Stock:      override.Stock,
+	}
+}
@@ -0,0 +1,33 @@
+package loyaltySchemas
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+type Filters struct {
+	Limit  uint64 `db:"limit"`
+	Page   uint64 `db:"page"`
+	Offset uint64 `db:"offset"`
+}
+
+func (f *Filters) Validate(r *http.Request) error {
+	query := r.URL.Query()
+	limit, err := strconv.ParseUint(query.Get("limit"), 10, 64)
+	if err != nil {
+		limit = 20
+	}
+	page, err := strconv.ParseUint(query.Get("page"), 10, 64)
+	if err != nil {
+		page = 1
+	}
+
+	if page == 0 {
+		return fmt.Errorf("the page can not be equal to 0")
+	}
+
+	f.Limit = limit
+	f.Page = page
+	return nil
+}
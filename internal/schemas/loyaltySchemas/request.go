@@ -0,0 +1,44 @@
+package loyaltySchemas
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// AdjustmentRequestModel adds points to the balance or takes them with a
+// negative value.
+type AdjustmentRequestModel struct {
+	Points int    `json:"points"`
+	Reason string `json:"reason"`
+}
+
+func (rm *AdjustmentRequestModel) Bind(r *http.Request) error {
+	rm.Reason = strings.TrimSpace(rm.Reason)
+	if rm.Points == 0 {
+		return fmt.Errorf("points can not be equal to 0")
+	}
+	if rm.Reason == "" {
+		return fmt.Errorf("reason is a required field")
+	}
+	if utf8.RuneCountInString(rm.Reason) > 256 {
+		return fmt.Errorf("reason must be at most 256 characters long")
+	}
+	return nil
+}
+
+type RuleRequestModel struct {
+	ServePoints   int     `json:"servePoints"`
+	AmountPercent float64 `json:"amountPercent"`
+}
+
+func (rm *RuleRequestModel) Bind(r *http.Request) error {
+	if rm.ServePoints < 0 {
+		return fmt.Errorf("servePoints can not be negative")
+	}
+	if rm.AmountPercent < 0 || rm.AmountPercent > 100 {
+		return fmt.Errorf("amountPercent must be between 0 and 100")
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package loyaltySchemas
+
+import (
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"time"
+)
+
+type BalanceResponseModel struct {
+	Balance int `json:"balance"`
+	// PointValue is the discount one point gives, zero if points can not be
+	// redeemed.
+	PointValue float64 `json:"pointValue"`
+}
+
+func NewBalanceResponseModel(balance *entity.LoyaltyBalance) *BalanceResponseModel {
+	return &BalanceResponseModel{
+		Balance:    balance.Balance,
+		PointValue: balance.PointValue,
+	}
+}
+
+type TransactionResponseModel struct {
+	Id          uuid.UUID  `json:"id"`
+	OrderId     *uuid.UUID `json:"orderId,omitempty"`
+	OrderNumber *uint64    `json:"orderNumber,omitempty"`
+	Kind        string     `json:"kind" enums:"earn,redeem,refund,adjust"`
+	Points      int        `json:"points"`
+	Reason      string     `json:"reason,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+func NewTransactionResponseModel(transaction *entity.LoyaltyTransaction) *TransactionResponseModel {
+	return &TransactionResponseModel{
+		Id:          transaction.Id,
+		OrderId:     transaction.OrderId,
+		OrderNumber: transaction.OrderNumber,
+		Kind:        transaction.Kind,
+		Points:      transaction.Points,
+		Reason:      transaction.Reason,
+		CreatedAt:   transaction.CreatedAt,
+	}
+}
+
+type RuleResponseModel struct {
+	CategoryId    uuid.UUID `json:"categoryId"`
+	CategoryName  string    `json:"categoryName"`
+	ServePoints   int       `json:"servePoints"`
+	AmountPercent float64   `json:"amountPercent"`
+}
+
+func NewRuleResponseModel(rule *entity.LoyaltyRule) *RuleResponseModel {
+	return &RuleResponseModel{
+		CategoryId:    rule.CategoryId,
+		CategoryName:  rule.CategoryName,
+		ServePoints:   rule.ServePoints,
+		AmountPercent: rule.AmountPercent,
+	}
+}
@@ -0,0 +1,88 @@
+package orderSchemas
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"math"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxItemQuantity = 100
+
+// CartItemRequestModel can set the location of an empty cart, the default
+// location is used without it.
+type CartItemRequestModel struct {
+	TeaId      uuid.UUID  `json:"teaId"`
+	Kind       string     `json:"kind" enums:"serve,weight"`
+	Quantity   float64    `json:"quantity"`
+	LocationId *uuid.UUID `json:"locationId,omitempty"`
+}
+
+func (rm *CartItemRequestModel) Bind(r *http.Request) error {
+	if rm.TeaId == uuid.Nil {
+		return fmt.Errorf("teaId is a required field")
+	}
+	if rm.Kind != entity.OrderItemKindServe && rm.Kind != entity.OrderItemKindWeight {
+		return fmt.Errorf("kind must be %s or %s", entity.OrderItemKindServe, entity.OrderItemKindWeight)
+	}
+	return ValidateQuantity(rm.Kind, rm.Quantity)
+}
+
+type CartItemQuantityRequestModel struct {
+	Quantity float64 `json:"quantity"`
+}
+
+func (rm *CartItemQuantityRequestModel) Bind(r *http.Request) error {
+	if rm.Quantity <= 0 || rm.Quantity > maxItemQuantity {
+		return fmt.Errorf("quantity must be greater than 0 and at most %d", maxItemQuantity)
+	}
+	return nil
+}
+
+// ValidateQuantity checks the quantity against the kind of the cart item.
+// Serves are counted in whole numbers, weight in units of the tea.
+func ValidateQuantity(kind string, quantity float64) error {
+	if quantity <= 0 || quantity > maxItemQuantity {
+		return fmt.Errorf("quantity must be greater than 0 and at most %d", maxItemQuantity)
+	}
+	if kind == entity.OrderItemKindServe && quantity != math.Trunc(quantity) {
+		return fmt.Errorf("quantity of serves must be a whole number")
+	}
+	if math.Abs(quantity*100-math.Round(quantity*100)) > 1e-6 {
+		return fmt.Errorf("quantity can have at most 2 decimal places")
+	}
+	return nil
+}
+
+type OrderRequestModel struct {
+	Comment      string `json:"comment,omitempty"`
+	RedeemPoints int    `json:"redeemPoints,omitempty"`
+}
+
+func (rm *OrderRequestModel) Bind(r *http.Request) error {
+	rm.Comment = strings.TrimSpace(rm.Comment)
+	if utf8.RuneCountInString(rm.Comment) > 500 {
+		return fmt.Errorf("comment must be at most 500 characters long")
+	}
+	if rm.RedeemPoints < 0 {
+		return fmt.Errorf("redeemPoints can not be negative")
+	}
+	return nil
+}
+
+type StatusRequestModel struct {
+	Status string `json:"status" enums:"accepted,brewing,ready,served,cancelled"`
+}
+
+func (rm *StatusRequestModel) Bind(r *http.Request) error {
+	if rm.Status == "" {
+		return fmt.Errorf("status is a required field")
+	}
+	if !entity.IsOrderStatus(rm.Status) {
+		return fmt.Errorf("unknown status %s", rm.Status)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package orderSchemas
+
+import (
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"time"
+)
+
+type CartItemResponseModel struct {
+	Id           uuid.UUID `json:"id"`
+	TeaId        uuid.UUID `json:"teaId"`
+	TeaName      string    `json:"teaName"`
+	Kind         string    `json:"kind"`
+	Quantity     float64   `json:"quantity"`
+	Price        float64   `json:"price"`
+	Amount       float64   `json:"amount"`
+	PriceChanged bool      `json:"priceChanged,omitempty"`
+	IsAvailable  bool      `json:"isAvailable"`
+}
+
+type CartDiscountResponseModel struct {
+	DiscountId uuid.UUID `json:"discountId"`
+	Name       string    `json:"name"`
+	Code       string    `json:"code,omitempty"`
+	Amount     float64   `json:"amount"`
+}
+
+// CartResponseModel has the items at the list prices of the location of the
+// cart. The total is what the order costs after the discounts. The promo code
+// is kept even if it does not apply to the cart any more.
+type CartResponseModel struct {
+	LocationId *uuid.UUID                   `json:"locationId,omitempty"`
+	Items      []*CartItemResponseModel     `json:"items"`
+	PromoCode  string                       `json:"promoCode,omitempty"`
+	Subtotal   float64                      `json:"subtotal"`
+	Discounts  []*CartDiscountResponseModel `json:"discounts"`
+	Discount   float64                      `json:"discount"`
+	Total      float64                      `json:"total"`
+}
+
+func NewCartResponseModel(cart *entity.Cart) *CartResponseModel {
+	response := &CartResponseModel{
+		Items:     make([]*CartItemResponseModel, len(cart.Items)),
+		PromoCode: cart.PromoCode,
+		Subtotal:  cart.Subtotal,
+		Discounts: make([]*CartDiscountResponseModel, len(cart.Discounts)),
+		Discount:  cart.Discount,
+		Total:     cart.Total,
+	}
+	if len(cart.Items) > 0 {
+		response.LocationId = &cart.Items[0].LocationId
+	}
+	for i := range cart.Items {
+		item := &cart.Items[i]
+		response.Items[i] = &CartItemResponseModel{
+			Id:           item.Id,
+			TeaId:        item.TeaId,
+			TeaName:      item.TeaName,
+			Kind:         item.Kind,
+			Quantity:     item.Quantity,
+			Price:        item.Price,
+			Amount:       item.Amount(),
+			PriceChanged: item.Price != item.CurrentPrice,
+			IsAvailable:  !item.IsHidden && (item.Stock == nil || *item.Stock > 0),
+		}
+	}
+	for i := range cart.Discounts {
+		discount := &cart.Discounts[i]
+		response.Discounts[i] = &CartDiscountResponseModel{
+			DiscountId: discount.DiscountId,
+			Name:       discount.Name,
+			Code:       discount.Code,
+			Amount:     discount.Amount,
+		}
+	}
+	return response
+}
+
+type OrderItemResponseModel struct {
+	TeaId    *uuid.UUID `json:"teaId,omitempty"`
+	TeaName  string     `json:"teaName"`
+	Kind     string     `json:"kind"`
+	Quantity float64    `json:"quantity"`
+	Price    float64    `json:"price"`
+	Amount   float64    `json:"amount"`
+}
+
+type LocationResponseModel struct {
+	Id   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+type OrderResponseModel struct {
+	Id             uuid.UUID                 `json:"id"`
+	Number         uint64                    `json:"number"`
+	CustomerName   string                    `json:"customerName,omitempty"`
+	Location       *LocationResponseModel    `json:"location,omitempty"`
+	Status         string                    `json:"status" enums:"new,accepted,brewing,ready,served,cancelled"`
+	Total          float64                   `json:"total"`
+	Discount       float64                   `json:"discount,omitempty"`
+	RedeemedPoints int                       `json:"redeemedPoints,omitempty"`
+	Comment        string                    `json:"comment,omitempty"`
+	Items          []*OrderItemResponseModel `json:"items"`
+	CreatedAt      time.Time                 `json:"createdAt"`
+	UpdatedAt      time.Time                 `json:"updatedAt"`
+}
+
+func NewOrderResponseModel(order *entity.Order) *OrderResponseModel {
+	items := make([]*OrderItemResponseModel, len(order.Items))
+	for i := range order.Items {
+		item := &order.Items[i]
+		items[i] = &OrderItemResponseModel{
+			TeaId:    item.TeaId,
+			TeaName:  item.TeaName,
+			Kind:     item.Kind,
+			Quantity: item.Quantity,
+			Price:    item.Price,
+			Amount:   item.Amount,
+		}
+	}
+
+	var location *LocationResponseModel
+	if order.LocationId != nil {
+		location = &LocationResponseModel{
+			Id:   *order.LocationId,
+			Name: order.LocationName,
+		}
+	}
+
+	return &OrderResponseModel{
+		Id:             order.Id,
+		Number:         order.Number,
+		CustomerName:   order.CustomerName,
+		Location:       location,
+		Status:         order.Status,
+		Total:          order.Total,
+		Discount:       order.Discount,
+		RedeemedPoints: order.RedeemedPoints,
+		Comment:        order.Comment,
+		Items:          items,
+		CreatedAt:      order.CreatedAt,
+		UpdatedAt:      order.UpdatedAt,
+	}
+}
@@ -0,0 +1,46 @@
+package roleSchemas
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+var nameRegexp = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+type RequestModel struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Permissions []string `json:"permissions"`
+}
+
+func (rm *RequestModel) Bind(r *http.Request) error {
+	rm.Name = strings.TrimSpace(rm.Name)
+	if rm.Name == "" {
+		return fmt.Errorf("name is a required field")
+	}
+	if len(rm.Name) > 64 {
+		return fmt.Errorf("name must be at most 64 characters long")
+	}
+	if !nameRegexp.MatchString(rm.Name) {
+		return fmt.Errorf("name must contain only lowercase latin letters, digits and underscores")
+	}
+
+	uniquePermissions := make([]string, 0, len(rm.Permissions))
+	seen := make(map[string]struct{}, len(rm.Permissions))
+	for _, permission := range rm.Permissions {
+		permission = strings.TrimSpace(permission)
+		if permission == "" {
+			return fmt.Errorf("permissions can not contain empty values")
+		}
+		if _, ok := seen[permission]; ok {
+			continue
+		}
+		seen[permission] = struct{}{}
+		uniquePermissions = append(uniquePermissions, permission)
+	}
+	rm.Permissions = uniquePermissions
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package roleSchemas
+
+import (
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+)
+
+type PermissionResponseModel struct {
+	Code        string `json:"code"`
+	Description string `json:"description,omitempty"`
+}
+
+func NewPermissionResponseModel(permission *entity.Permission) *PermissionResponseModel {
+	return &PermissionResponseModel{
+		Code:        permission.Code,
+		Description: permission.Description,
+	}
+}
+
+type ResponseModel struct {
+	Id          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	IsSystem    bool      `json:"isSystem"`
+	Permissions []string  `json:"permissions"`
+}
+
+func NewRoleResponseModel(role *entity.Role) *ResponseModel {
+	permissions := role.Permissions
+	if permissions == nil {
+		permissions = make([]string, 0)
+	}
+
+	return &ResponseModel{
+		Id:          role.Id,
+		Name:        role.Name,
+		Description: role.Description,
+		IsSystem:    role.IsSystem,
+		Permissions: permissions,
+	}
+}
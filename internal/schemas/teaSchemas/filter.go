@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"sort"
 	"strconv"
+	"strings"
 )
 
 type Filters struct {
@@ -23,6 +24,8 @@ type Filters struct {
 	IsOnlyHidden    bool         `json:"isOnlyHidden,omitempty" db:"is_hidden"`
 	UserId          uuid.UUID    `db:"user_id"`
 	IsOnlyFavourite bool         `json:"isOnlyFavourite,omitempty"`
+	ListId          uuid.UUID    `json:"listId,omitempty" db:"list_id"`
+	LocationId      uuid.UUID    `json:"locationId,omitempty" db:"location_id"`
 }
 
 func NewFilters() *Filters {
@@ -157,5 +160,78 @@ func (tf *Filters) Validate(r *http.Request) error {
 	}
 	tf.IsOnlyFavourite = isOnlyFavourite
 
+	listIdStr := query.Get("listId")
+	if listIdStr != "" {
+		listId, err := uuid.Parse(listIdStr)
+		if err != nil {
+			return fmt.Errorf("invalid list id: %s", listIdStr)
+		}
+		tf.ListId = listId
+	}
+
+	locationId, err := ParseLocationId(r)
+	if err != nil {
+		return err
+	}
+	tf.LocationId = locationId
+
+	return nil
+}
+
+// ParseLocationId reads the optional locationId query parameter.
+func ParseLocationId(r *http.Request) (uuid.UUID, error) {
+	locationIdStr := r.URL.Query().Get("locationId")
+	if locationIdStr == "" {
+		return uuid.Nil, nil
+	}
+
+	locationId, err := uuid.Parse(locationIdStr)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid location id: %s", locationIdStr)
+	}
+	return locationId, nil
+}
+
+const MaxComparedTeas = 5
+
+type CompareFilters struct {
+	Ids    []uuid.UUID
+	UserId uuid.UUID
+}
+
+func (cf *CompareFilters) Validate(r *http.Request) error {
+	query := r.URL.Query()
+
+	rawIds := make([]string, 0)
+	for _, value := range append(query["ids"], query["ids[]"]...) {
+		rawIds = append(rawIds, strings.Split(value, ",")...)
+	}
+
+	ids := make([]uuid.UUID, 0, len(rawIds))
+	seen := make(map[uuid.UUID]struct{}, len(rawIds))
+	for _, rawId := range rawIds {
+		rawId = strings.TrimSpace(rawId)
+		if rawId == "" {
+			continue
+		}
+		id, err := uuid.Parse(rawId)
+		if err != nil {
+			return fmt.Errorf("invalid tea id: %s", rawId)
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		ids = append(ids, id)
+	}
+
+	if len(ids) < 2 {
+		return fmt.Errorf("at least 2 teas are required for comparison")
+	}
+	if len(ids) > MaxComparedTeas {
+		return fmt.Errorf("at most %d teas can be compared", MaxComparedTeas)
+	}
+
+	cf.Ids = ids
 	return nil
 }
@@ -48,3 +48,25 @@ func (e *Evaluation) Bind(r *http.Request) error {
 	}
 	return nil
 }
+
+type VisibilityRequestModel struct {
+	IsHidden *bool `json:"isHidden"`
+}
+
+func (v *VisibilityRequestModel) Bind(r *http.Request) error {
+	if v.IsHidden == nil {
+		return fmt.Errorf("isHidden is a required field")
+	}
+	return nil
+}
+
+type StockRequestModel struct {
+	Stock *float64 `json:"stock"`
+}
+
+func (sr *StockRequestModel) Bind(r *http.Request) error {
+	if sr.Stock != nil && *sr.Stock < 0 {
+		return fmt.Errorf("stock can not be negative")
+	}
+	return nil
+}
@@ -1,9 +1,14 @@
 package teaSchemas
 
 import (
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/levchenki/tea-api/internal/entity"
 	"github.com/levchenki/tea-api/internal/schemas"
+	"github.com/levchenki/tea-api/internal/schemas/categorySchemas"
+	"github.com/levchenki/tea-api/internal/schemas/unitSchemas"
+	"sort"
+	"strings"
 )
 
 type ResponseModel struct {
@@ -16,6 +21,7 @@ type ResponseModel struct {
 	UnitId      uuid.UUID    `json:"unitId"`
 	Tags        []entity.Tag `json:"tags,omitempty"`
 	IsHidden    bool         `json:"isHidden,omitempty"`
+	Stock       *float64     `json:"stock,omitempty"`
 }
 
 func NewTeaResponseModel(tea *entity.Tea) *ResponseModel {
@@ -37,6 +43,7 @@ func NewTeaResponseModel(tea *entity.Tea) *ResponseModel {
 	if tea.IsHidden {
 		r.IsHidden = tea.IsHidden
 	}
+	r.Stock = tea.Stock
 	return r
 }
 
@@ -77,6 +84,7 @@ func NewTeaWithRatingResponseModel(tea *entity.TeaWithRating) *WithRatingRespons
 	if tea.IsHidden {
 		t.IsHidden = tea.IsHidden
 	}
+	t.Stock = tea.Stock
 	if tea.IsFavourite {
 		t.IsFavourite = tea.IsFavourite
 	}
@@ -112,3 +120,117 @@ func NewTeaPricesPaginatedResult[T any](teaResponse []T, total uint64, min, max
 		},
 	}
 }
+
+type CompareItemResponseModel struct {
+	Id            uuid.UUID                      `json:"id"`
+	Name          string                         `json:"name"`
+	Category      *categorySchemas.ResponseModel `json:"category,omitempty"`
+	Tags          []entity.Tag                   `json:"tags"`
+	ServePrice    float64                        `json:"servePrice"`
+	UnitPrice     float64                        `json:"unitPrice"`
+	Unit          *unitSchemas.ResponseModel     `json:"unit,omitempty"`
+	PricePer100g  *float64                       `json:"pricePer100g"`
+	AverageRating float64                        `json:"averageRating"`
+	Rating        float64                        `json:"rating,omitempty"`
+	Note          string                         `json:"note,omitempty"`
+	IsFavourite   bool                           `json:"isFavourite,omitempty"`
+}
+
+type CompareResponseModel struct {
+	Items           []*CompareItemResponseModel `json:"items"`
+	DifferentFields []string                    `json:"differentFields"`
+}
+
+func NewCompareResponseModel(teas []entity.TeaComparison) *CompareResponseModel {
+	items := make([]*CompareItemResponseModel, len(teas))
+	for i := range teas {
+		items[i] = newCompareItemResponseModel(&teas[i])
+	}
+
+	fieldValues := map[string]func(item *CompareItemResponseModel) string{
+		"category": func(item *CompareItemResponseModel) string {
+			if item.Category == nil {
+				return ""
+			}
+			return item.Category.Id.String()
+		},
+		"tags": func(item *CompareItemResponseModel) string {
+			tagIds := make([]string, len(item.Tags))
+			for i, tag := range item.Tags {
+				tagIds[i] = tag.Id.String()
+			}
+			sort.Strings(tagIds)
+			return strings.Join(tagIds, ",")
+		},
+		"servePrice": func(item *CompareItemResponseModel) string {
+			return fmt.Sprintf("%.2f", item.ServePrice)
+		},
+		"unitPrice": func(item *CompareItemResponseModel) string {
+			return fmt.Sprintf("%.2f", item.UnitPrice)
+		},
+		"unit": func(item *CompareItemResponseModel) string {
+			if item.Unit == nil {
+				return ""
+			}
+			return item.Unit.Id.String()
+		},
+		"pricePer100g": func(item *CompareItemResponseModel) string {
+			if item.PricePer100g == nil {
+				return ""
+			}
+			return fmt.Sprintf("%.2f", *item.PricePer100g)
+		},
+		"averageRating": func(item *CompareItemResponseModel) string {
+			return fmt.Sprintf("%.2f", item.AverageRating)
+		},
+		"rating": func(item *CompareItemResponseModel) string {
+			return fmt.Sprintf("%.2f", item.Rating)
+		},
+		"note": func(item *CompareItemResponseModel) string {
+			return item.Note
+		},
+	}
+
+	differentFields := make([]string, 0, len(fieldValues))
+	for field, value := range fieldValues {
+		for _, item := range items[1:] {
+			if value(item) != value(items[0]) {
+				differentFields = append(differentFields, field)
+				break
+			}
+		}
+	}
+	sort.Strings(differentFields)
+
+	return &CompareResponseModel{
+		Items:           items,
+		DifferentFields: differentFields,
+	}
+}
+
+func newCompareItemResponseModel(tea *entity.TeaComparison) *CompareItemResponseModel {
+	item := &CompareItemResponseModel{
+		Id:            tea.Id,
+		Name:          tea.Name,
+		Tags:          tea.Tags,
+		ServePrice:    tea.ServePrice,
+		UnitPrice:     tea.UnitPrice,
+		AverageRating: tea.AverageRating,
+		Rating:        tea.Rating,
+		Note:          tea.Note,
+		IsFavourite:   tea.IsFavourite,
+	}
+	if item.Tags == nil {
+		item.Tags = make([]entity.Tag, 0)
+	}
+	if tea.Category != nil {
+		item.Category = categorySchemas.NewResponseModel(tea.Category)
+	}
+	if tea.Unit != nil {
+		item.Unit = unitSchemas.NewResponseModel(tea.Unit)
+		if price, ok := tea.Unit.PricePer100Grams(tea.UnitPrice); ok {
+			item.PricePer100g = &price
+		}
+	}
+	return item
+}
@@ -0,0 +1,82 @@
+package userSchemas
+
+import (
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"time"
+)
+
+type UserResponseModel struct {
+	Id          uuid.UUID  `json:"id"`
+	TelegramId  uint64     `json:"telegramId,omitempty"`
+	FirstName   string     `json:"firstName"`
+	LastName    string     `json:"lastName,omitempty"`
+	Username    string     `json:"username,omitempty"`
+	PhotoUrl    string     `json:"photoUrl,omitempty"`
+	DisplayName string     `json:"displayName,omitempty"`
+	IsAdmin     bool       `json:"isAdmin"`
+	IsBlocked   bool       `json:"isBlocked"`
+	BlockedAt   *time.Time `json:"blockedAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+func NewUserResponseModel(user *entity.User) *UserResponseModel {
+	return &UserResponseModel{
+		Id:          user.Id,
+		TelegramId:  user.TelegramId,
+		FirstName:   user.FirstName,
+		LastName:    user.LastName,
+		Username:    user.Username,
+		PhotoUrl:    user.PhotoUrl,
+		DisplayName: user.DisplayName,
+		IsAdmin:     user.IsAdmin,
+		IsBlocked:   user.IsBlocked,
+		BlockedAt:   user.BlockedAt,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+	}
+}
+
+type EvaluationResponseModel struct {
+	TeaId     uuid.UUID `json:"teaId"`
+	TeaName   string    `json:"teaName"`
+	Rating    float64   `json:"rating"`
+	Note      string    `json:"note,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type ActivityStatisticsModel struct {
+	RatedCount      uint64 `json:"ratedCount"`
+	FavouritesCount uint64 `json:"favouritesCount"`
+	ListsCount      uint64 `json:"listsCount"`
+}
+
+type ActivityResponseModel struct {
+	User            *UserResponseModel         `json:"user"`
+	Statistics      ActivityStatisticsModel    `json:"statistics"`
+	LastEvaluations []*EvaluationResponseModel `json:"lastEvaluations"`
+}
+
+func NewActivityResponseModel(activity *entity.UserActivity) *ActivityResponseModel {
+	lastEvaluations := make([]*EvaluationResponseModel, len(activity.LastEvaluations))
+	for i, evaluation := range activity.LastEvaluations {
+		lastEvaluations[i] = &EvaluationResponseModel{
+			TeaId:     evaluation.TeaId,
+			TeaName:   evaluation.TeaName,
+			Rating:    evaluation.Rating,
+			Note:      evaluation.Note,
+			UpdatedAt: evaluation.UpdatedAt,
+		}
+	}
+
+	return &ActivityResponseModel{
+		User: NewUserResponseModel(activity.User),
+		Statistics: ActivityStatisticsModel{
+			RatedCount:      activity.Statistics.RatedCount,
+			FavouritesCount: activity.Statistics.FavouritesCount,
+			ListsCount:      activity.Statistics.ListsCount,
+		},
+		LastEvaluations: lastEvaluations,
+	}
+}
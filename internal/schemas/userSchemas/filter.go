@@ -0,0 +1,64 @@
+package userSchemas
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type Filters struct {
+	Limit      uint64 `db:"limit"`
+	Page       uint64 `db:"page"`
+	Offset     uint64 `db:"offset"`
+	Search     string `db:"search"`
+	TelegramId uint64 `db:"telegram_id"`
+	IsAdmin    *bool  `db:"is_admin"`
+	IsBlocked  *bool  `db:"is_blocked"`
+}
+
+func (f *Filters) Validate(r *http.Request) error {
+	query := r.URL.Query()
+	limit, err := strconv.ParseUint(query.Get("limit"), 10, 64)
+	if err != nil {
+		limit = 20
+	}
+	page, err := strconv.ParseUint(query.Get("page"), 10, 64)
+	if err != nil {
+		page = 1
+	}
+
+	if page == 0 {
+		return fmt.Errorf("the page can not be equal to 0")
+	}
+
+	f.Limit = limit
+	f.Page = page
+
+	search := strings.TrimPrefix(strings.TrimSpace(query.Get("search")), "@")
+	if search != "" {
+		telegramId, err := strconv.ParseUint(search, 10, 64)
+		if err == nil {
+			f.TelegramId = telegramId
+		}
+		f.Search = search
+	}
+
+	if isAdminStr := query.Get("isAdmin"); isAdminStr != "" {
+		isAdmin, err := strconv.ParseBool(isAdminStr)
+		if err != nil {
+			return fmt.Errorf("invalid isAdmin value: %s", isAdminStr)
+		}
+		f.IsAdmin = &isAdmin
+	}
+
+	if isBlockedStr := query.Get("isBlocked"); isBlockedStr != "" {
+		isBlocked, err := strconv.ParseBool(isBlockedStr)
+		if err != nil {
+			return fmt.Errorf("invalid isBlocked value: %s", isBlockedStr)
+		}
+		f.IsBlocked = &isBlocked
+	}
+
+	return nil
+}
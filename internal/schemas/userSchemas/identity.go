@@ -0,0 +1,92 @@
+package userSchemas
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type EmailLoginRequest struct {
+	Email string `json:"email"`
+}
+
+func (rm *EmailLoginRequest) Bind(r *http.Request) error {
+	rm.Email = strings.ToLower(strings.TrimSpace(rm.Email))
+	if rm.Email == "" {
+		return fmt.Errorf("email is required")
+	}
+
+	if len(rm.Email) > 255 {
+		return fmt.Errorf("email must be at most 255 characters long")
+	}
+
+	if match, _ := regexp.MatchString(`^[^@\s]+@[^@\s]+\.[^@\s]+$`, rm.Email); !match {
+		return fmt.Errorf("invalid email format")
+	}
+	return nil
+}
+
+type EmailAuthRequest struct {
+	Token string `json:"token"`
+}
+
+func (rm *EmailAuthRequest) Bind(r *http.Request) error {
+	if rm.Token == "" {
+		return fmt.Errorf("token is required")
+	}
+	return nil
+}
+
+type OIDCAuthRequest struct {
+	Code  string `json:"code"`
+	State string `json:"state"`
+}
+
+func (rm *OIDCAuthRequest) Bind(r *http.Request) error {
+	if rm.Code == "" || rm.State == "" {
+		return fmt.Errorf("code and state are required")
+	}
+	return nil
+}
+
+type OIDCAuthorizationResponse struct {
+	Url string `json:"url"`
+}
+
+// LinkIdentityRequest carries the same credentials the provider takes on
+// sign in, e.g. {"token": "..."} for email or {"initData": "..."} for the
+// mini app.
+type LinkIdentityRequest struct {
+	Credentials map[string]string `json:"credentials"`
+}
+
+func (rm *LinkIdentityRequest) Bind(r *http.Request) error {
+	if len(rm.Credentials) == 0 {
+		return fmt.Errorf("credentials are required")
+	}
+	return nil
+}
+
+type IdentityResponseModel struct {
+	Id         uuid.UUID `json:"id"`
+	Provider   string    `json:"provider"`
+	Subject    string    `json:"subject"`
+	Email      string    `json:"email,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+}
+
+func NewIdentityResponseModel(identity *entity.Identity) *IdentityResponseModel {
+	return &IdentityResponseModel{
+		Id:         identity.Id,
+		Provider:   identity.Provider,
+		Subject:    identity.Subject,
+		Email:      identity.Email,
+		CreatedAt:  identity.CreatedAt,
+		LastUsedAt: identity.LastUsedAt,
+	}
+}
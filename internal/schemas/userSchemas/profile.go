@@ -0,0 +1,78 @@
+package userSchemas
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type NotificationsModel struct {
+	Orders     bool `json:"orders"`
+	Favourites bool `json:"favourites"`
+}
+
+type ProfileRequestModel struct {
+	DisplayName   string             `json:"displayName"`
+	Language      string             `json:"language"`
+	Notifications NotificationsModel `json:"notifications"`
+}
+
+func (rm *ProfileRequestModel) Bind(r *http.Request) error {
+	rm.DisplayName = strings.TrimSpace(rm.DisplayName)
+	if len(rm.DisplayName) > 255 {
+		return fmt.Errorf("displayName must be at most 255 characters long")
+	}
+
+	rm.Language = strings.TrimSpace(rm.Language)
+	if rm.Language != "" {
+		if match, _ := regexp.MatchString("^[a-z]{2,3}(-[A-Za-z0-9]{2,8})?$", rm.Language); !match {
+			return fmt.Errorf("invalid language format")
+		}
+	}
+	return nil
+}
+
+type StatisticsModel struct {
+	RatedCount      uint64    `json:"ratedCount"`
+	FavouritesCount uint64    `json:"favouritesCount"`
+	MemberSince     time.Time `json:"memberSince"`
+}
+
+type ProfileResponseModel struct {
+	Id            uuid.UUID          `json:"id"`
+	FirstName     string             `json:"firstName"`
+	LastName      string             `json:"lastName,omitempty"`
+	Username      string             `json:"username,omitempty"`
+	PhotoUrl      string             `json:"photoUrl,omitempty"`
+	IsPremium     bool               `json:"isPremium"`
+	DisplayName   string             `json:"displayName,omitempty"`
+	Language      string             `json:"language,omitempty"`
+	Notifications NotificationsModel `json:"notifications"`
+	Statistics    StatisticsModel    `json:"statistics"`
+}
+
+func NewProfileResponseModel(user *entity.User, statistics *entity.UserStatistics) *ProfileResponseModel {
+	return &ProfileResponseModel{
+		Id:          user.Id,
+		FirstName:   user.FirstName,
+		LastName:    user.LastName,
+		Username:    user.Username,
+		PhotoUrl:    user.PhotoUrl,
+		IsPremium:   user.IsPremium,
+		DisplayName: user.DisplayName,
+		Language:    user.Language,
+		Notifications: NotificationsModel{
+			Orders:     user.NotifyOrders,
+			Favourites: user.NotifyFavourites,
+		},
+		Statistics: StatisticsModel{
+			RatedCount:      statistics.RatedCount,
+			FavouritesCount: statistics.FavouritesCount,
+			MemberSince:     user.CreatedAt,
+		},
+	}
+}
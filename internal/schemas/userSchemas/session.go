@@ -0,0 +1,48 @@
+package userSchemas
+
+import (
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"net"
+	"net/http"
+	"time"
+)
+
+type DeviceInfo struct {
+	UserAgent string
+	IpAddress string
+}
+
+func NewDeviceInfo(r *http.Request) *DeviceInfo {
+	ipAddress, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ipAddress = r.RemoteAddr
+	}
+
+	return &DeviceInfo{
+		UserAgent: r.UserAgent(),
+		IpAddress: ipAddress,
+	}
+}
+
+type SessionResponseModel struct {
+	Id         uuid.UUID `json:"id"`
+	UserAgent  string    `json:"userAgent,omitempty"`
+	IpAddress  string    `json:"ipAddress,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	IsCurrent  bool      `json:"isCurrent"`
+}
+
+func NewSessionResponseModel(session *entity.Session, currentSessionId uuid.UUID) *SessionResponseModel {
+	return &SessionResponseModel{
+		Id:         session.Id,
+		UserAgent:  session.UserAgent,
+		IpAddress:  session.IpAddress,
+		CreatedAt:  session.CreatedAt,
+		LastUsedAt: session.LastUsedAt,
+		ExpiresAt:  session.ExpiresAt,
+		IsCurrent:  session.Id == currentSessionId,
+	}
+}
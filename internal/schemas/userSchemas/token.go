@@ -13,11 +13,49 @@ type TokenResponse struct {
 }
 
 type AccessTokenClaims struct {
-	Id        uuid.UUID        `json:"id"`
-	FirstName string           `json:"firstName"`
-	Username  string           `json:"username,omitempty"`
-	Role      string           `json:"role"`
-	Exp       *jwt.NumericDate `json:"exp"`
+	Id          uuid.UUID        `json:"id"`
+	SessionId   uuid.UUID        `json:"sid"`
+	FirstName   string           `json:"firstName"`
+	Username    string           `json:"username,omitempty"`
+	Role        string           `json:"role"`
+	Roles       []string         `json:"roles"`
+	Permissions []string         `json:"permissions"`
+	Exp         *jwt.NumericDate `json:"exp"`
+	ApiKeyId    uuid.UUID        `json:"-"`
+	LocationId  uuid.UUID        `json:"-"`
+}
+
+// NewApiKeyAccessTokenClaims describes a request authenticated with an API
+// key. It has no user, and its permissions come from the scopes of the key.
+func NewApiKeyAccessTokenClaims(apiKey *entity.ApiKey) *AccessTokenClaims {
+	permissions := make([]string, 0)
+	for _, scope := range apiKey.Scopes {
+		permissions = append(permissions, entity.ApiKeyScopePermissions[scope]...)
+	}
+
+	return &AccessTokenClaims{
+		Id:          uuid.Nil,
+		FirstName:   apiKey.Name,
+		Role:        entity.RoleApiKey,
+		Roles:       make([]string, 0),
+		Permissions: permissions,
+		ApiKeyId:    apiKey.Id,
+	}
+}
+
+func (c *AccessTokenClaims) HasPermission(permission string) bool {
+	for _, p := range c.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// CanManageLocation tells whether the user may work with the location.
+// Users scoped to a location may work only with it, the rest with any.
+func (c *AccessTokenClaims) CanManageLocation(locationId uuid.UUID) bool {
+	return c.LocationId == uuid.Nil || c.LocationId == locationId
 }
 
 type RefreshTokenClaims struct {
@@ -48,15 +86,18 @@ type UserTokens struct {
 	RefreshToken *RefreshToken `json:"-"`
 }
 
-func NewAccessToken(signedValue string, user *entity.User, role string, expTime time.Time) *AccessToken {
+func NewAccessToken(signedValue string, user *entity.User, sessionId uuid.UUID, role string, roles, permissions []string, expTime time.Time) *AccessToken {
 	return &AccessToken{
 		SignedValue: signedValue,
 		Claims: &AccessTokenClaims{
-			Id:        user.Id,
-			FirstName: user.FirstName,
-			Username:  user.Username,
-			Role:      role,
-			Exp:       jwt.NewNumericDate(expTime),
+			Id:          user.Id,
+			SessionId:   sessionId,
+			FirstName:   user.FirstName,
+			Username:    user.Username,
+			Role:        role,
+			Roles:       roles,
+			Permissions: permissions,
+			Exp:         jwt.NewNumericDate(expTime),
 		},
 	}
 }
@@ -1,5 +1,7 @@
 package userSchemas
 
+import "strconv"
+
 type TelegramUser struct {
 	Id        uint64 `json:"id"`
 	FirstName string `json:"first_name"`
@@ -10,6 +12,27 @@ type TelegramUser struct {
 	PhotoURL  string `json:"photo_url"`
 }
 
+// Credentials returns the widget fields the way Telegram has signed them,
+// optional fields are only present if they are not empty.
+func (u *TelegramUser) Credentials() map[string]string {
+	credentials := map[string]string{
+		"id":         strconv.FormatUint(u.Id, 10),
+		"first_name": u.FirstName,
+		"auth_date":  strconv.FormatInt(u.AuthDate, 10),
+		"hash":       u.Hash,
+	}
+	if u.LastName != "" {
+		credentials["last_name"] = u.LastName
+	}
+	if u.Username != "" {
+		credentials["username"] = u.Username
+	}
+	if u.PhotoURL != "" {
+		credentials["photo_url"] = u.PhotoURL
+	}
+	return credentials
+}
+
 type MiniAppInitRequest struct {
 	InitData string `json:"initData"`
 }
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/errx"
+	"github.com/levchenki/tea-api/internal/logx"
+	"slices"
+	"strconv"
+)
+
+type AdminBootstrapUserRepository interface {
+	GetByTelegramId(telegramId uint64) (*entity.User, error)
+}
+
+type AdminBootstrapRoleRepository interface {
+	GetByName(name string) (*entity.Role, error)
+	AssignToUser(userId, roleId uuid.UUID) error
+}
+
+// AdminBootstrapService grants the admin role by Telegram id, so a fresh
+// deployment gets its first admins without touching the database. The
+// configured ids are applied at startup and on every sign-in, which covers
+// users who register after the deployment.
+type AdminBootstrapService struct {
+	userRepository AdminBootstrapUserRepository
+	roleRepository AdminBootstrapRoleRepository
+	telegramIds    []uint64
+	log            logx.AppLogger
+}
+
+func NewAdminBootstrapService(
+	userRepository AdminBootstrapUserRepository,
+	roleRepository AdminBootstrapRoleRepository,
+	telegramIds []uint64,
+	log logx.AppLogger,
+) *AdminBootstrapService {
+	return &AdminBootstrapService{
+		userRepository: userRepository,
+		roleRepository: roleRepository,
+		telegramIds:    telegramIds,
+		log:            log,
+	}
+}
+
+// GrantConfigured makes admins of the configured users that are already
+// registered. The rest become admins on their first sign-in.
+func (s *AdminBootstrapService) GrantConfigured() error {
+	for _, telegramId := range s.telegramIds {
+		user, err := s.userRepository.GetByTelegramId(telegramId)
+		if err != nil {
+			return err
+		}
+
+		if user == nil {
+			s.log.Info("Configured admin has not signed in yet", "telegramId", telegramId)
+			continue
+		}
+
+		_, err = s.Apply(user)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Grant makes an admin of the user with the Telegram id, whether the id is
+// configured or not.
+func (s *AdminBootstrapService) Grant(telegramId uint64) (*entity.User, error) {
+	user, err := s.userRepository.GetByTelegramId(telegramId)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		errResponse := errx.NewNotFoundError(fmt.Errorf("user with telegram id %d is not found", telegramId))
+		return nil, errResponse
+	}
+
+	if user.IsAdmin {
+		return user, nil
+	}
+
+	err = s.assignAdminRole(user)
+	if err != nil {
+		return nil, err
+	}
+	return s.userRepository.GetByTelegramId(telegramId)
+}
+
+// Apply grants the admin role to the user if their Telegram id is configured
+// and returns the user as it is after that.
+func (s *AdminBootstrapService) Apply(user *entity.User) (*entity.User, error) {
+	if user.IsAdmin || user.TelegramId == 0 || !slices.Contains(s.telegramIds, user.TelegramId) {
+		return user, nil
+	}
+
+	err := s.assignAdminRole(user)
+	if err != nil {
+		return nil, err
+	}
+	return s.userRepository.GetByTelegramId(user.TelegramId)
+}
+
+func (s *AdminBootstrapService) assignAdminRole(user *entity.User) error {
+	adminRole, err := s.roleRepository.GetByName(entity.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	if adminRole == nil {
+		errResponse := errx.NewInternalServerError(fmt.Errorf("role %s is not found", entity.RoleAdmin))
+		return errResponse
+	}
+
+	err = s.roleRepository.AssignToUser(user.Id, adminRole.Id)
+	if err != nil {
+		return err
+	}
+
+	s.log.Info("Admin role granted", "userId", user.Id.String(), "telegramId", strconv.FormatUint(user.TelegramId, 10))
+	return nil
+}
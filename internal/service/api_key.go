@@ -0,0 +1,113 @@
+package service
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/errx"
+	"github.com/levchenki/tea-api/internal/schemas/apiKeySchemas"
+	"time"
+)
+
+const (
+	apiKeyPrefix              = "tea_"
+	apiKeySecretSize          = 32
+	apiKeyDisplayPrefixLength = 12
+)
+
+type ApiKeyRepository interface {
+	GetAll() ([]entity.ApiKey, error)
+	GetById(id uuid.UUID) (*entity.ApiKey, error)
+	GetByHash(keyHash string) (*entity.ApiKey, error)
+	Create(apiKey *entity.ApiKey) (*entity.ApiKey, error)
+	Revoke(id uuid.UUID) error
+	Touch(id uuid.UUID) error
+}
+
+type ApiKeyService struct {
+	apiKeyRepository ApiKeyRepository
+}
+
+func NewApiKeyService(apiKeyRepository ApiKeyRepository) *ApiKeyService {
+	return &ApiKeyService{
+		apiKeyRepository: apiKeyRepository,
+	}
+}
+
+func (s *ApiKeyService) GetAll() ([]entity.ApiKey, error) {
+	apiKeys, err := s.apiKeyRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	return apiKeys, nil
+}
+
+// Create returns the stored key together with its plain value. Only a hash is
+// kept, so the value can not be recovered later.
+func (s *ApiKeyService) Create(actorId uuid.UUID, apiKey *apiKeySchemas.RequestModel) (*entity.ApiKey, string, error) {
+	secret, err := generateRandomToken(apiKeySecretSize)
+	if err != nil {
+		errResponse := errx.NewInternalServerError(fmt.Errorf("api key generation error: %w", err))
+		return nil, "", errResponse
+	}
+	key := apiKeyPrefix + secret
+
+	createdApiKey, err := s.apiKeyRepository.Create(&entity.ApiKey{
+		Name:      apiKey.Name,
+		Prefix:    key[:apiKeyDisplayPrefixLength],
+		KeyHash:   hashToken(key),
+		Scopes:    apiKey.Scopes,
+		CreatedBy: &actorId,
+		ExpiresAt: apiKey.ExpiresAt,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return createdApiKey, key, nil
+}
+
+func (s *ApiKeyService) Revoke(id uuid.UUID) error {
+	apiKey, err := s.apiKeyRepository.GetById(id)
+	if err != nil {
+		return err
+	}
+
+	if apiKey == nil {
+		errResponse := errx.NewNotFoundError(fmt.Errorf("api key with id %s is not found", id))
+		return errResponse
+	}
+
+	err = s.apiKeyRepository.Revoke(id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *ApiKeyService) Authenticate(key string) (*entity.ApiKey, error) {
+	apiKey, err := s.apiKeyRepository.GetByHash(hashToken(key))
+	if err != nil {
+		return nil, err
+	}
+
+	if apiKey == nil {
+		errResponse := errx.NewUnauthorizedError(fmt.Errorf("api key is invalid"))
+		return nil, errResponse
+	}
+
+	if apiKey.RevokedAt != nil {
+		errResponse := errx.NewUnauthorizedError(fmt.Errorf("api key is revoked"))
+		return nil, errResponse
+	}
+
+	if apiKey.ExpiresAt != nil && time.Now().After(*apiKey.ExpiresAt) {
+		errResponse := errx.NewUnauthorizedError(fmt.Errorf("api key is expired"))
+		return nil, errResponse
+	}
+
+	err = s.apiKeyRepository.Touch(apiKey.Id)
+	if err != nil {
+		return nil, err
+	}
+	return apiKey, nil
+}
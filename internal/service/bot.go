@@ -0,0 +1,328 @@
+package service
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/schemas/teaSchemas"
+	"github.com/levchenki/tea-api/internal/tgx"
+	"html"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	botMenuLimit       = 100
+	botSearchLimit     = 10
+	botFavouritesLimit = 20
+	botInlineLimit     = 20
+	botInlineCacheTime = 60
+	botDescriptionSize = 300
+	botMessageSize     = 4000
+)
+
+type BotTeaService interface {
+	GetAllTeas(filters *teaSchemas.Filters) ([]entity.TeaWithRating, uint64, error)
+}
+
+type BotCategoryService interface {
+	GetAll() ([]entity.Category, error)
+}
+
+type BotUserRepository interface {
+	GetByTelegramId(telegramId uint64) (*entity.User, error)
+}
+
+// BotService answers the updates the bot receives through the webhook. Bot
+// users are matched to our users by Telegram id, so the ones who have signed
+// in to the mini app see their favourites and ratings.
+type BotService struct {
+	teaService      BotTeaService
+	categoryService BotCategoryService
+	userRepository  BotUserRepository
+	botName         string
+	miniAppName     string
+}
+
+func NewBotService(
+	teaService BotTeaService,
+	categoryService BotCategoryService,
+	userRepository BotUserRepository,
+	botName string,
+	miniAppName string,
+) *BotService {
+	return &BotService{
+		teaService:      teaService,
+		categoryService: categoryService,
+		userRepository:  userRepository,
+		botName:         botName,
+		miniAppName:     miniAppName,
+	}
+}
+
+// HandleUpdate returns the Bot API method to call in response to the update
+// or nil if the update needs no answer.
+func (s *BotService) HandleUpdate(update *tgx.Update) (any, error) {
+	if update.InlineQuery != nil {
+		return s.answerInlineQuery(update.InlineQuery)
+	}
+
+	message := update.Message
+	if message == nil || message.From == nil || message.Chat.Type != "private" {
+		return nil, nil
+	}
+
+	command, args := tgx.ParseCommand(message.Text)
+	switch command {
+	case "menu":
+		return s.menu(message.Chat.Id)
+	case "search":
+		return s.search(message.Chat.Id, message.From.Id, args)
+	case "favourites":
+		return s.favourites(message.Chat.Id, message.From.Id)
+	default:
+		return s.help(message.Chat.Id), nil
+	}
+}
+
+func (s *BotService) help(chatId int64) *tgx.SendMessage {
+	lines := []string{
+		"/menu - teas we have today",
+		"/search &lt;name&gt; - find a tea by name",
+		"/favourites - your favourite teas",
+	}
+	if botName := strings.TrimPrefix(s.botName, "@"); botName != "" {
+		lines = append(lines, "", fmt.Sprintf("You can also search in any chat: @%s sencha", html.EscapeString(botName)))
+	}
+	text := strings.Join(lines, "\n")
+
+	reply := tgx.NewSendMessage(chatId, text)
+	reply.ReplyMarkup = s.appKeyboard()
+	return reply
+}
+
+func (s *BotService) menu(chatId int64) (*tgx.SendMessage, error) {
+	categories, err := s.categoryService.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	filters := s.newFilters(botMenuLimit, uuid.Nil)
+	filters.SortBy = teaSchemas.Name
+	teas, _, err := s.teaService.GetAllTeas(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(teas) == 0 {
+		return tgx.NewSendMessage(chatId, "The menu is empty right now."), nil
+	}
+
+	teasByCategoryId := make(map[uuid.UUID][]entity.TeaWithRating)
+	for _, tea := range teas {
+		teasByCategoryId[tea.CategoryId] = append(teasByCategoryId[tea.CategoryId], tea)
+	}
+
+	// Telegram cuts messages at 4096 characters, the rest of a long menu is
+	// left to the app.
+	text := ""
+	for _, category := range categories {
+		categoryTeas, ok := teasByCategoryId[category.Id]
+		if !ok {
+			continue
+		}
+
+		lines := []string{fmt.Sprintf("<b>%s</b>", html.EscapeString(category.Name))}
+		for i := range categoryTeas {
+			lines = append(lines, teaLine(&categoryTeas[i], false))
+		}
+
+		section := strings.Join(lines, "\n")
+		if text != "" {
+			section = "\n\n" + section
+		}
+		if utf8.RuneCountInString(text+section) > botMessageSize {
+			text += "\n\n…and more in the app"
+			break
+		}
+		text += section
+	}
+
+	reply := tgx.NewSendMessage(chatId, text)
+	reply.ReplyMarkup = s.appKeyboard()
+	return reply, nil
+}
+
+func (s *BotService) search(chatId int64, telegramId uint64, query string) (*tgx.SendMessage, error) {
+	if query == "" {
+		return tgx.NewSendMessage(chatId, "Send the name of the tea, for example: /search sencha"), nil
+	}
+
+	user, err := s.userRepository.GetByTelegramId(telegramId)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := s.newFilters(botSearchLimit, userIdOf(user))
+	filters.Name = query
+	teas, _, err := s.teaService.GetAllTeas(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(teas) == 0 {
+		text := fmt.Sprintf("Nothing found for «%s».", html.EscapeString(query))
+		return tgx.NewSendMessage(chatId, text), nil
+	}
+	return s.teaList(chatId, teas, user != nil), nil
+}
+
+func (s *BotService) favourites(chatId int64, telegramId uint64) (*tgx.SendMessage, error) {
+	user, err := s.userRepository.GetByTelegramId(telegramId)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		reply := tgx.NewSendMessage(chatId, "Open the app once to sign in, then your favourites will show up here.")
+		reply.ReplyMarkup = s.appKeyboard()
+		return reply, nil
+	}
+
+	filters := s.newFilters(botFavouritesLimit, user.Id)
+	filters.IsOnlyFavourite = true
+	filters.SortBy = teaSchemas.Name
+	teas, _, err := s.teaService.GetAllTeas(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(teas) == 0 {
+		return tgx.NewSendMessage(chatId, "You have no favourite teas yet."), nil
+	}
+	return s.teaList(chatId, teas, true), nil
+}
+
+func (s *BotService) answerInlineQuery(inlineQuery *tgx.InlineQuery) (*tgx.AnswerInlineQuery, error) {
+	user, err := s.userRepository.GetByTelegramId(inlineQuery.From.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := s.newFilters(botInlineLimit, userIdOf(user))
+	filters.Name = strings.TrimSpace(inlineQuery.Query)
+	if filters.Name == "" {
+		filters.SortBy = teaSchemas.Name
+	}
+	teas, _, err := s.teaService.GetAllTeas(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]tgx.InlineQueryResultArticle, len(teas))
+	for i := range teas {
+		results[i] = s.teaCard(&teas[i], user != nil)
+	}
+	return tgx.NewAnswerInlineQuery(inlineQuery.Id, results, botInlineCacheTime, user != nil), nil
+}
+
+func (s *BotService) teaList(chatId int64, teas []entity.TeaWithRating, withRating bool) *tgx.SendMessage {
+	lines := make([]string, len(teas))
+	keyboard := make([][]tgx.InlineKeyboardButton, 0, len(teas))
+	for i := range teas {
+		lines[i] = teaLine(&teas[i], withRating)
+		if link := s.teaLink(teas[i].Id); link != "" {
+			keyboard = append(keyboard, []tgx.InlineKeyboardButton{{Text: teas[i].Name, Url: link}})
+		}
+	}
+
+	reply := tgx.NewSendMessage(chatId, strings.Join(lines, "\n"))
+	if len(keyboard) > 0 {
+		reply.ReplyMarkup = &tgx.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+	}
+	return reply
+}
+
+func (s *BotService) teaCard(tea *entity.TeaWithRating, withRating bool) tgx.InlineQueryResultArticle {
+	description := truncate(tea.Description, botDescriptionSize)
+
+	text := fmt.Sprintf("<b>%s</b>", html.EscapeString(tea.Name))
+	if description != "" {
+		text += "\n" + html.EscapeString(description)
+	}
+
+	card := tgx.InlineQueryResultArticle{
+		Type:        "article",
+		Id:          tea.Id.String(),
+		Title:       tea.Name,
+		Description: teaDetails(tea, withRating),
+		InputMessageContent: tgx.InputTextMessageContent{
+			MessageText: text,
+			ParseMode:   tgx.ParseModeHTML,
+		},
+	}
+	if link := s.teaLink(tea.Id); link != "" {
+		card.ReplyMarkup = &tgx.InlineKeyboardMarkup{
+			InlineKeyboard: [][]tgx.InlineKeyboardButton{{{Text: "Open in the app", Url: link}}},
+		}
+	}
+	return card
+}
+
+func (s *BotService) appKeyboard() *tgx.InlineKeyboardMarkup {
+	link := tgx.MiniAppLink(s.botName, s.miniAppName, "")
+	if link == "" {
+		return nil
+	}
+	return &tgx.InlineKeyboardMarkup{
+		InlineKeyboard: [][]tgx.InlineKeyboardButton{{{Text: "Open the app", Url: link}}},
+	}
+}
+
+func (s *BotService) teaLink(teaId uuid.UUID) string {
+	return tgx.MiniAppLink(s.botName, s.miniAppName, "tea_"+teaId.String())
+}
+
+func (s *BotService) newFilters(limit uint64, userId uuid.UUID) *teaSchemas.Filters {
+	filters := teaSchemas.NewFilters()
+	filters.Limit = limit
+	filters.Page = 1
+	filters.IsAsc = true
+	filters.UserId = userId
+	return filters
+}
+
+func userIdOf(user *entity.User) uuid.UUID {
+	if user == nil {
+		return uuid.Nil
+	}
+	return user.Id
+}
+
+func teaLine(tea *entity.TeaWithRating, withRating bool) string {
+	line := "• " + html.EscapeString(tea.Name)
+	if details := teaDetails(tea, withRating); details != "" {
+		line += " — " + html.EscapeString(details)
+	}
+	return line
+}
+
+func teaDetails(tea *entity.TeaWithRating, withRating bool) string {
+	details := make([]string, 0, 2)
+	if tea.ServePrice > 0 {
+		details = append(details, strconv.FormatFloat(tea.ServePrice, 'f', -1, 64)+" per serve")
+	}
+	if withRating && tea.Rating > 0 {
+		details = append(details, "your rating "+strconv.FormatFloat(tea.Rating, 'f', -1, 64))
+	}
+	return strings.Join(details, ", ")
+}
+
+func truncate(text string, size int) string {
+	text = strings.TrimSpace(text)
+	if utf8.RuneCountInString(text) <= size {
+		return text
+	}
+	return string([]rune(text)[:size-1]) + "…"
+}
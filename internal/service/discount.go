@@ -0,0 +1,473 @@
+package service
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/errx"
+	"github.com/levchenki/tea-api/internal/schemas/discountSchemas"
+	"time"
+)
+
+type DiscountRepository interface {
+	GetAll() ([]entity.Discount, error)
+	GetById(id uuid.UUID) (*entity.Discount, error)
+	GetByCode(code string) (*entity.Discount, error)
+	ExistsByCode(existedId uuid.UUID, code string) (bool, error)
+	GetAutomatic(at time.Time) ([]entity.Discount, error)
+	Create(discount *entity.Discount) (uuid.UUID, error)
+	Update(discount *entity.Discount) error
+	Delete(id uuid.UUID) error
+	GetUsage(ids []uuid.UUID, userId uuid.UUID) (map[uuid.UUID]entity.DiscountUsage, error)
+	GetCartPromoCode(userId uuid.UUID) (*entity.Discount, error)
+	SetCartPromoCode(userId, discountId uuid.UUID) error
+	DeleteCartPromoCode(userId uuid.UUID) error
+	GetReport(filters *discountSchemas.ReportFilters) ([]entity.DiscountReport, error)
+	GetRedemptions(discountId uuid.UUID, filters *discountSchemas.RedemptionFilters) ([]entity.DiscountRedemption, uint64, error)
+}
+
+type DiscountTagRepository interface {
+	Exists(id uuid.UUID) (bool, error)
+	GetAllByTeaIds(teaIds []uuid.UUID) (map[uuid.UUID][]entity.Tag, error)
+}
+
+type DiscountCategoryRepository interface {
+	Exists(id uuid.UUID) (bool, error)
+}
+
+type DiscountTeaRepository interface {
+	Exists(id uuid.UUID) (bool, error)
+}
+
+// DiscountService manages promo codes and automatic discount rules and
+// prices carts with them. The prices of the teas stay the list prices, the
+// discounts only lower the total of the cart.
+type DiscountService struct {
+	discountRepository DiscountRepository
+	tagRepository      DiscountTagRepository
+	categoryRepository DiscountCategoryRepository
+	teaRepository      DiscountTeaRepository
+}
+
+func NewDiscountService(
+	discountRepository DiscountRepository,
+	tagRepository DiscountTagRepository,
+	categoryRepository DiscountCategoryRepository,
+	teaRepository DiscountTeaRepository,
+) *DiscountService {
+	return &DiscountService{
+		discountRepository: discountRepository,
+		tagRepository:      tagRepository,
+		categoryRepository: categoryRepository,
+		teaRepository:      teaRepository,
+	}
+}
+
+func (s *DiscountService) GetAll() ([]entity.Discount, error) {
+	discounts, err := s.discountRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	return discounts, nil
+}
+
+func (s *DiscountService) GetById(id uuid.UUID) (*entity.Discount, error) {
+	discount, err := s.discountRepository.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if discount == nil || discount.DeletedAt != nil {
+		err := fmt.Errorf("discount with id %s is not found", id.String())
+		return nil, errx.NewNotFoundError(err)
+	}
+	return discount, nil
+}
+
+func (s *DiscountService) Create(discount *entity.Discount) (*entity.Discount, error) {
+	err := s.checkDiscount(discount)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.discountRepository.Create(discount)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetById(id)
+}
+
+func (s *DiscountService) Update(id uuid.UUID, discount *entity.Discount) (*entity.Discount, error) {
+	_, err := s.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	discount.Id = id
+	err = s.checkDiscount(discount)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.discountRepository.Update(discount)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetById(id)
+}
+
+func (s *DiscountService) Delete(id uuid.UUID) error {
+	_, err := s.GetById(id)
+	if err != nil {
+		return err
+	}
+
+	err = s.discountRepository.Delete(id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *DiscountService) GetReport(filters *discountSchemas.ReportFilters) ([]entity.DiscountReport, error) {
+	reports, err := s.discountRepository.GetReport(filters)
+	if err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// GetRedemptions returns the redemptions of deleted discounts too, they are
+// still listed in the report.
+func (s *DiscountService) GetRedemptions(id uuid.UUID, filters *discountSchemas.RedemptionFilters) ([]entity.DiscountRedemption, uint64, error) {
+	discount, err := s.discountRepository.GetById(id)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if discount == nil {
+		err := fmt.Errorf("discount with id %s is not found", id.String())
+		return nil, 0, errx.NewNotFoundError(err)
+	}
+
+	redemptions, total, err := s.discountRepository.GetRedemptions(id, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+	return redemptions, total, nil
+}
+
+// PriceCart applies the discounts to the cart of the user. The greatest of
+// the automatic rules applies along with the promo code the user has
+// entered, together they never exceed the subtotal.
+func (s *DiscountService) PriceCart(userId uuid.UUID, items []entity.CartItem) (*entity.Cart, error) {
+	promoCode, err := s.discountRepository.GetCartPromoCode(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	// The promo code could have been turned into an automatic rule since.
+	if promoCode != nil && !promoCode.IsPromoCode() {
+		promoCode = nil
+	}
+	return s.priceCart(userId, items, promoCode)
+}
+
+// ApplyPromoCode checks that the promo code can be used with the cart and
+// keeps it for the cart until the order is placed.
+func (s *DiscountService) ApplyPromoCode(userId uuid.UUID, code string, items []entity.CartItem) (*entity.Cart, error) {
+	discount, err := s.discountRepository.GetByCode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	if discount == nil {
+		err := fmt.Errorf("promo code %s is not found", code)
+		return nil, errx.NewNotFoundError(err)
+	}
+
+	cart, err := s.priceCart(userId, items, discount)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := false
+	for _, d := range cart.Discounts {
+		if d.DiscountId == discount.Id {
+			applied = true
+		}
+	}
+
+	if !applied {
+		reason, err := s.rejectionReason(userId, discount, items)
+		if err != nil {
+			return nil, err
+		}
+		err = fmt.Errorf("promo code %s can not be used: %s", discount.Code, reason)
+		return nil, errx.NewBadRequestError(err)
+	}
+
+	err = s.discountRepository.SetCartPromoCode(userId, discount.Id)
+	if err != nil {
+		return nil, err
+	}
+	return cart, nil
+}
+
+func (s *DiscountService) RemovePromoCode(userId uuid.UUID, items []entity.CartItem) (*entity.Cart, error) {
+	err := s.discountRepository.DeleteCartPromoCode(userId)
+	if err != nil {
+		return nil, err
+	}
+	return s.priceCart(userId, items, nil)
+}
+
+func (s *DiscountService) priceCart(userId uuid.UUID, items []entity.CartItem, promoCode *entity.Discount) (*entity.Cart, error) {
+	cart := &entity.Cart{
+		Items:     items,
+		Discounts: make([]entity.AppliedDiscount, 0),
+	}
+	for i := range items {
+		cart.Subtotal += items[i].Amount()
+	}
+	cart.Subtotal = entity.RoundPrice(cart.Subtotal)
+	cart.Total = cart.Subtotal
+
+	if promoCode != nil {
+		cart.PromoCode = promoCode.Code
+	}
+
+	if len(items) == 0 {
+		return cart, nil
+	}
+
+	now := time.Now()
+	discounts, err := s.discountRepository.GetAutomatic(now)
+	if err != nil {
+		return nil, err
+	}
+	if promoCode != nil {
+		discounts = append(discounts, *promoCode)
+	}
+
+	amounts, err := s.discountAmounts(userId, discounts, items, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var best *entity.AppliedDiscount
+	for i := range discounts {
+		amount := amounts[discounts[i].Id]
+		if discounts[i].IsPromoCode() || amount <= 0 {
+			continue
+		}
+		if best == nil || amount > best.Amount {
+			best = newAppliedDiscount(&discounts[i], amount)
+		}
+	}
+
+	if best != nil {
+		cart.Discounts = append(cart.Discounts, *best)
+		cart.Discount = best.Amount
+	}
+
+	if promoCode != nil {
+		amount := min(amounts[promoCode.Id], entity.RoundPrice(cart.Subtotal-cart.Discount))
+		if amount > 0 {
+			cart.Discounts = append(cart.Discounts, *newAppliedDiscount(promoCode, amount))
+			cart.Discount = entity.RoundPrice(cart.Discount + amount)
+		}
+	}
+
+	cart.Total = entity.RoundPrice(cart.Subtotal - cart.Discount)
+	return cart, nil
+}
+
+// discountAmounts returns the discount each of the discounts gives the cart.
+// Discounts that can not be used with the cart give nothing.
+func (s *DiscountService) discountAmounts(userId uuid.UUID, discounts []entity.Discount, items []entity.CartItem, now time.Time) (map[uuid.UUID]float64, error) {
+	amounts := make(map[uuid.UUID]float64, len(discounts))
+	if len(discounts) == 0 {
+		return amounts, nil
+	}
+
+	ids := make([]uuid.UUID, len(discounts))
+	for i := range discounts {
+		ids[i] = discounts[i].Id
+	}
+
+	usages, err := s.discountRepository.GetUsage(ids, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	tagIds, err := s.getTagIds(discounts, items)
+	if err != nil {
+		return nil, err
+	}
+
+	var subtotal float64
+	for i := range items {
+		subtotal += items[i].Amount()
+	}
+
+	for i := range discounts {
+		discount := &discounts[i]
+		usage := usages[discount.Id]
+		if !discount.IsValidAt(now) || subtotal < discount.MinOrderAmount || isUsedUp(discount, &usage) {
+			continue
+		}
+
+		var base float64
+		for j := range items {
+			if appliesTo(discount, &items[j], tagIds[items[j].TeaId]) {
+				base += items[j].Amount()
+			}
+		}
+		amounts[discount.Id] = discount.AmountOf(entity.RoundPrice(base))
+	}
+	return amounts, nil
+}
+
+// getTagIds loads the tags of the teas in the cart, only if a discount is
+// limited to a tag.
+func (s *DiscountService) getTagIds(discounts []entity.Discount, items []entity.CartItem) (map[uuid.UUID]map[uuid.UUID]bool, error) {
+	tagIds := make(map[uuid.UUID]map[uuid.UUID]bool)
+
+	hasTagScope := false
+	for i := range discounts {
+		if discounts[i].Scope == entity.DiscountScopeTag {
+			hasTagScope = true
+		}
+	}
+	if !hasTagScope {
+		return tagIds, nil
+	}
+
+	teaIds := make([]uuid.UUID, len(items))
+	for i := range items {
+		teaIds[i] = items[i].TeaId
+	}
+
+	tags, err := s.tagRepository.GetAllByTeaIds(teaIds)
+	if err != nil {
+		return nil, err
+	}
+
+	for teaId, teaTags := range tags {
+		tagIds[teaId] = make(map[uuid.UUID]bool, len(teaTags))
+		for _, tag := range teaTags {
+			tagIds[teaId][tag.Id] = true
+		}
+	}
+	return tagIds, nil
+}
+
+// rejectionReason tells the customer why the promo code gives nothing to
+// the cart.
+func (s *DiscountService) rejectionReason(userId uuid.UUID, discount *entity.Discount, items []entity.CartItem) (string, error) {
+	if !discount.IsValidAt(time.Now()) {
+		return "it is not valid at the moment", nil
+	}
+
+	var subtotal float64
+	for i := range items {
+		subtotal += items[i].Amount()
+	}
+	if subtotal < discount.MinOrderAmount {
+		return fmt.Sprintf("the order must be at least %g", discount.MinOrderAmount), nil
+	}
+
+	usages, err := s.discountRepository.GetUsage([]uuid.UUID{discount.Id}, userId)
+	if err != nil {
+		return "", err
+	}
+	usage := usages[discount.Id]
+	if isUsedUp(discount, &usage) {
+		return "it has been used up", nil
+	}
+
+	if len(items) == 0 {
+		return "the cart is empty", nil
+	}
+	if discount.Scope != entity.DiscountScopeOrder {
+		return "the cart has no teas it applies to", nil
+	}
+	return "the cart is already fully discounted", nil
+}
+
+func (s *DiscountService) checkDiscount(discount *entity.Discount) error {
+	if discount.IsPromoCode() {
+		exists, err := s.discountRepository.ExistsByCode(discount.Id, discount.Code)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			err := fmt.Errorf("promo code %s already exists", discount.Code)
+			return errx.NewBadRequestError(err)
+		}
+	}
+
+	if discount.TargetId == nil {
+		return nil
+	}
+
+	var (
+		exists bool
+		err    error
+	)
+	switch discount.Scope {
+	case entity.DiscountScopeCategory:
+		exists, err = s.categoryRepository.Exists(*discount.TargetId)
+	case entity.DiscountScopeTag:
+		exists, err = s.tagRepository.Exists(*discount.TargetId)
+	case entity.DiscountScopeTea:
+		exists, err = s.teaRepository.Exists(*discount.TargetId)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		err := fmt.Errorf("%s with id %s is not found", discount.Scope, discount.TargetId.String())
+		return errx.NewNotFoundError(err)
+	}
+	return nil
+}
+
+func appliesTo(discount *entity.Discount, item *entity.CartItem, tagIds map[uuid.UUID]bool) bool {
+	if discount.Scope != entity.DiscountScopeOrder && discount.TargetId == nil {
+		return false
+	}
+
+	switch discount.Scope {
+	case entity.DiscountScopeOrder:
+		return true
+	case entity.DiscountScopeCategory:
+		return item.CategoryId == *discount.TargetId
+	case entity.DiscountScopeTag:
+		return tagIds[*discount.TargetId]
+	case entity.DiscountScopeTea:
+		return item.TeaId == *discount.TargetId
+	}
+	return false
+}
+
+func isUsedUp(discount *entity.Discount, usage *entity.DiscountUsage) bool {
+	if discount.UsageLimit != nil && usage.Total >= *discount.UsageLimit {
+		return true
+	}
+	return discount.PerUserLimit != nil && usage.ByUser >= *discount.PerUserLimit
+}
+
+func newAppliedDiscount(discount *entity.Discount, amount float64) *entity.AppliedDiscount {
+	return &entity.AppliedDiscount{
+		DiscountId: discount.Id,
+		Name:       discount.Name,
+		Code:       discount.Code,
+		Amount:     amount,
+	}
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/errx"
+	"github.com/levchenki/tea-api/internal/schemas/eventSchemas"
+)
+
+type EventRepository interface {
+	GetAll(filters *eventSchemas.Filters) ([]entity.Event, uint64, error)
+	GetById(id, userId uuid.UUID) (*entity.Event, error)
+	Create(event *entity.Event, teaIds []uuid.UUID) (uuid.UUID, error)
+	Update(event *entity.Event, teaIds []uuid.UUID) error
+	Delete(id uuid.UUID) error
+	Register(eventId, userId uuid.UUID) (string, error)
+	Cancel(eventId, userId uuid.UUID) (bool, error)
+	GetAttendees(eventId uuid.UUID) ([]entity.EventAttendee, error)
+}
+
+type EventTeaRepository interface {
+	GetAllByIds(ids []uuid.UUID) ([]entity.Tea, error)
+}
+
+type EventLocationRepository interface {
+	Exists(id uuid.UUID) (bool, error)
+}
+
+type EventService struct {
+	eventRepository    EventRepository
+	teaRepository      EventTeaRepository
+	locationRepository EventLocationRepository
+}
+
+func NewEventService(
+	eventRepository EventRepository,
+	teaRepository EventTeaRepository,
+	locationRepository EventLocationRepository,
+) *EventService {
+	return &EventService{
+		eventRepository:    eventRepository,
+		teaRepository:      teaRepository,
+		locationRepository: locationRepository,
+	}
+}
+
+func (s *EventService) GetAll(filters *eventSchemas.Filters) ([]entity.Event, uint64, error) {
+	if filters.LocationId != uuid.Nil {
+		err := s.checkLocation(filters.LocationId)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	events, total, err := s.eventRepository.GetAll(filters)
+	if err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
+
+// GetById returns the event with the registration status of the user. The
+// nil user id loads the event without it.
+func (s *EventService) GetById(id, userId uuid.UUID) (*entity.Event, error) {
+	event, err := s.eventRepository.GetById(id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if event == nil {
+		err := fmt.Errorf("event with id %s is not found", id.String())
+		return nil, errx.NewNotFoundError(err)
+	}
+	return event, nil
+}
+
+func (s *EventService) Create(event *entity.Event, teaIds []uuid.UUID) (*entity.Event, error) {
+	err := s.checkEvent(event, teaIds)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.eventRepository.Create(event, teaIds)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetById(id, uuid.Nil)
+}
+
+func (s *EventService) Update(id uuid.UUID, event *entity.Event, teaIds []uuid.UUID) (*entity.Event, error) {
+	_, err := s.GetById(id, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.checkEvent(event, teaIds)
+	if err != nil {
+		return nil, err
+	}
+
+	event.Id = id
+	err = s.eventRepository.Update(event, teaIds)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetById(id, uuid.Nil)
+}
+
+func (s *EventService) Delete(id uuid.UUID) error {
+	_, err := s.GetById(id, uuid.Nil)
+	if err != nil {
+		return err
+	}
+
+	err = s.eventRepository.Delete(id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Register signs the user up for the event. A full event puts the user on
+// the waitlist.
+func (s *EventService) Register(id, userId uuid.UUID) (*entity.Event, error) {
+	event, err := s.GetById(id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if event.HasStarted() {
+		err := fmt.Errorf("event with id %s has already started", id.String())
+		return nil, errx.NewBadRequestError(err)
+	}
+
+	_, err = s.eventRepository.Register(id, userId)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetById(id, userId)
+}
+
+func (s *EventService) Cancel(id, userId uuid.UUID) (*entity.Event, error) {
+	event, err := s.GetById(id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if event.HasStarted() {
+		err := fmt.Errorf("event with id %s has already started", id.String())
+		return nil, errx.NewBadRequestError(err)
+	}
+
+	cancelled, err := s.eventRepository.Cancel(id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !cancelled {
+		err := fmt.Errorf("user with id %s is not registered for event with id %s", userId.String(), id.String())
+		return nil, errx.NewNotFoundError(err)
+	}
+	return s.GetById(id, userId)
+}
+
+func (s *EventService) GetAttendees(id uuid.UUID) ([]entity.EventAttendee, error) {
+	_, err := s.GetById(id, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+
+	attendees, err := s.eventRepository.GetAttendees(id)
+	if err != nil {
+		return nil, err
+	}
+	return attendees, nil
+}
+
+func (s *EventService) checkEvent(event *entity.Event, teaIds []uuid.UUID) error {
+	if event.LocationId != nil {
+		err := s.checkLocation(*event.LocationId)
+		if err != nil {
+			return err
+		}
+	}
+
+	teas, err := s.teaRepository.GetAllByIds(teaIds)
+	if err != nil {
+		return err
+	}
+
+	if len(teas) != len(teaIds) {
+		foundTeaIds := make(map[uuid.UUID]struct{}, len(teas))
+		for _, tea := range teas {
+			foundTeaIds[tea.Id] = struct{}{}
+		}
+
+		for _, teaId := range teaIds {
+			if _, ok := foundTeaIds[teaId]; !ok {
+				err := fmt.Errorf("tea with id %s is not found", teaId.String())
+				return errx.NewNotFoundError(err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s *EventService) checkLocation(locationId uuid.UUID) error {
+	exists, err := s.locationRepository.Exists(locationId)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		err := fmt.Errorf("location with id %s is not found", locationId.String())
+		return errx.NewNotFoundError(err)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/errx"
+	"github.com/levchenki/tea-api/internal/jwtx"
+	"time"
+)
+
+const (
+	guestTokenType = "guest"
+	guestTokenTTL  = 180 * 24 * time.Hour
+)
+
+type GuestRepository interface {
+	Create(ttl time.Duration) (*entity.Guest, error)
+	GetById(id uuid.UUID) (*entity.Guest, error)
+	Touch(id uuid.UUID) error
+	GetMarks(guestId uuid.UUID) ([]entity.GuestTeaMark, error)
+	SetMark(guestId, teaId uuid.UUID, mark string) error
+	RemoveMark(guestId, teaId uuid.UUID, mark string) error
+	MergeIntoUser(guestId, userId uuid.UUID) error
+}
+
+type GuestTeaRepository interface {
+	Exists(id uuid.UUID) (bool, error)
+}
+
+type GuestService struct {
+	guestRepository GuestRepository
+	teaRepository   GuestTeaRepository
+}
+
+func NewGuestService(guestRepository GuestRepository, teaRepository GuestTeaRepository) *GuestService {
+	return &GuestService{
+		guestRepository: guestRepository,
+		teaRepository:   teaRepository,
+	}
+}
+
+// CreateGuest registers a new guest and returns its token. The token is
+// signed with the same keys as access tokens and is told apart by its type.
+func (s *GuestService) CreateGuest(jwtKeys *jwtx.KeySet) (string, error) {
+	guest, err := s.guestRepository.Create(guestTokenTTL)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := jwtKeys.Sign(jwt.MapClaims{
+		"typ": guestTokenType,
+		"gid": guest.Id.String(),
+		"iat": guest.CreatedAt.Unix(),
+		"exp": guest.CreatedAt.Add(guestTokenTTL).Unix(),
+	})
+	if err != nil {
+		errResponse := errx.NewInternalServerError(fmt.Errorf("guest token generation error: %w", err))
+		return "", errResponse
+	}
+	return token, nil
+}
+
+func (s *GuestService) Authenticate(guestToken string, jwtKeys *jwtx.KeySet) (uuid.UUID, error) {
+	token, err := jwtKeys.Parse(guestToken)
+	if err != nil {
+		var errResponse *errx.AppError
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			errResponse = errx.NewUnauthorizedError(fmt.Errorf("guest token is expired"))
+		} else {
+			errResponse = errx.NewUnauthorizedError(fmt.Errorf("guest token is invalid"))
+		}
+		return uuid.Nil, errResponse
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != guestTokenType {
+		errResponse := errx.NewUnauthorizedError(fmt.Errorf("guest token is invalid"))
+		return uuid.Nil, errResponse
+	}
+
+	rawGuestId, _ := claims["gid"].(string)
+	guestId, err := uuid.Parse(rawGuestId)
+	if err != nil {
+		errResponse := errx.NewUnauthorizedError(fmt.Errorf("guest token is invalid"))
+		return uuid.Nil, errResponse
+	}
+
+	guest, err := s.guestRepository.GetById(guestId)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	// The guest is deleted once it has been merged into an account.
+	if guest == nil {
+		errResponse := errx.NewUnauthorizedError(fmt.Errorf("guest with id %s is not found", guestId))
+		return uuid.Nil, errResponse
+	}
+
+	err = s.guestRepository.Touch(guestId)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return guestId, nil
+}
+
+func (s *GuestService) GetMarks(guestId uuid.UUID) ([]entity.GuestTeaMark, error) {
+	marks, err := s.guestRepository.GetMarks(guestId)
+	if err != nil {
+		return nil, err
+	}
+	return marks, nil
+}
+
+func (s *GuestService) ToggleMark(guestId, teaId uuid.UUID, mark string, isSet bool) error {
+	exists, err := s.teaRepository.Exists(teaId)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		err := fmt.Errorf("tea with id %s is not found", teaId.String())
+		return errx.NewNotFoundError(err)
+	}
+
+	if isSet {
+		err = s.guestRepository.SetMark(guestId, teaId, mark)
+	} else {
+		err = s.guestRepository.RemoveMark(guestId, teaId, mark)
+	}
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// MergeIntoUser moves the marks of the guest to the user and forgets the
+// guest, so the same token can not be merged twice.
+func (s *GuestService) MergeIntoUser(guestToken string, userId uuid.UUID, jwtKeys *jwtx.KeySet) error {
+	guestId, err := s.Authenticate(guestToken, jwtKeys)
+	if err != nil {
+		return err
+	}
+
+	err = s.guestRepository.MergeIntoUser(guestId, userId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
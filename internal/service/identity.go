@@ -0,0 +1,251 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/errx"
+	"github.com/levchenki/tea-api/internal/jwtx"
+	"github.com/levchenki/tea-api/internal/schemas/userSchemas"
+)
+
+// IdentityProvider verifies credentials of an external account. Every
+// provider has its own set of credentials, e.g. the login widget fields,
+// the mini app init data or a one-time email token.
+type IdentityProvider interface {
+	Name() string
+	Verify(credentials map[string]string) (*entity.ExternalIdentity, error)
+}
+
+type IdentityRepository interface {
+	GetByProviderSubject(provider, subject string) (*entity.Identity, error)
+	GetAllByUserId(userId uuid.UUID) ([]entity.Identity, error)
+	Create(identity *entity.Identity) (*entity.Identity, error)
+	Touch(id uuid.UUID, email string) error
+	Delete(id uuid.UUID) error
+}
+
+// SignIn verifies the credentials with the provider and issues tokens for the
+// user linked to the identity. A user is created on the first sign in.
+func (s *UserService) SignIn(providerName string, credentials map[string]string, device *userSchemas.DeviceInfo, jwtKeys *jwtx.KeySet) (*userSchemas.UserTokens, error) {
+	externalIdentity, err := s.verifyIdentity(providerName, credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.getOrCreateIdentityUser(externalIdentity)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err = s.adminBootstrap.Apply(user)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.createUserTokens(user, device, jwtKeys)
+}
+
+func (s *UserService) RequestEmailLogin(email string) error {
+	provider, ok := s.identityProviders[entity.IdentityProviderEmail].(*EmailIdentityProvider)
+	if !ok {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("identity provider %s is not supported", entity.IdentityProviderEmail))
+		return errResponse
+	}
+	return provider.SendLoginLink(email)
+}
+
+func (s *UserService) GetOIDCAuthorizationURL() (string, error) {
+	provider, ok := s.identityProviders[entity.IdentityProviderOIDC].(*OIDCIdentityProvider)
+	if !ok {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("identity provider %s is not supported", entity.IdentityProviderOIDC))
+		return "", errResponse
+	}
+	return provider.AuthorizationURL()
+}
+
+func (s *UserService) GetIdentities(userId uuid.UUID) ([]entity.Identity, error) {
+	identities, err := s.identityRepository.GetAllByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+// LinkIdentity adds another way of signing in to an existing user, e.g. an
+// email to an account created through Telegram.
+func (s *UserService) LinkIdentity(userId uuid.UUID, providerName string, credentials map[string]string) (*entity.Identity, error) {
+	user, err := s.getExistingUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	externalIdentity, err := s.verifyIdentity(providerName, credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := s.identityRepository.GetByProviderSubject(externalIdentity.Provider, externalIdentity.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	if identity != nil {
+		if identity.UserId != userId {
+			errResponse := errx.NewBadRequestError(fmt.Errorf("identity is already linked to another user"))
+			return nil, errResponse
+		}
+		return identity, nil
+	}
+
+	isTelegram := externalIdentity.Provider == entity.IdentityProviderTelegram
+	if isTelegram && user.TelegramId != 0 {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("another telegram account is already linked"))
+		return nil, errResponse
+	}
+
+	identity, err = s.identityRepository.Create(&entity.Identity{
+		UserId:   userId,
+		Provider: externalIdentity.Provider,
+		Subject:  externalIdentity.Subject,
+		Email:    externalIdentity.Email,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if isTelegram {
+		err = s.userRepository.SetTelegramId(userId, externalIdentity.TelegramId)
+		if err != nil {
+			return nil, err
+		}
+
+		user, err := s.getExistingUser(userId)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = s.adminBootstrap.Apply(user)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return identity, nil
+}
+
+func (s *UserService) UnlinkIdentity(userId, identityId uuid.UUID) error {
+	identities, err := s.identityRepository.GetAllByUserId(userId)
+	if err != nil {
+		return err
+	}
+
+	var identity *entity.Identity
+	for i := range identities {
+		if identities[i].Id == identityId {
+			identity = &identities[i]
+			break
+		}
+	}
+
+	if identity == nil {
+		errResponse := errx.NewNotFoundError(fmt.Errorf("identity with id %s is not found", identityId))
+		return errResponse
+	}
+
+	if len(identities) == 1 {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("the last identity can not be unlinked"))
+		return errResponse
+	}
+
+	err = s.identityRepository.Delete(identityId)
+	if err != nil {
+		return err
+	}
+
+	if identity.Provider == entity.IdentityProviderTelegram {
+		err = s.userRepository.SetTelegramId(userId, 0)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *UserService) verifyIdentity(providerName string, credentials map[string]string) (*entity.ExternalIdentity, error) {
+	provider, ok := s.identityProviders[providerName]
+	if !ok {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("identity provider %s is not supported", providerName))
+		return nil, errResponse
+	}
+	return provider.Verify(credentials)
+}
+
+func (s *UserService) getOrCreateIdentityUser(externalIdentity *entity.ExternalIdentity) (*entity.User, error) {
+	identity, err := s.identityRepository.GetByProviderSubject(externalIdentity.Provider, externalIdentity.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	if identity != nil {
+		err = s.identityRepository.Touch(identity.Id, externalIdentity.Email)
+		if err != nil {
+			return nil, err
+		}
+
+		// Telegram sends the current profile with every sign-in, other
+		// providers only fill in a new user and leave later edits alone.
+		if externalIdentity.Provider == entity.IdentityProviderTelegram {
+			profile := newIdentityUser(externalIdentity)
+			profile.Id = identity.UserId
+			err = s.userRepository.UpdateTelegramProfile(profile, externalIdentity.HasLanguageAndPremium)
+			if err != nil {
+				errResponse := errx.NewInternalServerError(fmt.Errorf("telegram profile sync error: %w", err))
+				return nil, errResponse
+			}
+		}
+		return s.getExistingUser(identity.UserId)
+	}
+
+	userId, err := s.userRepository.Create(newIdentityUser(externalIdentity), &entity.Identity{
+		Provider: externalIdentity.Provider,
+		Subject:  externalIdentity.Subject,
+		Email:    externalIdentity.Email,
+	})
+	if err != nil {
+		errResponse := errx.NewInternalServerError(fmt.Errorf("user creation error: %w", err))
+		return nil, errResponse
+	}
+
+	return s.getExistingUser(userId)
+}
+
+func newIdentityUser(externalIdentity *entity.ExternalIdentity) *entity.User {
+	user := entity.NewEmptyUser(
+		externalIdentity.TelegramId,
+		externalIdentity.FirstName,
+		externalIdentity.LastName,
+		externalIdentity.Username,
+	)
+	user.PhotoUrl = externalIdentity.PhotoUrl
+	user.LanguageCode = externalIdentity.LanguageCode
+	user.IsPremium = externalIdentity.IsPremium
+	return user
+}
+
+func generateRandomToken(size int) (string, error) {
+	b := make([]byte, size)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
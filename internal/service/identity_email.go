@@ -0,0 +1,96 @@
+package service
+
+import (
+	"fmt"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/errx"
+	"github.com/levchenki/tea-api/internal/mailx"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const emailLoginTokenSize = 32
+
+type EmailLoginRepository interface {
+	CreateEmailLoginToken(tokenHash, email string, expiresAt time.Time) error
+	ConsumeEmailLoginToken(tokenHash string) (string, error)
+}
+
+// EmailIdentityProvider signs users in with one-time links sent by email.
+// Only a hash of the token is stored, the token itself exists in the mail.
+type EmailIdentityProvider struct {
+	repository EmailLoginRepository
+	mailSender mailx.Sender
+	appDomain  string
+	ttl        time.Duration
+}
+
+func NewEmailIdentityProvider(repository EmailLoginRepository, mailSender mailx.Sender, appDomain string, ttl time.Duration) *EmailIdentityProvider {
+	return &EmailIdentityProvider{
+		repository: repository,
+		mailSender: mailSender,
+		appDomain:  strings.TrimSuffix(appDomain, "/"),
+		ttl:        ttl,
+	}
+}
+
+func (p *EmailIdentityProvider) Name() string {
+	return entity.IdentityProviderEmail
+}
+
+// SendLoginLink mails a sign-in link to the address. The link leads to the
+// frontend, which posts the token back to the API.
+func (p *EmailIdentityProvider) SendLoginLink(email string) error {
+	token, err := generateRandomToken(emailLoginTokenSize)
+	if err != nil {
+		errResponse := errx.NewInternalServerError(fmt.Errorf("email login token generation error: %w", err))
+		return errResponse
+	}
+
+	err = p.repository.CreateEmailLoginToken(hashToken(token), email, time.Now().Add(p.ttl))
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/auth/email?token=%s", p.appDomain, url.QueryEscape(token))
+	body := fmt.Sprintf(
+		"Follow the link to sign in:\n\n%s\n\nThe link works once and expires in %s. "+
+			"If you did not request it, ignore this email.\n",
+		link, p.ttl,
+	)
+
+	err = p.mailSender.Send(email, "Sign-in link", body)
+	if err != nil {
+		errResponse := errx.NewInternalServerError(fmt.Errorf("sign-in link sending error: %w", err))
+		return errResponse
+	}
+	return nil
+}
+
+func (p *EmailIdentityProvider) Verify(credentials map[string]string) (*entity.ExternalIdentity, error) {
+	token := credentials["token"]
+	if token == "" {
+		errResponse := errx.NewUnauthorizedError(fmt.Errorf("email login token is missing"))
+		return nil, errResponse
+	}
+
+	email, err := p.repository.ConsumeEmailLoginToken(hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	if email == "" {
+		errResponse := errx.NewUnauthorizedError(fmt.Errorf("sign-in link is invalid or expired"))
+		return nil, errResponse
+	}
+
+	firstName, _, _ := strings.Cut(email, "@")
+	externalIdentity := &entity.ExternalIdentity{
+		Provider:  entity.IdentityProviderEmail,
+		Subject:   email,
+		Email:     email,
+		FirstName: firstName,
+	}
+	return externalIdentity, nil
+}
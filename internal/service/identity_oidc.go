@@ -0,0 +1,289 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/errx"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	oidcLoginStateTTL    = 10 * time.Minute
+	oidcRequestTimeout   = 10 * time.Second
+	oidcRandomTokenSize  = 32
+	oidcMaxResponseBytes = 1 << 20
+)
+
+type OIDCConfig struct {
+	Issuer       string
+	ClientId     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+type OIDCLoginStateRepository interface {
+	CreateOIDCLoginState(state *entity.OIDCLoginState) error
+	ConsumeOIDCLoginState(state string) (*entity.OIDCLoginState, error)
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+}
+
+// OIDCIdentityProvider signs users in with any OpenID Connect provider using
+// the authorization code flow with PKCE. The redirect URL points to the
+// frontend, which passes the code and the state on to the API.
+type OIDCIdentityProvider struct {
+	cfg        OIDCConfig
+	repository OIDCLoginStateRepository
+	client     *http.Client
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+}
+
+func NewOIDCIdentityProvider(cfg OIDCConfig, repository OIDCLoginStateRepository) *OIDCIdentityProvider {
+	return &OIDCIdentityProvider{
+		cfg:        cfg,
+		repository: repository,
+		client: &http.Client{
+			Timeout: oidcRequestTimeout,
+		},
+	}
+}
+
+func (p *OIDCIdentityProvider) Name() string {
+	return entity.IdentityProviderOIDC
+}
+
+func (p *OIDCIdentityProvider) AuthorizationURL() (string, error) {
+	discovery, err := p.getDiscovery()
+	if err != nil {
+		errResponse := errx.NewInternalServerError(fmt.Errorf("oidc discovery error: %w", err))
+		return "", errResponse
+	}
+
+	randomTokens := make([]string, 3)
+	for i := range randomTokens {
+		randomTokens[i], err = generateRandomToken(oidcRandomTokenSize)
+		if err != nil {
+			errResponse := errx.NewInternalServerError(fmt.Errorf("oidc state generation error: %w", err))
+			return "", errResponse
+		}
+	}
+
+	loginState := &entity.OIDCLoginState{
+		State:        randomTokens[0],
+		Nonce:        randomTokens[1],
+		CodeVerifier: randomTokens[2],
+		ExpiresAt:    time.Now().Add(oidcLoginStateTTL),
+	}
+
+	err = p.repository.CreateOIDCLoginState(loginState)
+	if err != nil {
+		return "", err
+	}
+
+	authorizationURL, err := url.Parse(discovery.AuthorizationEndpoint)
+	if err != nil {
+		errResponse := errx.NewInternalServerError(fmt.Errorf("invalid oidc authorization endpoint: %w", err))
+		return "", errResponse
+	}
+
+	codeChallenge := sha256.Sum256([]byte(loginState.CodeVerifier))
+	query := authorizationURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.cfg.ClientId)
+	query.Set("redirect_uri", p.cfg.RedirectURL)
+	query.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	query.Set("state", loginState.State)
+	query.Set("nonce", loginState.Nonce)
+	query.Set("code_challenge", base64.RawURLEncoding.EncodeToString(codeChallenge[:]))
+	query.Set("code_challenge_method", "S256")
+	authorizationURL.RawQuery = query.Encode()
+
+	return authorizationURL.String(), nil
+}
+
+func (p *OIDCIdentityProvider) Verify(credentials map[string]string) (*entity.ExternalIdentity, error) {
+	code, state := credentials["code"], credentials["state"]
+	if code == "" || state == "" {
+		errResponse := errx.NewUnauthorizedError(fmt.Errorf("oidc code and state are required"))
+		return nil, errResponse
+	}
+
+	loginState, err := p.repository.ConsumeOIDCLoginState(state)
+	if err != nil {
+		return nil, err
+	}
+
+	if loginState == nil {
+		errResponse := errx.NewUnauthorizedError(fmt.Errorf("oidc state is invalid or expired"))
+		return nil, errResponse
+	}
+
+	discovery, err := p.getDiscovery()
+	if err != nil {
+		errResponse := errx.NewInternalServerError(fmt.Errorf("oidc discovery error: %w", err))
+		return nil, errResponse
+	}
+
+	idToken, err := p.exchangeCode(discovery, code, loginState.CodeVerifier)
+	if err != nil {
+		errResponse := errx.NewUnauthorizedError(fmt.Errorf("oidc code exchange error: %w", err))
+		return nil, errResponse
+	}
+
+	claims, err := p.parseIDToken(discovery, idToken, loginState.Nonce)
+	if err != nil {
+		errResponse := errx.NewUnauthorizedError(fmt.Errorf("oidc id token is invalid: %w", err))
+		return nil, errResponse
+	}
+
+	subject, _ := claims.GetSubject()
+
+	var email string
+	if isVerified, _ := claims["email_verified"].(bool); isVerified {
+		email, _ = claims["email"].(string)
+	}
+
+	firstName, _ := claims["given_name"].(string)
+	if firstName == "" {
+		firstName, _ = claims["name"].(string)
+	}
+	if firstName == "" {
+		firstName, _ = claims["preferred_username"].(string)
+	}
+	lastName, _ := claims["family_name"].(string)
+
+	externalIdentity := &entity.ExternalIdentity{
+		Provider:  entity.IdentityProviderOIDC,
+		Subject:   subject,
+		Email:     email,
+		FirstName: firstName,
+		LastName:  lastName,
+	}
+	return externalIdentity, nil
+}
+
+func (p *OIDCIdentityProvider) getDiscovery() (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	discoveryURL := strings.TrimSuffix(p.cfg.Issuer, "/") + "/.well-known/openid-configuration"
+	response, err := p.client.Get(discoveryURL)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("discovery endpoint responded with status %d", response.StatusCode)
+	}
+
+	discovery := &oidcDiscovery{}
+	err = json.NewDecoder(io.LimitReader(response.Body, oidcMaxResponseBytes)).Decode(discovery)
+	if err != nil {
+		return nil, err
+	}
+
+	if discovery.Issuer != p.cfg.Issuer {
+		return nil, fmt.Errorf("issuer %q does not match the configured one", discovery.Issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" {
+		return nil, fmt.Errorf("discovery document has no authorization or token endpoint")
+	}
+
+	p.discovery = discovery
+	return discovery, nil
+}
+
+func (p *OIDCIdentityProvider) exchangeCode(discovery *oidcDiscovery, code, codeVerifier string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"code_verifier": {codeVerifier},
+	}
+
+	request, err := http.NewRequest(http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	request.SetBasicAuth(url.QueryEscape(p.cfg.ClientId), url.QueryEscape(p.cfg.ClientSecret))
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint responded with status %d", response.StatusCode)
+	}
+
+	var tokenResponse struct {
+		IdToken string `json:"id_token"`
+	}
+	err = json.NewDecoder(io.LimitReader(response.Body, oidcMaxResponseBytes)).Decode(&tokenResponse)
+	if err != nil {
+		return "", err
+	}
+
+	if tokenResponse.IdToken == "" {
+		return "", fmt.Errorf("token response has no id_token")
+	}
+	return tokenResponse.IdToken, nil
+}
+
+// parseIDToken checks the claims of the ID token. Its signature is not
+// verified: the token is received directly from the token endpoint over TLS,
+// which OpenID Connect Core (3.1.3.7) accepts in place of the signature.
+func (p *OIDCIdentityProvider) parseIDToken(discovery *oidcDiscovery, idToken, nonce string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, _, err := jwt.NewParser().ParseUnverified(idToken, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if issuer, _ := claims.GetIssuer(); issuer != discovery.Issuer {
+		return nil, fmt.Errorf("unexpected issuer %q", issuer)
+	}
+
+	if audience, _ := claims.GetAudience(); !slices.Contains(audience, p.cfg.ClientId) {
+		return nil, fmt.Errorf("token is issued for another client")
+	}
+
+	exp, err := claims.GetExpirationTime()
+	if err != nil || exp == nil || exp.Before(time.Now()) {
+		return nil, fmt.Errorf("token is expired")
+	}
+
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return nil, fmt.Errorf("nonce does not match")
+	}
+
+	if subject, _ := claims.GetSubject(); subject == "" {
+		return nil, fmt.Errorf("sub can not be empty")
+	}
+	return claims, nil
+}
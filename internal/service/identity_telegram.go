@@ -0,0 +1,170 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/errx"
+	"github.com/levchenki/tea-api/internal/tgx"
+	"strconv"
+	"time"
+
+	tgMiniApp "github.com/telegram-mini-apps/init-data-golang"
+)
+
+const telegramAuthClockSkew = time.Minute
+
+type TelegramWidgetIdentityProvider struct {
+	botToken    string
+	maxAge      time.Duration
+	replayCache *tgx.ReplayCache
+}
+
+func NewTelegramWidgetIdentityProvider(botToken string, maxAge time.Duration) *TelegramWidgetIdentityProvider {
+	return &TelegramWidgetIdentityProvider{
+		botToken:    botToken,
+		maxAge:      maxAge,
+		replayCache: tgx.NewReplayCache(maxAge + telegramAuthClockSkew),
+	}
+}
+
+func (p *TelegramWidgetIdentityProvider) Name() string {
+	return entity.IdentityProviderTelegram
+}
+
+func (p *TelegramWidgetIdentityProvider) Verify(credentials map[string]string) (*entity.ExternalIdentity, error) {
+	if p.botToken == "" {
+		errResponse := errx.NewInternalServerError(fmt.Errorf("telegram bot token is not configured"))
+		return nil, errResponse
+	}
+
+	if err := tgx.CheckWidgetHash(credentials, p.botToken); err != nil {
+		errResponse := errx.NewUnauthorizedError(fmt.Errorf("telegram verification error: %w", err))
+		return nil, errResponse
+	}
+
+	authDate, err := strconv.ParseInt(credentials["auth_date"], 10, 64)
+	if err != nil {
+		errResponse := errx.NewUnauthorizedError(fmt.Errorf("telegram auth data has no auth_date"))
+		return nil, errResponse
+	}
+
+	if err := checkTelegramAuthDate(time.Unix(authDate, 0), p.maxAge); err != nil {
+		return nil, err
+	}
+
+	telegramId, err := strconv.ParseUint(credentials["id"], 10, 64)
+	if err != nil || telegramId == 0 {
+		errResponse := errx.NewUnauthorizedError(fmt.Errorf("telegram auth data has no valid id"))
+		return nil, errResponse
+	}
+
+	if p.replayCache.Remember(credentials["hash"]) {
+		errResponse := errx.NewUnauthorizedError(fmt.Errorf("telegram auth data has already been used"))
+		return nil, errResponse
+	}
+
+	externalIdentity := &entity.ExternalIdentity{
+		Provider:   entity.IdentityProviderTelegram,
+		Subject:    strconv.FormatUint(telegramId, 10),
+		FirstName:  credentials["first_name"],
+		LastName:   credentials["last_name"],
+		Username:   credentials["username"],
+		PhotoUrl:   credentials["photo_url"],
+		TelegramId: telegramId,
+	}
+	return externalIdentity, nil
+}
+
+type TelegramMiniAppIdentityProvider struct {
+	botToken string
+	maxAge   time.Duration
+}
+
+func NewTelegramMiniAppIdentityProvider(botToken string, maxAge time.Duration) *TelegramMiniAppIdentityProvider {
+	return &TelegramMiniAppIdentityProvider{
+		botToken: botToken,
+		maxAge:   maxAge,
+	}
+}
+
+func (p *TelegramMiniAppIdentityProvider) Name() string {
+	return entity.IdentityProviderTelegramMiniApp
+}
+
+func (p *TelegramMiniAppIdentityProvider) Verify(credentials map[string]string) (*entity.ExternalIdentity, error) {
+	initData := credentials["initData"]
+
+	// The replay cache is not applied here: Telegram hands the same init data
+	// to the mini app for as long as it is open, and a reload sends it again.
+	err := tgMiniApp.Validate(initData, p.botToken, p.maxAge)
+
+	if err != nil {
+		var errResponse *errx.AppError
+		switch {
+		case errors.Is(err, tgMiniApp.ErrExpired):
+			errResponse = errx.NewUnauthorizedError(fmt.Errorf("mini app data is expired"))
+		case errors.Is(err, tgMiniApp.ErrAuthDateMissing), errors.Is(err, tgMiniApp.ErrAuthDateInvalid):
+			errResponse = errx.NewUnauthorizedError(fmt.Errorf("mini app data has no valid auth_date"))
+		case errors.Is(err, tgMiniApp.ErrSignMissing), errors.Is(err, tgMiniApp.ErrSignInvalid):
+			errResponse = errx.NewUnauthorizedError(fmt.Errorf("mini app data signature is invalid"))
+		default:
+			errResponse = errx.NewUnauthorizedError(fmt.Errorf("mini app data is malformed"))
+		}
+		return nil, errResponse
+	}
+
+	parsed, err := tgMiniApp.Parse(initData)
+
+	if err != nil {
+		errResponse := errx.NewUnauthorizedError(fmt.Errorf("mini app data is malformed"))
+		return nil, errResponse
+	}
+
+	if err := checkTelegramAuthDate(parsed.AuthDate(), p.maxAge); err != nil {
+		return nil, err
+	}
+
+	parsedUser := parsed.User
+	if parsedUser.ID <= 0 {
+		errResponse := errx.NewUnauthorizedError(fmt.Errorf("mini app data has no user"))
+		return nil, errResponse
+	}
+	telegramId := uint64(parsedUser.ID)
+
+	externalIdentity := &entity.ExternalIdentity{
+		Provider:     entity.IdentityProviderTelegram,
+		Subject:      strconv.FormatUint(telegramId, 10),
+		FirstName:    parsedUser.FirstName,
+		LastName:     parsedUser.LastName,
+		Username:     parsedUser.Username,
+		PhotoUrl:     parsedUser.PhotoURL,
+		LanguageCode: parsedUser.LanguageCode,
+		IsPremium:    parsedUser.IsPremium,
+		TelegramId:   telegramId,
+
+		HasLanguageAndPremium: true,
+	}
+	return externalIdentity, nil
+}
+
+// checkTelegramAuthDate rejects payloads older than the configured maximum age
+// and payloads from the future beyond a small clock skew.
+func checkTelegramAuthDate(authDate time.Time, maxAge time.Duration) error {
+	if authDate.Unix() <= 0 {
+		errResponse := errx.NewUnauthorizedError(fmt.Errorf("telegram auth data has no auth_date"))
+		return errResponse
+	}
+
+	now := time.Now()
+	if authDate.After(now.Add(telegramAuthClockSkew)) {
+		errResponse := errx.NewUnauthorizedError(fmt.Errorf("telegram auth_date is in the future"))
+		return errResponse
+	}
+
+	if now.Sub(authDate) > maxAge {
+		errResponse := errx.NewUnauthorizedError(fmt.Errorf("telegram auth data is expired"))
+		return errResponse
+	}
+	return nil
+}
@@ -0,0 +1,385 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/errx"
+	"strings"
+)
+
+type ListRepository interface {
+	GetAllByUserId(userId uuid.UUID) ([]entity.TeaList, error)
+	GetById(id uuid.UUID) (*entity.TeaList, error)
+	GetItems(listId uuid.UUID) ([]entity.TeaListItem, error)
+	CreateFavourite(userId uuid.UUID) error
+	Create(list *entity.TeaList) (*entity.TeaList, error)
+	Update(list *entity.TeaList) error
+	Delete(id uuid.UUID) error
+	GetByShareToken(token string) (*entity.TeaList, error)
+	SetShareToken(id uuid.UUID, token string) error
+	ExistsByName(userId, existedId uuid.UUID, name string) (bool, error)
+
+	ItemExists(listId, teaId uuid.UUID) (bool, error)
+	AddItem(listId, teaId uuid.UUID, note string) error
+	UpdateItemNote(listId, teaId uuid.UUID, note string) error
+	RemoveItem(listId, teaId uuid.UUID) error
+	Reorder(listId uuid.UUID, teaIds []uuid.UUID) error
+}
+
+type ListTeaRepository interface {
+	Exists(id uuid.UUID) (bool, error)
+	GetAllByIds(ids []uuid.UUID) ([]entity.Tea, error)
+}
+
+type ListTagRepository interface {
+	GetAllByTeaIds(teaIds []uuid.UUID) (map[uuid.UUID][]entity.Tag, error)
+}
+
+type ListService struct {
+	listRepository ListRepository
+	teaRepository  ListTeaRepository
+	tagRepository  ListTagRepository
+}
+
+func NewListService(
+	listRepository ListRepository,
+	teaRepository ListTeaRepository,
+	tagRepository ListTagRepository,
+) *ListService {
+	return &ListService{
+		listRepository: listRepository,
+		teaRepository:  teaRepository,
+		tagRepository:  tagRepository,
+	}
+}
+
+func (s *ListService) GetAll(userId uuid.UUID) ([]entity.TeaList, error) {
+	err := s.listRepository.CreateFavourite(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	lists, err := s.listRepository.GetAllByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+	return lists, nil
+}
+
+func (s *ListService) GetById(id, userId uuid.UUID) (*entity.TeaList, error) {
+	list, err := s.getOwnedList(id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.listRepository.GetItems(id)
+	if err != nil {
+		return nil, err
+	}
+	list.Items = items
+
+	return list, nil
+}
+
+func (s *ListService) Create(userId uuid.UUID, name string) (*entity.TeaList, error) {
+	err := s.validateName(userId, uuid.Nil, name)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &entity.TeaList{
+		UserId: userId,
+		Name:   name,
+	}
+	createdList, err := s.listRepository.Create(list)
+	if err != nil {
+		return nil, err
+	}
+	createdList.Items = make([]entity.TeaListItem, 0)
+
+	return createdList, nil
+}
+
+func (s *ListService) Update(id, userId uuid.UUID, name string) (*entity.TeaList, error) {
+	list, err := s.getOwnedList(id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if list.IsFavourite {
+		err := fmt.Errorf("list %s is built-in and can not be renamed", list.Name)
+		return nil, errx.NewBadRequestError(err)
+	}
+
+	err = s.validateName(userId, id, name)
+	if err != nil {
+		return nil, err
+	}
+
+	list.Name = name
+	err = s.listRepository.Update(list)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetById(id, userId)
+}
+
+func (s *ListService) Delete(id, userId uuid.UUID) error {
+	list, err := s.getOwnedList(id, userId)
+	if err != nil {
+		return err
+	}
+
+	if list.IsFavourite {
+		err := fmt.Errorf("list %s is built-in and can not be deleted", list.Name)
+		return errx.NewBadRequestError(err)
+	}
+
+	err = s.listRepository.Delete(id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *ListService) AddItem(id, userId, teaId uuid.UUID, note string) (*entity.TeaList, error) {
+	_, err := s.getOwnedList(id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := s.teaRepository.Exists(teaId)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		err := fmt.Errorf("tea with id %s is not found", teaId.String())
+		return nil, errx.NewNotFoundError(err)
+	}
+
+	err = s.listRepository.AddItem(id, teaId, note)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetById(id, userId)
+}
+
+func (s *ListService) UpdateItemNote(id, userId, teaId uuid.UUID, note string) (*entity.TeaList, error) {
+	err := s.checkItem(id, userId, teaId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.listRepository.UpdateItemNote(id, teaId, note)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetById(id, userId)
+}
+
+func (s *ListService) RemoveItem(id, userId, teaId uuid.UUID) error {
+	err := s.checkItem(id, userId, teaId)
+	if err != nil {
+		return err
+	}
+
+	err = s.listRepository.RemoveItem(id, teaId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *ListService) Reorder(id, userId uuid.UUID, teaIds []uuid.UUID) (*entity.TeaList, error) {
+	_, err := s.getOwnedList(id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.listRepository.GetItems(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) != len(teaIds) {
+		err := fmt.Errorf("teaIds must contain every tea of the list exactly once")
+		return nil, errx.NewBadRequestError(err)
+	}
+
+	existedTeaIds := make(map[uuid.UUID]struct{}, len(items))
+	for _, item := range items {
+		existedTeaIds[item.TeaId] = struct{}{}
+	}
+	seen := make(map[uuid.UUID]struct{}, len(teaIds))
+	for _, teaId := range teaIds {
+		if _, ok := existedTeaIds[teaId]; !ok {
+			err := fmt.Errorf("tea with id %s is not in the list", teaId.String())
+			return nil, errx.NewBadRequestError(err)
+		}
+		if _, ok := seen[teaId]; ok {
+			err := fmt.Errorf("tea with id %s is duplicated", teaId.String())
+			return nil, errx.NewBadRequestError(err)
+		}
+		seen[teaId] = struct{}{}
+	}
+
+	err = s.listRepository.Reorder(id, teaIds)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetById(id, userId)
+}
+
+func (s *ListService) Share(id, userId uuid.UUID) (*entity.TeaList, error) {
+	list, err := s.getOwnedList(id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if list.ShareToken != "" {
+		return list, nil
+	}
+
+	token, err := s.generateShareToken()
+	if err != nil {
+		return nil, errx.NewInternalServerError(fmt.Errorf("share token generation error: %w", err))
+	}
+
+	err = s.listRepository.SetShareToken(id, token)
+	if err != nil {
+		return nil, err
+	}
+	list.ShareToken = token
+
+	return list, nil
+}
+
+func (s *ListService) RevokeShare(id, userId uuid.UUID) error {
+	_, err := s.getOwnedList(id, userId)
+	if err != nil {
+		return err
+	}
+
+	err = s.listRepository.SetShareToken(id, "")
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *ListService) GetShared(token string) (*entity.TeaList, error) {
+	list, err := s.listRepository.GetByShareToken(token)
+	if err != nil {
+		return nil, err
+	}
+	if list == nil {
+		err := fmt.Errorf("shared list is not found")
+		return nil, errx.NewNotFoundError(err)
+	}
+
+	items, err := s.listRepository.GetItems(list.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	teaIds := make([]uuid.UUID, len(items))
+	for i, item := range items {
+		teaIds[i] = item.TeaId
+	}
+
+	teas, err := s.teaRepository.GetAllByIds(teaIds)
+	if err != nil {
+		return nil, err
+	}
+
+	tagsByTeaId := make(map[uuid.UUID][]entity.Tag)
+	if len(teaIds) > 0 {
+		tagsByTeaId, err = s.tagRepository.GetAllByTeaIds(teaIds)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	teasById := make(map[uuid.UUID]*entity.Tea, len(teas))
+	for i := range teas {
+		teas[i].Tags = tagsByTeaId[teas[i].Id]
+		teasById[teas[i].Id] = &teas[i]
+	}
+
+	visibleItems := make([]entity.TeaListItem, 0, len(items))
+	for _, item := range items {
+		tea, ok := teasById[item.TeaId]
+		if !ok || tea.IsHidden {
+			continue
+		}
+		item.Tea = tea
+		visibleItems = append(visibleItems, item)
+	}
+	list.Items = visibleItems
+	list.ItemsCount = uint64(len(visibleItems))
+
+	return list, nil
+}
+
+func (s *ListService) generateShareToken() (string, error) {
+	b := make([]byte, 24)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (s *ListService) getOwnedList(id, userId uuid.UUID) (*entity.TeaList, error) {
+	list, err := s.listRepository.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if list == nil || list.UserId != userId {
+		err := fmt.Errorf("list with id %s is not found", id.String())
+		return nil, errx.NewNotFoundError(err)
+	}
+	return list, nil
+}
+
+func (s *ListService) checkItem(id, userId, teaId uuid.UUID) error {
+	_, err := s.getOwnedList(id, userId)
+	if err != nil {
+		return err
+	}
+
+	exists, err := s.listRepository.ItemExists(id, teaId)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		err := fmt.Errorf("tea with id %s is not in the list", teaId.String())
+		return errx.NewNotFoundError(err)
+	}
+	return nil
+}
+
+func (s *ListService) validateName(userId, existedId uuid.UUID, name string) error {
+	if strings.EqualFold(name, entity.FavouriteListName) {
+		err := fmt.Errorf("list name %s is reserved", name)
+		return errx.NewBadRequestError(err)
+	}
+
+	exists, err := s.listRepository.ExistsByName(userId, existedId, name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		err := fmt.Errorf("list with name %s has already existed", name)
+		return errx.NewBadRequestError(err)
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/errx"
+)
+
+type LocationRepository interface {
+	GetAll() ([]entity.Location, error)
+	GetById(id uuid.UUID) (*entity.Location, error)
+	Exists(id uuid.UUID) (bool, error)
+	ExistsByName(existedId uuid.UUID, name string) (bool, error)
+	Create(location *entity.Location) (*entity.Location, error)
+	Update(location *entity.Location) (*entity.Location, error)
+	Delete(id uuid.UUID) error
+
+	GetOverride(teaId, locationId uuid.UUID) (*entity.TeaLocationOverride, error)
+	SetVisibility(teaId, locationId uuid.UUID, isHidden *bool) error
+	SetStock(teaId, locationId uuid.UUID, stock *float64) error
+	SetPrices(teaId, locationId uuid.UUID, servePrice, unitPrice *float64) error
+	DeleteOverride(teaId, locationId uuid.UUID) error
+}
+
+type LocationTeaRepository interface {
+	Exists(id uuid.UUID) (bool, error)
+}
+
+type LocationUserRepository interface {
+	GetById(id uuid.UUID) (*entity.User, error)
+	SetLocation(id, locationId uuid.UUID) error
+}
+
+// LocationService manages the locations and the values of the teas that
+// differ in them. The catalogue values of a tea apply to every location that
+// does not override them.
+type LocationService struct {
+	locationRepository LocationRepository
+	teaRepository      LocationTeaRepository
+	userRepository     LocationUserRepository
+}
+
+func NewLocationService(
+	locationRepository LocationRepository,
+	teaRepository LocationTeaRepository,
+	userRepository LocationUserRepository,
+) *LocationService {
+	return &LocationService{
+		locationRepository: locationRepository,
+		teaRepository:      teaRepository,
+		userRepository:     userRepository,
+	}
+}
+
+func (s *LocationService) GetAll() ([]entity.Location, error) {
+	locations, err := s.locationRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	return locations, nil
+}
+
+func (s *LocationService) GetById(id uuid.UUID) (*entity.Location, error) {
+	location, err := s.locationRepository.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if location == nil {
+		err := fmt.Errorf("location with id %s is not found", id.String())
+		return nil, errx.NewNotFoundError(err)
+	}
+	return location, nil
+}
+
+func (s *LocationService) Create(location *entity.Location) (*entity.Location, error) {
+	exists, err := s.locationRepository.ExistsByName(uuid.Nil, location.Name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		err := fmt.Errorf("location with name %s has already existed", location.Name)
+		return nil, errx.NewBadRequestError(err)
+	}
+
+	createdLocation, err := s.locationRepository.Create(location)
+	if err != nil {
+		return nil, err
+	}
+	return createdLocation, nil
+}
+
+func (s *LocationService) Update(id uuid.UUID, location *entity.Location) (*entity.Location, error) {
+	_, err := s.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := s.locationRepository.ExistsByName(id, location.Name)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		err := fmt.Errorf("location with name %s has already existed", location.Name)
+		return nil, errx.NewBadRequestError(err)
+	}
+
+	location.Id = id
+	updatedLocation, err := s.locationRepository.Update(location)
+	if err != nil {
+		return nil, err
+	}
+	return updatedLocation, nil
+}
+
+func (s *LocationService) Delete(id uuid.UUID) error {
+	location, err := s.GetById(id)
+	if err != nil {
+		return err
+	}
+
+	if location.IsDefault {
+		err := fmt.Errorf("default location can not be deleted")
+		return errx.NewBadRequestError(err)
+	}
+
+	err = s.locationRepository.Delete(id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetOverride returns the values of the tea that differ in the location. A
+// tea without any returns an empty override.
+func (s *LocationService) GetOverride(locationId, teaId uuid.UUID) (*entity.TeaLocationOverride, error) {
+	err := s.checkLocationTea(locationId, teaId)
+	if err != nil {
+		return nil, err
+	}
+	return s.getOverride(locationId, teaId)
+}
+
+func (s *LocationService) SetVisibility(locationId, teaId uuid.UUID, isHidden *bool) (*entity.TeaLocationOverride, error) {
+	err := s.checkLocationTea(locationId, teaId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.locationRepository.SetVisibility(teaId, locationId, isHidden)
+	if err != nil {
+		return nil, err
+	}
+	return s.getOverride(locationId, teaId)
+}
+
+func (s *LocationService) SetStock(locationId, teaId uuid.UUID, stock *float64) (*entity.TeaLocationOverride, error) {
+	err := s.checkLocationTea(locationId, teaId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.locationRepository.SetStock(teaId, locationId, stock)
+	if err != nil {
+		return nil, err
+	}
+	return s.getOverride(locationId, teaId)
+}
+
+func (s *LocationService) SetPrices(locationId, teaId uuid.UUID, servePrice, unitPrice *float64) (*entity.TeaLocationOverride, error) {
+	err := s.checkLocationTea(locationId, teaId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.locationRepository.SetPrices(teaId, locationId, servePrice, unitPrice)
+	if err != nil {
+		return nil, err
+	}
+	return s.getOverride(locationId, teaId)
+}
+
+func (s *LocationService) DeleteOverride(locationId, teaId uuid.UUID) error {
+	err := s.checkLocationTea(locationId, teaId)
+	if err != nil {
+		return err
+	}
+
+	err = s.locationRepository.DeleteOverride(teaId, locationId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetUserLocation scopes the user to the location. The nil location id
+// lets the user work with every location again.
+func (s *LocationService) SetUserLocation(userId, locationId uuid.UUID) error {
+	user, err := s.userRepository.GetById(userId)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		err := fmt.Errorf("user with id %s is not found", userId.String())
+		return errx.NewNotFoundError(err)
+	}
+
+	if locationId != uuid.Nil {
+		_, err = s.GetById(locationId)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = s.userRepository.SetLocation(userId, locationId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *LocationService) getOverride(locationId, teaId uuid.UUID) (*entity.TeaLocationOverride, error) {
+	override, err := s.locationRepository.GetOverride(teaId, locationId)
+	if err != nil {
+		return nil, err
+	}
+
+	if override == nil {
+		override = &entity.TeaLocationOverride{
+			TeaId:      teaId,
+			LocationId: locationId,
+		}
+	}
+	return override, nil
+}
+
+func (s *LocationService) checkLocationTea(locationId, teaId uuid.UUID) error {
+	exists, err := s.locationRepository.Exists(locationId)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		err := fmt.Errorf("location with id %s is not found", locationId.String())
+		return errx.NewNotFoundError(err)
+	}
+
+	exists, err = s.teaRepository.Exists(teaId)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		err := fmt.Errorf("tea with id %s is not found", teaId.String())
+		return errx.NewNotFoundError(err)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/errx"
+	"github.com/levchenki/tea-api/internal/schemas/loyaltySchemas"
+)
+
+type LoyaltyRepository interface {
+	GetBalance(userId uuid.UUID) (int, error)
+	GetHistory(userId uuid.UUID, filters *loyaltySchemas.Filters) ([]entity.LoyaltyTransaction, uint64, error)
+	Adjust(userId uuid.UUID, points int, reason string, createdBy uuid.UUID) (bool, error)
+	GetRules() ([]entity.LoyaltyRule, error)
+	GetRule(categoryId uuid.UUID) (*entity.LoyaltyRule, error)
+	SetRule(rule *entity.LoyaltyRule) error
+}
+
+type LoyaltyUserRepository interface {
+	GetById(id uuid.UUID) (*entity.User, error)
+}
+
+// LoyaltyService reads and adjusts the loyalty ledger. Points are earned and
+// redeemed along with the orders, see OrderService.
+type LoyaltyService struct {
+	loyaltyRepository LoyaltyRepository
+	userRepository    LoyaltyUserRepository
+	pointValue        float64
+}
+
+func NewLoyaltyService(
+	loyaltyRepository LoyaltyRepository,
+	userRepository LoyaltyUserRepository,
+	pointValue float64,
+) *LoyaltyService {
+	return &LoyaltyService{
+		loyaltyRepository: loyaltyRepository,
+		userRepository:    userRepository,
+		pointValue:        pointValue,
+	}
+}
+
+func (s *LoyaltyService) GetBalance(userId uuid.UUID) (*entity.LoyaltyBalance, error) {
+	err := s.checkUser(userId)
+	if err != nil {
+		return nil, err
+	}
+	return s.getBalance(userId)
+}
+
+func (s *LoyaltyService) GetHistory(userId uuid.UUID, filters *loyaltySchemas.Filters) ([]entity.LoyaltyTransaction, uint64, error) {
+	err := s.checkUser(userId)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	transactions, total, err := s.loyaltyRepository.GetHistory(userId, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+	return transactions, total, nil
+}
+
+// Adjust changes the balance of the user on behalf of the staff member. The
+// balance can not go below zero. Staff can not adjust their own balance.
+func (s *LoyaltyService) Adjust(userId uuid.UUID, points int, reason string, staffId uuid.UUID) (*entity.LoyaltyBalance, error) {
+	if userId == staffId {
+		errResponse := errx.NewForbiddenError(fmt.Errorf("own loyalty balance can not be adjusted"))
+		return nil, errResponse
+	}
+
+	err := s.checkUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.loyaltyRepository.Adjust(userId, points, reason, staffId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		err := fmt.Errorf("balance of user with id %s can not go below zero", userId.String())
+		return nil, errx.NewBadRequestError(err)
+	}
+	return s.getBalance(userId)
+}
+
+func (s *LoyaltyService) GetRules() ([]entity.LoyaltyRule, error) {
+	rules, err := s.loyaltyRepository.GetRules()
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (s *LoyaltyService) SetRule(categoryId uuid.UUID, servePoints int, amountPercent float64) (*entity.LoyaltyRule, error) {
+	rule, err := s.getRule(categoryId)
+	if err != nil {
+		return nil, err
+	}
+
+	rule.ServePoints = servePoints
+	rule.AmountPercent = amountPercent
+	err = s.loyaltyRepository.SetRule(rule)
+	if err != nil {
+		return nil, err
+	}
+	return s.getRule(categoryId)
+}
+
+func (s *LoyaltyService) getBalance(userId uuid.UUID) (*entity.LoyaltyBalance, error) {
+	balance, err := s.loyaltyRepository.GetBalance(userId)
+	if err != nil {
+		return nil, err
+	}
+	return &entity.LoyaltyBalance{
+		Balance:    balance,
+		PointValue: s.pointValue,
+	}, nil
+}
+
+func (s *LoyaltyService) getRule(categoryId uuid.UUID) (*entity.LoyaltyRule, error) {
+	rule, err := s.loyaltyRepository.GetRule(categoryId)
+	if err != nil {
+		return nil, err
+	}
+
+	if rule == nil {
+		err := fmt.Errorf("category with id %s is not found", categoryId.String())
+		return nil, errx.NewNotFoundError(err)
+	}
+	return rule, nil
+}
+
+func (s *LoyaltyService) checkUser(userId uuid.UUID) error {
+	user, err := s.userRepository.GetById(userId)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		err := fmt.Errorf("user with id %s is not found", userId.String())
+		return errx.NewNotFoundError(err)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/logx"
+	"github.com/levchenki/tea-api/internal/notifyx"
+	"time"
+)
+
+type NotificationRepository interface {
+	EnqueueOrderNotification(userId uuid.UUID, text string) error
+	EnqueueFavouriteNotifications(teaId uuid.UUID, text string) error
+
+	ClaimDue(limit int, lease time.Duration) ([]entity.Notification, error)
+	Delete(id uuid.UUID) error
+	Reschedule(id uuid.UUID, delay time.Duration, lastError string) error
+	MarkFailed(id uuid.UUID, lastError string) error
+}
+
+// NotificationService puts notifications into the queue. Notifications are
+// a side effect of the action that caused them, so failures are logged and
+// never fail the action itself.
+type NotificationService struct {
+	notificationRepository NotificationRepository
+	log                    logx.AppLogger
+}
+
+func NewNotificationService(notificationRepository NotificationRepository, log logx.AppLogger) *NotificationService {
+	return &NotificationService{
+		notificationRepository: notificationRepository,
+		log:                    log,
+	}
+}
+
+func (s *NotificationService) NotifyOrderReady(order *entity.Order) {
+	if order.UserId == uuid.Nil {
+		return
+	}
+
+	text := fmt.Sprintf("Your order #%d is ready, you can pick it up at the bar.", order.Number)
+	err := s.notificationRepository.EnqueueOrderNotification(order.UserId, text)
+	if err != nil {
+		s.log.Error("Failed to enqueue order notification", "orderId", order.Id, "error", err.Error())
+	}
+}
+
+func (s *NotificationService) NotifyTeaBack(tea *entity.Tea) {
+	text := fmt.Sprintf("%s from your favourites is available again.", tea.Name)
+	err := s.notificationRepository.EnqueueFavouriteNotifications(tea.Id, text)
+	if err != nil {
+		s.log.Error("Failed to enqueue favourite notifications", "teaId", tea.Id, "error", err.Error())
+	}
+}
+
+const (
+	notificationBatchSize   = 50
+	notificationLease       = time.Minute
+	notificationBaseBackoff = 30 * time.Second
+	notificationMaxBackoff  = time.Hour
+)
+
+// NotificationDispatcher sends queued notifications through the messenger.
+// Failed sends are retried with a growing delay until the attempts run out
+// or the messenger reports that the message can never be delivered.
+type NotificationDispatcher struct {
+	notificationRepository NotificationRepository
+	messenger              notifyx.Messenger
+	interval               time.Duration
+	maxAttempts            int
+	log                    logx.AppLogger
+}
+
+func NewNotificationDispatcher(
+	notificationRepository NotificationRepository,
+	messenger notifyx.Messenger,
+	interval time.Duration,
+	maxAttempts int,
+	log logx.AppLogger,
+) *NotificationDispatcher {
+	return &NotificationDispatcher{
+		notificationRepository: notificationRepository,
+		messenger:              messenger,
+		interval:               interval,
+		maxAttempts:            maxAttempts,
+		log:                    log,
+	}
+}
+
+// Run dispatches the queue every interval until the context is done.
+func (d *NotificationDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		err := d.Dispatch()
+		if err != nil {
+			d.log.Error("Failed to dispatch notifications", "error", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch sends the notifications that are due, a batch at a time, until
+// none are left.
+func (d *NotificationDispatcher) Dispatch() error {
+	for {
+		notifications, err := d.notificationRepository.ClaimDue(notificationBatchSize, notificationLease)
+		if err != nil {
+			return err
+		}
+
+		for i := range notifications {
+			err = d.send(&notifications[i])
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(notifications) < notificationBatchSize {
+			return nil
+		}
+	}
+}
+
+func (d *NotificationDispatcher) send(notification *entity.Notification) error {
+	errSend := d.messenger.Send(notification.Recipient, notification.Text)
+	if errSend == nil {
+		return d.notificationRepository.Delete(notification.Id)
+	}
+
+	if notifyx.IsPermanent(errSend) || notification.Attempts >= d.maxAttempts {
+		d.log.Error("Notification is not delivered",
+			"notificationId", notification.Id,
+			"attempts", notification.Attempts,
+			"error", errSend.Error(),
+		)
+		return d.notificationRepository.MarkFailed(notification.Id, errSend.Error())
+	}
+
+	return d.notificationRepository.Reschedule(notification.Id, notificationBackoff(notification.Attempts), errSend.Error())
+}
+
+// notificationBackoff doubles the delay after every failed attempt.
+func notificationBackoff(attempts int) time.Duration {
+	backoff := notificationBaseBackoff
+	for i := 1; i < attempts && backoff < notificationMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, notificationMaxBackoff)
+}
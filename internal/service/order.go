@@ -0,0 +1,593 @@
+package service
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/errx"
+	"github.com/levchenki/tea-api/internal/schemas/orderSchemas"
+	"math"
+	"slices"
+	"strings"
+)
+
+type CartRepository interface {
+	GetAllByUserId(userId uuid.UUID) ([]entity.CartItem, error)
+	GetById(userId, id uuid.UUID) (*entity.CartItem, error)
+	Save(item *entity.CartItem) error
+	RefreshPrices(userId uuid.UUID) error
+	Delete(userId, id uuid.UUID) error
+	Clear(userId uuid.UUID) error
+}
+
+type OrderRepository interface {
+	GetAllByUserId(userId uuid.UUID, filters *orderSchemas.Filters) ([]entity.Order, uint64, error)
+	GetById(id uuid.UUID) (*entity.Order, error)
+	Create(order *entity.Order) (uuid.UUID, bool, error)
+	GetActive(locationId uuid.UUID) ([]entity.Order, error)
+	SetStatus(id uuid.UUID, from, to string) (bool, error)
+}
+
+type OrderTeaRepository interface {
+	GetById(id uuid.UUID) (*entity.TeaWithRating, error)
+}
+
+type OrderLocationRepository interface {
+	GetDefault() (*entity.Location, error)
+	Exists(id uuid.UUID) (bool, error)
+	GetOverride(teaId, locationId uuid.UUID) (*entity.TeaLocationOverride, error)
+}
+
+type OrderLoyaltyRepository interface {
+	GetBalance(userId uuid.UUID) (int, error)
+}
+
+// CartPricer applies the discounts to the cart, see DiscountService.
+type CartPricer interface {
+	PriceCart(userId uuid.UUID, items []entity.CartItem) (*entity.Cart, error)
+	ApplyPromoCode(userId uuid.UUID, code string, items []entity.CartItem) (*entity.Cart, error)
+	RemovePromoCode(userId uuid.UUID, items []entity.CartItem) (*entity.Cart, error)
+}
+
+type OrderEventPublisher interface {
+	Publish(event entity.OrderEvent)
+}
+
+type OrderNotifier interface {
+	NotifyOrderReady(order *entity.Order)
+}
+
+type OrderService struct {
+	cartRepository     CartRepository
+	orderRepository    OrderRepository
+	teaRepository      OrderTeaRepository
+	locationRepository OrderLocationRepository
+	loyaltyRepository  OrderLoyaltyRepository
+	pricer             CartPricer
+	orderEvents        OrderEventPublisher
+	notifier           OrderNotifier
+	pointValue         float64
+}
+
+func NewOrderService(
+	cartRepository CartRepository,
+	orderRepository OrderRepository,
+	teaRepository OrderTeaRepository,
+	locationRepository OrderLocationRepository,
+	loyaltyRepository OrderLoyaltyRepository,
+	pricer CartPricer,
+	orderEvents OrderEventPublisher,
+	notifier OrderNotifier,
+	pointValue float64,
+) *OrderService {
+	return &OrderService{
+		cartRepository:     cartRepository,
+		orderRepository:    orderRepository,
+		teaRepository:      teaRepository,
+		locationRepository: locationRepository,
+		loyaltyRepository:  loyaltyRepository,
+		pricer:             pricer,
+		orderEvents:        orderEvents,
+		notifier:           notifier,
+		pointValue:         pointValue,
+	}
+}
+
+// GetCart returns the cart of the user priced with the discounts.
+func (s *OrderService) GetCart(userId uuid.UUID) (*entity.Cart, error) {
+	items, err := s.cartRepository.GetAllByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+	return s.pricer.PriceCart(userId, items)
+}
+
+// AddCartItem puts the tea into the cart. If the cart already has the tea in
+// the same kind, the quantities are added up. The first tea sets the location
+// the cart is ordered from, the default one unless requested otherwise.
+func (s *OrderService) AddCartItem(userId uuid.UUID, request *orderSchemas.CartItemRequestModel) (*entity.Cart, error) {
+	items, err := s.cartRepository.GetAllByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	requestedLocationId := uuid.Nil
+	if request.LocationId != nil {
+		requestedLocationId = *request.LocationId
+	}
+
+	locationId, err := s.getCartLocationId(items, requestedLocationId)
+	if err != nil {
+		return nil, err
+	}
+
+	quantity := request.Quantity
+	for _, item := range items {
+		if item.TeaId == request.TeaId && item.Kind == request.Kind {
+			quantity += item.Quantity
+		}
+	}
+
+	err = orderSchemas.ValidateQuantity(request.Kind, quantity)
+	if err != nil {
+		return nil, errx.NewBadRequestError(err)
+	}
+
+	err = s.saveCartItem(userId, locationId, request.TeaId, request.Kind, quantity)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetCart(userId)
+}
+
+func (s *OrderService) UpdateCartItem(userId, id uuid.UUID, quantity float64) (*entity.Cart, error) {
+	item, err := s.getExistingCartItem(userId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = orderSchemas.ValidateQuantity(item.Kind, quantity)
+	if err != nil {
+		return nil, errx.NewBadRequestError(err)
+	}
+
+	err = s.saveCartItem(userId, item.LocationId, item.TeaId, item.Kind, quantity)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetCart(userId)
+}
+
+func (s *OrderService) DeleteCartItem(userId, id uuid.UUID) (*entity.Cart, error) {
+	_, err := s.getExistingCartItem(userId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.cartRepository.Delete(userId, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetCart(userId)
+}
+
+// SetPromoCode enters the promo code for the cart. Returns 400 if it can not
+// be used with the cart.
+func (s *OrderService) SetPromoCode(userId uuid.UUID, code string) (*entity.Cart, error) {
+	items, err := s.cartRepository.GetAllByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+	return s.pricer.ApplyPromoCode(userId, code, items)
+}
+
+func (s *OrderService) RemovePromoCode(userId uuid.UUID) (*entity.Cart, error) {
+	items, err := s.cartRepository.GetAllByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+	return s.pricer.RemovePromoCode(userId, items)
+}
+
+func (s *OrderService) ClearCart(userId uuid.UUID) error {
+	err := s.cartRepository.Clear(userId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// PlaceOrder turns the cart into an order. If a price has changed since the
+// tea was put into the cart, the cart gets the new prices and the order is
+// not placed, so the customer never pays a price they have not seen. The
+// order gets the discounts of the cart, the redeemed loyalty points discount
+// the rest.
+func (s *OrderService) PlaceOrder(userId uuid.UUID, request *orderSchemas.OrderRequestModel) (*entity.Order, error) {
+	items, err := s.cartRepository.GetAllByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("cart is empty"))
+		return nil, errResponse
+	}
+
+	order := &entity.Order{
+		UserId:     userId,
+		LocationId: &items[0].LocationId,
+		Status:     entity.OrderStatusNew,
+		Comment:    request.Comment,
+		Items:      make([]entity.OrderItem, 0, len(items)),
+	}
+
+	changedPrices := make([]string, 0)
+	for _, item := range items {
+		// Teas added concurrently to an empty cart could have got different
+		// locations.
+		if item.LocationId != *order.LocationId {
+			errResponse := errx.NewBadRequestError(fmt.Errorf("cart has teas of several locations, check the cart"))
+			return nil, errResponse
+		}
+
+		err = checkOrderItem(item.TeaName, item.IsHidden, item.Stock, item.CurrentPrice, item.Kind, item.Quantity)
+		if err != nil {
+			return nil, err
+		}
+
+		if item.Price != item.CurrentPrice {
+			changedPrices = append(changedPrices, item.TeaName)
+		}
+
+		teaId := item.TeaId
+		order.Items = append(order.Items, entity.OrderItem{
+			TeaId:    &teaId,
+			TeaName:  item.TeaName,
+			Kind:     item.Kind,
+			Quantity: item.Quantity,
+			Price:    item.Price,
+			Amount:   item.Amount(),
+		})
+	}
+
+	if len(changedPrices) > 0 {
+		err = s.cartRepository.RefreshPrices(userId)
+		if err != nil {
+			return nil, err
+		}
+		err = fmt.Errorf("prices of %s have changed, check the cart before placing the order", strings.Join(changedPrices, ", "))
+		return nil, errx.NewConflictError(err)
+	}
+
+	cart, err := s.pricer.PriceCart(userId, items)
+	if err != nil {
+		return nil, err
+	}
+	order.Discounts = cart.Discounts
+	order.Discount = cart.Discount
+	order.Total = cart.Total
+
+	if request.RedeemPoints > 0 {
+		err = s.applyLoyaltyPoints(userId, order, request.RedeemPoints)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Teas are locked in the same order by concurrent orders to avoid deadlocks.
+	slices.SortFunc(order.Items, func(a, b entity.OrderItem) int {
+		return strings.Compare(a.TeaId.String(), b.TeaId.String())
+	})
+
+	id, ok, err := s.orderRepository.Create(order)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		// A discount could have been used up by other orders in the meantime.
+		if len(order.Discounts) > 0 {
+			err = s.checkDiscounts(userId, order.Discounts)
+			if err != nil {
+				return nil, err
+			}
+		}
+		// The points could have been spent by another order in the meantime.
+		if order.RedeemedPoints > 0 {
+			err = s.checkLoyaltyBalance(userId, order.RedeemedPoints)
+			if err != nil {
+				return nil, err
+			}
+		}
+		errResponse := errx.NewBadRequestError(fmt.Errorf("some teas in the cart are no longer available, check the cart"))
+		return nil, errResponse
+	}
+
+	placedOrder, err := s.GetOrder(userId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.orderEvents.Publish(entity.OrderEvent{
+		Type:  entity.OrderEventCreated,
+		Order: placedOrder,
+	})
+	return placedOrder, nil
+}
+
+func (s *OrderService) GetOrders(userId uuid.UUID, filters *orderSchemas.Filters) ([]entity.Order, uint64, error) {
+	orders, total, err := s.orderRepository.GetAllByUserId(userId, filters)
+	if err != nil {
+		return nil, 0, err
+	}
+	return orders, total, nil
+}
+
+func (s *OrderService) GetOrder(userId, id uuid.UUID) (*entity.Order, error) {
+	order, err := s.orderRepository.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if order == nil || order.UserId != userId {
+		errResponse := errx.NewNotFoundError(fmt.Errorf("order with id %s is not found", id))
+		return nil, errResponse
+	}
+	return order, nil
+}
+
+// GetQueue returns the orders the bar of the location still has to work on,
+// the oldest first. The nil location id gives the queues of all locations.
+func (s *OrderService) GetQueue(locationId uuid.UUID) ([]entity.Order, error) {
+	if locationId != uuid.Nil {
+		err := s.checkLocation(locationId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	orders, err := s.orderRepository.GetActive(locationId)
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// SetStatus moves the order along its workflow. The nil location id lets
+// the order be of any location, otherwise returns 403 for orders of other
+// locations. Returns 409 if somebody else has changed the status in the
+// meantime.
+func (s *OrderService) SetStatus(id uuid.UUID, status string, locationId uuid.UUID) (*entity.Order, error) {
+	order, err := s.orderRepository.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if order == nil {
+		errResponse := errx.NewNotFoundError(fmt.Errorf("order with id %s is not found", id))
+		return nil, errResponse
+	}
+
+	if locationId != uuid.Nil && (order.LocationId == nil || *order.LocationId != locationId) {
+		errResponse := errx.NewForbiddenError(fmt.Errorf("order with id %s is of another location", id))
+		return nil, errResponse
+	}
+
+	if !order.CanMoveTo(status) {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("order can not be moved from %s to %s", order.Status, status))
+		return nil, errResponse
+	}
+
+	ok, err := s.orderRepository.SetStatus(id, order.Status, status)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		errResponse := errx.NewConflictError(fmt.Errorf("order status has been changed by someone else, reload the queue"))
+		return nil, errResponse
+	}
+
+	updatedOrder, err := s.orderRepository.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if updatedOrder == nil {
+		errResponse := errx.NewNotFoundError(fmt.Errorf("order with id %s is not found", id))
+		return nil, errResponse
+	}
+
+	s.orderEvents.Publish(entity.OrderEvent{
+		Type:  entity.OrderEventUpdated,
+		Order: updatedOrder,
+	})
+
+	if updatedOrder.Status == entity.OrderStatusReady {
+		s.notifier.NotifyOrderReady(updatedOrder)
+	}
+	return updatedOrder, nil
+}
+
+// applyLoyaltyPoints discounts the order by the value of the points. The
+// points can not cover more than the order total after the other discounts.
+func (s *OrderService) applyLoyaltyPoints(userId uuid.UUID, order *entity.Order, points int) error {
+	if s.pointValue <= 0 {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("loyalty points can not be redeemed"))
+		return errResponse
+	}
+
+	discount := entity.RoundPrice(float64(points) * s.pointValue)
+	if discount > order.Total {
+		maxPoints := int(math.Floor(order.Total/s.pointValue + 1e-9))
+		errResponse := errx.NewBadRequestError(fmt.Errorf("at most %d points can be redeemed against the order", maxPoints))
+		return errResponse
+	}
+
+	err := s.checkLoyaltyBalance(userId, points)
+	if err != nil {
+		return err
+	}
+
+	order.Discount = entity.RoundPrice(order.Discount + discount)
+	order.RedeemedPoints = points
+	order.Total = entity.RoundPrice(order.Total - discount)
+	return nil
+}
+
+// checkDiscounts returns 409 if the cart no longer gets the discounts.
+func (s *OrderService) checkDiscounts(userId uuid.UUID, discounts []entity.AppliedDiscount) error {
+	items, err := s.cartRepository.GetAllByUserId(userId)
+	if err != nil {
+		return err
+	}
+
+	cart, err := s.pricer.PriceCart(userId, items)
+	if err != nil {
+		return err
+	}
+
+	if !slices.Equal(cart.Discounts, discounts) {
+		errResponse := errx.NewConflictError(fmt.Errorf("discounts have changed, check the cart before placing the order"))
+		return errResponse
+	}
+	return nil
+}
+
+func (s *OrderService) checkLoyaltyBalance(userId uuid.UUID, points int) error {
+	balance, err := s.loyaltyRepository.GetBalance(userId)
+	if err != nil {
+		return err
+	}
+
+	if balance < points {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("not enough loyalty points, the balance is %d", balance))
+		return errResponse
+	}
+	return nil
+}
+
+// getCartLocationId returns the location of the cart. An empty cart takes
+// the requested location or the default one, the nil location id requests
+// none. A cart with items is ordered from their location only.
+func (s *OrderService) getCartLocationId(items []entity.CartItem, locationId uuid.UUID) (uuid.UUID, error) {
+	if len(items) > 0 {
+		if locationId != uuid.Nil && locationId != items[0].LocationId {
+			err := fmt.Errorf("cart has teas of another location, clear it to order from this one")
+			return uuid.Nil, errx.NewBadRequestError(err)
+		}
+		return items[0].LocationId, nil
+	}
+
+	if locationId != uuid.Nil {
+		err := s.checkLocation(locationId)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return locationId, nil
+	}
+
+	location, err := s.locationRepository.GetDefault()
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	if location == nil {
+		errResponse := errx.NewInternalServerError(fmt.Errorf("default location is not found"))
+		return uuid.Nil, errResponse
+	}
+	return location.Id, nil
+}
+
+func (s *OrderService) checkLocation(locationId uuid.UUID) error {
+	exists, err := s.locationRepository.Exists(locationId)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		errResponse := errx.NewNotFoundError(fmt.Errorf("location with id %s is not found", locationId))
+		return errResponse
+	}
+	return nil
+}
+
+// saveCartItem prices and checks the tea by its values in the location.
+func (s *OrderService) saveCartItem(userId, locationId, teaId uuid.UUID, kind string, quantity float64) error {
+	tea, err := s.teaRepository.GetById(teaId)
+	if err != nil {
+		return err
+	}
+
+	if tea == nil {
+		errResponse := errx.NewNotFoundError(fmt.Errorf("tea with id %s is not found", teaId))
+		return errResponse
+	}
+
+	override, err := s.locationRepository.GetOverride(teaId, locationId)
+	if err != nil {
+		return err
+	}
+
+	if override != nil {
+		override.ApplyTo(&tea.Tea)
+	}
+
+	price := entity.TeaPrice(&tea.Tea, kind)
+	err = checkOrderItem(tea.Name, tea.IsHidden, tea.Stock, price, kind, quantity)
+	if err != nil {
+		return err
+	}
+
+	return s.cartRepository.Save(&entity.CartItem{
+		UserId:     userId,
+		LocationId: locationId,
+		TeaId:      teaId,
+		Kind:       kind,
+		Quantity:   quantity,
+		Price:      price,
+	})
+}
+
+func (s *OrderService) getExistingCartItem(userId, id uuid.UUID) (*entity.CartItem, error) {
+	item, err := s.cartRepository.GetById(userId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if item == nil {
+		errResponse := errx.NewNotFoundError(fmt.Errorf("cart item with id %s is not found", id))
+		return nil, errResponse
+	}
+	return item, nil
+}
+
+// checkOrderItem tells whether the tea can be ordered in the kind and the
+// quantity. The stock is kept in units of the tea, so only weight items are
+// compared with it, serves just need the tea to be in stock.
+func checkOrderItem(teaName string, isHidden bool, stock *float64, price float64, kind string, quantity float64) error {
+	if isHidden {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("tea %s is not available", teaName))
+		return errResponse
+	}
+
+	if price <= 0 {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("tea %s can not be ordered as %s", teaName, kind))
+		return errResponse
+	}
+
+	if stock == nil {
+		return nil
+	}
+
+	if *stock <= 0 {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("tea %s is out of stock", teaName))
+		return errResponse
+	}
+
+	if kind == entity.OrderItemKindWeight && quantity > *stock {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("only %g of tea %s is left", *stock, teaName))
+		return errResponse
+	}
+	return nil
+}
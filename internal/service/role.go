@@ -0,0 +1,226 @@
+package service
+
+import (
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/levchenki/tea-api/internal/entity"
+	"github.com/levchenki/tea-api/internal/errx"
+	"github.com/levchenki/tea-api/internal/schemas/roleSchemas"
+)
+
+type RoleRepository interface {
+	GetAllPermissions() ([]entity.Permission, error)
+	CountExistingPermissions(codes []string) (int, error)
+
+	GetAll() ([]entity.Role, error)
+	GetById(id uuid.UUID) (*entity.Role, error)
+	GetByUserId(userId uuid.UUID) ([]entity.Role, error)
+	Create(role *entity.Role) (*entity.Role, error)
+	Update(role *entity.Role) (*entity.Role, error)
+	Delete(id uuid.UUID) error
+	ExistsByName(existedId uuid.UUID, name string) (bool, error)
+
+	AssignToUser(userId, roleId uuid.UUID) error
+	UnassignFromUser(userId, roleId uuid.UUID) error
+}
+
+type RoleUserRepository interface {
+	GetById(userId uuid.UUID) (*entity.User, error)
+}
+
+type RoleService struct {
+	roleRepository RoleRepository
+	userRepository RoleUserRepository
+}
+
+func NewRoleService(roleRepository RoleRepository, userRepository RoleUserRepository) *RoleService {
+	return &RoleService{
+		roleRepository: roleRepository,
+		userRepository: userRepository,
+	}
+}
+
+func (s *RoleService) GetAllPermissions() ([]entity.Permission, error) {
+	permissions, err := s.roleRepository.GetAllPermissions()
+	if err != nil {
+		return nil, err
+	}
+	return permissions, nil
+}
+
+func (s *RoleService) GetAll() ([]entity.Role, error) {
+	roles, err := s.roleRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (s *RoleService) Create(role *roleSchemas.RequestModel) (*entity.Role, error) {
+	err := s.validate(uuid.Nil, role)
+	if err != nil {
+		return nil, err
+	}
+
+	createdRole, err := s.roleRepository.Create(&entity.Role{
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: role.Permissions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return createdRole, nil
+}
+
+func (s *RoleService) Update(id uuid.UUID, role *roleSchemas.RequestModel) (*entity.Role, error) {
+	_, err := s.getEditableRole(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.validate(id, role)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedRole, err := s.roleRepository.Update(&entity.Role{
+		Id:          id,
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: role.Permissions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updatedRole, nil
+}
+
+func (s *RoleService) Delete(id uuid.UUID) error {
+	_, err := s.getEditableRole(id)
+	if err != nil {
+		return err
+	}
+
+	err = s.roleRepository.Delete(id)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *RoleService) GetUserRoles(userId uuid.UUID) ([]entity.Role, error) {
+	err := s.checkUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := s.roleRepository.GetByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (s *RoleService) AssignToUser(userId, roleId uuid.UUID) ([]entity.Role, error) {
+	err := s.checkUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.getExistingRole(roleId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.roleRepository.AssignToUser(userId, roleId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.roleRepository.GetByUserId(userId)
+}
+
+func (s *RoleService) UnassignFromUser(actorId, userId, roleId uuid.UUID) ([]entity.Role, error) {
+	err := s.checkUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	role, err := s.getExistingRole(roleId)
+	if err != nil {
+		return nil, err
+	}
+
+	if actorId == userId && role.Name == entity.RoleAdmin {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("role %s can not be removed from yourself", role.Name))
+		return nil, errResponse
+	}
+
+	err = s.roleRepository.UnassignFromUser(userId, roleId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.roleRepository.GetByUserId(userId)
+}
+
+func (s *RoleService) validate(existedId uuid.UUID, role *roleSchemas.RequestModel) error {
+	exists, err := s.roleRepository.ExistsByName(existedId, role.Name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		err := fmt.Errorf("role with name %s has already existed", role.Name)
+		return errx.NewBadRequestError(err)
+	}
+
+	count, err := s.roleRepository.CountExistingPermissions(role.Permissions)
+	if err != nil {
+		return err
+	}
+	if count != len(role.Permissions) {
+		err := fmt.Errorf("permissions contain unknown values")
+		return errx.NewBadRequestError(err)
+	}
+	return nil
+}
+
+func (s *RoleService) getExistingRole(id uuid.UUID) (*entity.Role, error) {
+	role, err := s.roleRepository.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if role == nil {
+		err := fmt.Errorf("role with id %s is not found", id.String())
+		return nil, errx.NewNotFoundError(err)
+	}
+	return role, nil
+}
+
+func (s *RoleService) getEditableRole(id uuid.UUID) (*entity.Role, error) {
+	role, err := s.getExistingRole(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if role.IsSystem {
+		err := fmt.Errorf("role %s is built-in and can not be changed", role.Name)
+		return nil, errx.NewBadRequestError(err)
+	}
+	return role, nil
+}
+
+func (s *RoleService) checkUser(userId uuid.UUID) error {
+	user, err := s.userRepository.GetById(userId)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		err := fmt.Errorf("user with id %s is not found", userId.String())
+		return errx.NewNotFoundError(err)
+	}
+	return nil
+}
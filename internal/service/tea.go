@@ -26,6 +26,9 @@ type TeaRepository interface {
 
 	SetFavourite(id, userId uuid.UUID) error
 	RemoveFavourite(id, userId uuid.UUID) error
+
+	SetVisibility(id uuid.UUID, isHidden bool) error
+	SetStock(id uuid.UUID, stock *float64) error
 }
 
 type TeaTagRepository interface {
@@ -35,23 +38,46 @@ type TeaTagRepository interface {
 
 type TeaUnitRepository interface {
 	Exists(id uuid.UUID) (bool, error)
+	GetAll() ([]entity.Unit, error)
+}
+
+type TeaCategoryRepository interface {
+	GetAll() ([]entity.Category, error)
+}
+
+type TeaLocationRepository interface {
+	Exists(id uuid.UUID) (bool, error)
+	GetOverride(teaId, locationId uuid.UUID) (*entity.TeaLocationOverride, error)
+}
+
+type TeaNotifier interface {
+	NotifyTeaBack(tea *entity.Tea)
 }
 
 type TeaService struct {
-	teaRepository  TeaRepository
-	tagRepository  TeaTagRepository
-	unitRepository TeaUnitRepository
+	teaRepository      TeaRepository
+	tagRepository      TeaTagRepository
+	unitRepository     TeaUnitRepository
+	categoryRepository TeaCategoryRepository
+	locationRepository TeaLocationRepository
+	notifier           TeaNotifier
 }
 
 func NewTeaService(
 	teaRepository TeaRepository,
 	tagRepository TeaTagRepository,
 	unitRepository TeaUnitRepository,
+	categoryRepository TeaCategoryRepository,
+	locationRepository TeaLocationRepository,
+	notifier TeaNotifier,
 ) *TeaService {
 	return &TeaService{
-		teaRepository:  teaRepository,
-		tagRepository:  tagRepository,
-		unitRepository: unitRepository,
+		teaRepository:      teaRepository,
+		tagRepository:      tagRepository,
+		unitRepository:     unitRepository,
+		categoryRepository: categoryRepository,
+		locationRepository: locationRepository,
+		notifier:           notifier,
 	}
 }
 
@@ -81,7 +107,40 @@ func (s *TeaService) GetTeaById(id uuid.UUID, userId uuid.UUID) (*entity.TeaWith
 	return teaById, nil
 }
 
+// GetTeaByIdInLocation returns the tea with the values of the location.
+// The nil location id gives the catalogue values.
+func (s *TeaService) GetTeaByIdInLocation(id, userId, locationId uuid.UUID) (*entity.TeaWithRating, error) {
+	err := s.checkLocation(locationId)
+	if err != nil {
+		return nil, err
+	}
+
+	tea, err := s.GetTeaById(id, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if locationId == uuid.Nil {
+		return tea, nil
+	}
+
+	override, err := s.locationRepository.GetOverride(id, locationId)
+	if err != nil {
+		return nil, err
+	}
+
+	if override != nil {
+		override.ApplyTo(&tea.Tea)
+	}
+	return tea, nil
+}
+
 func (s *TeaService) GetAllTeas(filters *teaSchemas.Filters) ([]entity.TeaWithRating, uint64, error) {
+	err := s.checkLocation(filters.LocationId)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	allTeas, total, err := s.teaRepository.GetAll(filters)
 	if err != nil {
 		return nil, 0, err
@@ -109,6 +168,42 @@ func (s *TeaService) GetAllTeas(filters *teaSchemas.Filters) ([]entity.TeaWithRa
 	return allTeas, total, err
 }
 
+func (s *TeaService) CompareTeas(filters *teaSchemas.CompareFilters) ([]entity.TeaComparison, error) {
+	categories, err := s.categoryRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	categoriesById := make(map[uuid.UUID]*entity.Category, len(categories))
+	for i := range categories {
+		categoriesById[categories[i].Id] = &categories[i]
+	}
+
+	units, err := s.unitRepository.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	unitsById := make(map[uuid.UUID]*entity.Unit, len(units))
+	for i := range units {
+		unitsById[units[i].Id] = &units[i]
+	}
+
+	comparison := make([]entity.TeaComparison, 0, len(filters.Ids))
+	for _, id := range filters.Ids {
+		tea, err := s.GetTeaById(id, filters.UserId)
+		if err != nil {
+			return nil, err
+		}
+
+		comparison = append(comparison, entity.TeaComparison{
+			TeaWithRating: *tea,
+			Category:      categoriesById[tea.CategoryId],
+			Unit:          unitsById[tea.UnitId],
+		})
+	}
+
+	return comparison, nil
+}
+
 func (s *TeaService) CreateTea(t *teaSchemas.RequestModel) (*entity.Tea, error) {
 	exists, err := s.teaRepository.ExistsByName(uuid.Nil, t.Name)
 	if err != nil {
@@ -208,6 +303,22 @@ func (s *TeaService) UpdateTea(id uuid.UUID, t *teaSchemas.RequestModel) (*entit
 	return updatedTea, nil
 }
 
+func (s *TeaService) checkLocation(locationId uuid.UUID) error {
+	if locationId == uuid.Nil {
+		return nil
+	}
+
+	exists, err := s.locationRepository.Exists(locationId)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		err := fmt.Errorf("location with id %s is not found", locationId.String())
+		return errx.NewNotFoundError(err)
+	}
+	return nil
+}
+
 func (s *TeaService) getTagsDelta(existedTagIds, incomingTagIds []uuid.UUID) ([]uuid.UUID, []uuid.UUID) {
 	existedTagsMap := make(map[uuid.UUID]uuid.UUID, len(existedTagIds))
 	for _, tagId := range existedTagIds {
@@ -275,7 +386,62 @@ func (s *TeaService) DeleteEvaluation(userId, teaId uuid.UUID) error {
 	return nil
 }
 
+func (s *TeaService) SetVisibility(id uuid.UUID, isHidden bool) (*entity.TeaWithRating, error) {
+	tea, err := s.teaRepository.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+	if tea == nil {
+		err := fmt.Errorf("tea with id %s is not found", id.String())
+		return nil, errx.NewNotFoundError(err)
+	}
+
+	err = s.teaRepository.SetVisibility(id, isHidden)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getChangedAvailability(tea)
+}
+
+func (s *TeaService) SetStock(id uuid.UUID, stock *float64) (*entity.TeaWithRating, error) {
+	tea, err := s.teaRepository.GetById(id)
+	if err != nil {
+		return nil, err
+	}
+	if tea == nil {
+		err := fmt.Errorf("tea with id %s is not found", id.String())
+		return nil, errx.NewNotFoundError(err)
+	}
+
+	err = s.teaRepository.SetStock(id, stock)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getChangedAvailability(tea)
+}
+
+// getChangedAvailability returns the tea after its visibility or stock has
+// been changed and tells the fans of the tea if it is available again.
+func (s *TeaService) getChangedAvailability(before *entity.TeaWithRating) (*entity.TeaWithRating, error) {
+	after, err := s.GetTeaById(before.Id, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if !before.IsAvailable() && after.IsAvailable() {
+		s.notifier.NotifyTeaBack(&after.Tea)
+	}
+	return after, nil
+}
+
 func (s *TeaService) GetMinMaxServePrices(filters *teaSchemas.Filters) (float64, float64, error) {
+	err := s.checkLocation(filters.LocationId)
+	if err != nil {
+		return 0, 0, err
+	}
+
 	minPrice, maxPrice, err := s.teaRepository.GetMinMaxServePrices(filters)
 	if err != nil {
 		return 0, 0, err
@@ -1,284 +1,591 @@
 package service
 
 import (
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
 	"errors"
 	"fmt"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/levchenki/tea-api/internal/entity"
 	"github.com/levchenki/tea-api/internal/errx"
+	"github.com/levchenki/tea-api/internal/jwtx"
+	"github.com/levchenki/tea-api/internal/logx"
 	"github.com/levchenki/tea-api/internal/schemas/userSchemas"
 	"sort"
 	"strings"
 	"time"
+)
 
-	tgMiniApp "github.com/telegram-mini-apps/init-data-golang"
+// The typ claim tells the tokens signed with the same keys apart, also for
+// services verifying them with the published JWKS.
+const (
+	accessTokenType  = "access"
+	refreshTokenType = "refresh"
 )
 
 type UserRepository interface {
-	Exists(telegramId uint64) (bool, error)
-	Create(user *entity.User) error
-	GetByTelegramId(telegramId uint64) (*entity.User, error)
+	Create(user *entity.User, identity *entity.Identity) (uuid.UUID, error)
 	GetById(userId uuid.UUID) (*entity.User, error)
-	SaveRefreshToken(userId, refreshTokenId uuid.UUID) error
-	IsRefreshTokenExists(userId, refreshTokenId uuid.UUID) (bool, error)
+	SetTelegramId(userId uuid.UUID, telegramId uint64) error
+	UpdateTelegramProfile(user *entity.User, withLanguageAndPremium bool) error
+	UpdateProfile(user *entity.User) error
+	GetStatistics(userId uuid.UUID) (*entity.UserStatistics, error)
+	Delete(userId uuid.UUID) error
+
+	GetAll(filters *userSchemas.Filters) ([]entity.User, uint64, error)
+	GetLastEvaluations(userId uuid.UUID, limit uint64) ([]entity.UserEvaluation, error)
+	SetBlocked(userId uuid.UUID, isBlocked bool) error
+}
+
+type UserRoleRepository interface {
+	GetByName(name string) (*entity.Role, error)
+	GetByUserId(userId uuid.UUID) ([]entity.Role, error)
+	AssignToUser(userId, roleId uuid.UUID) error
+	UnassignFromUser(userId, roleId uuid.UUID) error
 }
 
+type UserSessionRepository interface {
+	Create(session *entity.Session, refreshToken *entity.RefreshToken) (*entity.Session, error)
+	GetActiveById(id uuid.UUID) (*entity.Session, error)
+	GetAllActiveByUserId(userId uuid.UUID) ([]entity.Session, error)
+	Revoke(id uuid.UUID, reason string) error
+	RevokeAllByUserId(userId uuid.UUID, reason string) error
+
+	GetRefreshToken(userId, id uuid.UUID) (*entity.RefreshToken, error)
+	GetLatestRefreshToken(sessionId uuid.UUID) (*entity.RefreshToken, error)
+	Rotate(session *entity.Session, refreshToken *entity.RefreshToken) (bool, error)
+}
+
+const lastEvaluationsLimit = 10
+
 type UserService struct {
-	userRepository UserRepository
+	userRepository     UserRepository
+	roleRepository     UserRoleRepository
+	sessionRepository  UserSessionRepository
+	identityRepository IdentityRepository
+	identityProviders  map[string]IdentityProvider
+	adminBootstrap     *AdminBootstrapService
+	refreshGracePeriod time.Duration
+	log                logx.AppLogger
 }
 
-func NewUserService(userRepository UserRepository) *UserService {
+func NewUserService(
+	userRepository UserRepository,
+	roleRepository UserRoleRepository,
+	sessionRepository UserSessionRepository,
+	identityRepository IdentityRepository,
+	identityProviders []IdentityProvider,
+	adminBootstrap *AdminBootstrapService,
+	refreshGracePeriod time.Duration,
+	log logx.AppLogger,
+) *UserService {
+	providers := make(map[string]IdentityProvider, len(identityProviders))
+	for _, provider := range identityProviders {
+		providers[provider.Name()] = provider
+	}
+
 	return &UserService{
-		userRepository: userRepository,
+		userRepository:     userRepository,
+		roleRepository:     roleRepository,
+		sessionRepository:  sessionRepository,
+		identityRepository: identityRepository,
+		identityProviders:  providers,
+		adminBootstrap:     adminBootstrap,
+		refreshGracePeriod: refreshGracePeriod,
+		log:                log,
 	}
 }
 
-func (s *UserService) AuthenticateUser(tgUser *userSchemas.TelegramUser, botToken, jwtSecret string) (*userSchemas.UserTokens, error) {
-	if err := s.verifyTelegramAuth(tgUser, botToken); err != nil {
-		errResponse := errx.NewForbiddenError(fmt.Errorf("telegram verification error: %w", err))
+func (s *UserService) createUserTokens(u *entity.User, device *userSchemas.DeviceInfo, jwtKeys *jwtx.KeySet) (*userSchemas.UserTokens, error) {
+	if u.IsBlocked {
+		errResponse := errx.NewForbiddenError(fmt.Errorf("user with id %s is blocked", u.Id))
 		return nil, errResponse
 	}
 
-	err := s.createIfNotExists(tgUser.Id, tgUser.FirstName, tgUser.LastName, tgUser.Username)
+	refreshToken, err := s.generateRefreshToken(u.Id, jwtKeys)
+	if err != nil {
+		errResponse := errx.NewInternalServerError(fmt.Errorf("refreshToken generation error: %w", err))
+		return nil, errResponse
+	}
+
+	session, err := s.sessionRepository.Create(&entity.Session{
+		UserId:    u.Id,
+		UserAgent: device.UserAgent,
+		IpAddress: device.IpAddress,
+		ExpiresAt: refreshToken.Claims.Exp.Time,
+	}, &entity.RefreshToken{
+		Id:        refreshToken.Claims.Jit,
+		ExpiresAt: refreshToken.Claims.Exp.Time,
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return s.createUserTokens(tgUser.Id, jwtSecret)
+	accessToken, err := s.generateAccessToken(u, session.Id, jwtKeys)
+	if err != nil {
+		errResponse := errx.NewInternalServerError(fmt.Errorf("accessToken generation error: %w", err))
+		return nil, errResponse
+	}
+
+	tokens := userSchemas.NewUserTokens(accessToken, refreshToken)
+	return tokens, nil
 }
 
-func (s *UserService) AuthenticateTelegramMiniApp(initData, botToken, jwtSecret string) (*userSchemas.UserTokens, error) {
+func (s *UserService) authenticate() {}
 
-	expIn := 1 * time.Hour
+func (s *UserService) CheckAuthToken(authHeader string, jwtKeys *jwtx.KeySet) (*userSchemas.AccessTokenClaims, error) {
+	if !strings.HasPrefix(authHeader, "Bearer") {
+		errResponse := errx.NewUnauthorizedError(fmt.Errorf("user is not authorized"))
+		return nil, errResponse
+	}
 
-	err := tgMiniApp.Validate(initData, botToken, expIn)
+	accessTokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	accessToken, err := jwtKeys.Parse(accessTokenString)
 
 	if err != nil {
-		if errors.Is(err, tgMiniApp.ErrExpired) {
-			return nil, errx.NewUnauthorizedError(fmt.Errorf("mini app data is expired"))
+		var errResponse *errx.AppError
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			errResponse = errx.NewUnauthorizedError(fmt.Errorf("access token is expired"))
+		} else {
+			errResponse = errx.NewUnauthorizedError(err)
 		}
+		return nil, errResponse
+	}
+
+	accessTokenClaims, err := s.validateAccessToken(accessToken)
+
+	if err != nil {
+		errResponse := errx.NewUnauthorizedError(err)
+		return nil, errResponse
+	}
+
+	user, err := s.userRepository.GetById(accessTokenClaims.Id)
+	if err != nil {
 		return nil, err
 	}
 
-	parsed, err := tgMiniApp.Parse(initData)
+	if user == nil {
+		errResponse := errx.NewUnauthorizedError(fmt.Errorf("user with id %s is not found", accessTokenClaims.Id))
+		return nil, errResponse
+	}
+
+	if user.IsBlocked {
+		errResponse := errx.NewForbiddenError(fmt.Errorf("user with id %s is blocked", user.Id))
+		return nil, errResponse
+	}
 
+	session, err := s.sessionRepository.GetActiveById(accessTokenClaims.SessionId)
 	if err != nil {
 		return nil, err
 	}
 
-	parsedUser := parsed.User
-	tgUserId := uint64(parsedUser.ID)
+	if session == nil || session.UserId != user.Id {
+		errResponse := errx.NewUnauthorizedError(fmt.Errorf("session is revoked or expired"))
+		return nil, errResponse
+	}
 
-	err = s.createIfNotExists(tgUserId, parsedUser.FirstName, parsedUser.LastName, parsedUser.Username)
+	roles, permissions, err := s.getRolesAndPermissions(user.Id)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.createUserTokens(tgUserId, jwtSecret)
+	accessTokenClaims.Role = s.getRole(user)
+	accessTokenClaims.Roles = roles
+	accessTokenClaims.Permissions = permissions
+	accessTokenClaims.LocationId = user.LocationId
+
+	return accessTokenClaims, nil
 }
 
-func (s *UserService) createIfNotExists(tgUserId uint64, firstName, lastName, username string) error {
-	exists, err := s.userRepository.Exists(tgUserId)
+func (s *UserService) UpdateAccessToken(signedRefreshToken string, device *userSchemas.DeviceInfo, jwtKeys *jwtx.KeySet) (*userSchemas.UserTokens, error) {
+
+	refreshToken, err := jwtKeys.Parse(signedRefreshToken)
+
 	if err != nil {
-		errResponse := errx.NewInternalServerError(fmt.Errorf("user exists check error: %w", err))
-		return errResponse
+		var errResponse *errx.AppError
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			errResponse = errx.NewUnauthorizedError(fmt.Errorf("refresh token is expired"))
+		} else {
+			errResponse = errx.NewUnauthorizedError(err)
+		}
+		return nil, errResponse
 	}
 
-	if !exists {
-		emptyUser := entity.NewEmptyUser(tgUserId, firstName, lastName, username)
-		err := s.userRepository.Create(emptyUser)
-		if err != nil {
-			errResponse := errx.NewInternalServerError(fmt.Errorf("user creation error: %w", err))
-			return errResponse
-		}
+	rtClaims, err := s.validateRefreshToken(refreshToken)
+	if err != nil {
+		errResponse := errx.NewUnauthorizedError(err)
+		return nil, errResponse
 	}
-	return nil
-}
 
-func (s *UserService) createUserTokens(tgUserId uint64, jwtSecret string) (*userSchemas.UserTokens, error) {
-	u, err := s.userRepository.GetByTelegramId(tgUserId)
+	storedRefreshToken, err := s.sessionRepository.GetRefreshToken(rtClaims.Id, rtClaims.Jit)
 
 	if err != nil {
-		errResponse := errx.NewInternalServerError(fmt.Errorf("user getting error: %w", err))
+		return nil, err
+	}
+
+	if storedRefreshToken == nil {
+		errResponse := errx.NewUnauthorizedError(fmt.Errorf("refresh token for user %s is not found", rtClaims.Id))
 		return nil, errResponse
 	}
 
-	accessToken, err := s.generateAccessToken(u, jwtSecret)
+	session, err := s.sessionRepository.GetActiveById(storedRefreshToken.SessionId)
 	if err != nil {
-		errResponse := errx.NewInternalServerError(fmt.Errorf("accessToken generation error: %w", err))
+		return nil, err
+	}
+
+	if session == nil {
+		errResponse := errx.NewUnauthorizedError(fmt.Errorf("session is revoked or expired"))
 		return nil, errResponse
 	}
 
-	refreshToken, err := s.createRefreshToken(u.Id, jwtSecret)
+	user, err := s.userRepository.GetById(rtClaims.Id)
 	if err != nil {
 		return nil, err
 	}
 
-	tokens := userSchemas.NewUserTokens(accessToken, refreshToken)
-	return tokens, nil
-}
+	if user == nil {
+		errResponse := errx.NewNotFoundError(fmt.Errorf("user with id %s is not found", rtClaims.Id))
+		return nil, errResponse
+	}
 
-func (s *UserService) authenticate() {}
+	if user.IsBlocked {
+		errResponse := errx.NewForbiddenError(fmt.Errorf("user with id %s is blocked", user.Id))
+		return nil, errResponse
+	}
+
+	if storedRefreshToken.RotatedAt != nil {
+		return s.handleRotatedRefreshToken(user, session, storedRefreshToken, device, jwtKeys)
+	}
 
-func (s *UserService) createRefreshToken(userId uuid.UUID, jwtSecret string) (*userSchemas.RefreshToken, error) {
-	refreshToken, err := s.generateRefreshToken(userId, jwtSecret)
+	newRefreshToken, err := s.generateRefreshToken(user.Id, jwtKeys)
 	if err != nil {
 		errResponse := errx.NewInternalServerError(fmt.Errorf("refreshToken generation error: %w", err))
 		return nil, errResponse
 	}
 
-	err = s.userRepository.SaveRefreshToken(userId, refreshToken.Claims.Jit)
+	session.ExpiresAt = newRefreshToken.Claims.Exp.Time
+	session.UserAgent = device.UserAgent
+	session.IpAddress = device.IpAddress
+	isRotated, err := s.sessionRepository.Rotate(session, &entity.RefreshToken{
+		Id:        newRefreshToken.Claims.Jit,
+		SessionId: session.Id,
+		ParentId:  &storedRefreshToken.Id,
+		ExpiresAt: newRefreshToken.Claims.Exp.Time,
+	})
 	if err != nil {
 		return nil, err
 	}
-	return refreshToken, err
-}
 
-func (s *UserService) CheckAuthToken(authHeader string, jwtSecret string) (*userSchemas.AccessTokenClaims, error) {
-	if !strings.HasPrefix(authHeader, "Bearer") {
-		errResponse := errx.NewUnauthorizedError(fmt.Errorf("user is not authorized"))
-		return nil, errResponse
+	if !isRotated {
+		now := time.Now()
+		storedRefreshToken.RotatedAt = &now
+		return s.handleRotatedRefreshToken(user, session, storedRefreshToken, device, jwtKeys)
 	}
 
-	accessTokenString := strings.TrimPrefix(authHeader, "Bearer ")
-	accessToken, err := s.parseJWT(accessTokenString, jwtSecret)
+	newAccessToken, err := s.generateAccessToken(user, session.Id, jwtKeys)
 
 	if err != nil {
-		var errResponse *errx.AppError
-		if errors.Is(err, jwt.ErrTokenExpired) {
-			errResponse = errx.NewUnauthorizedError(fmt.Errorf("access token is expired"))
-		} else {
-			errResponse = errx.NewUnauthorizedError(err)
+		return nil, err
+	}
+
+	tokens := userSchemas.NewUserTokens(newAccessToken, newRefreshToken)
+	return tokens, nil
+}
+
+// handleRotatedRefreshToken deals with a refresh token that already has a child.
+// Within the grace period it is most likely a concurrent refresh from the same
+// client, so the latest token of the family is returned again. Later on it can
+// only be a replayed token, and the whole family is revoked.
+func (s *UserService) handleRotatedRefreshToken(
+	user *entity.User,
+	session *entity.Session,
+	refreshToken *entity.RefreshToken,
+	device *userSchemas.DeviceInfo,
+	jwtKeys *jwtx.KeySet,
+) (*userSchemas.UserTokens, error) {
+	if time.Since(*refreshToken.RotatedAt) > s.refreshGracePeriod {
+		err := s.sessionRepository.Revoke(session.Id, entity.SessionRevokeReasonTokenReuse)
+		if err != nil {
+			return nil, err
 		}
+
+		s.log.Error("Security event: refresh token reuse detected, session revoked",
+			"userId", user.Id.String(),
+			"sessionId", session.Id.String(),
+			"refreshTokenId", refreshToken.Id.String(),
+			"ipAddress", device.IpAddress,
+			"userAgent", device.UserAgent,
+		)
+
+		errResponse := errx.NewUnauthorizedError(fmt.Errorf("refresh token has already been used"))
 		return nil, errResponse
 	}
 
-	accessTokenClaims, err := s.validateAccessToken(accessToken)
+	latestRefreshToken, err := s.sessionRepository.GetLatestRefreshToken(session.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if latestRefreshToken == nil {
+		errResponse := errx.NewUnauthorizedError(fmt.Errorf("session is revoked or expired"))
+		return nil, errResponse
+	}
 
+	rtClaims := &userSchemas.RefreshTokenClaims{
+		Id:  user.Id,
+		Exp: jwt.NewNumericDate(latestRefreshToken.ExpiresAt),
+		Jit: latestRefreshToken.Id,
+	}
+	newRefreshToken, err := s.signRefreshToken(rtClaims, jwtKeys)
 	if err != nil {
-		errResponse := errx.NewUnauthorizedError(err)
+		errResponse := errx.NewInternalServerError(fmt.Errorf("refreshToken generation error: %w", err))
 		return nil, errResponse
 	}
 
-	return accessTokenClaims, nil
-}
+	newAccessToken, err := s.generateAccessToken(user, session.Id, jwtKeys)
+	if err != nil {
+		return nil, err
+	}
 
-func (s *UserService) UpdateAccessToken(signedRefreshToken, jwtSecret string) (*userSchemas.UserTokens, error) {
+	tokens := userSchemas.NewUserTokens(newAccessToken, newRefreshToken)
+	return tokens, nil
+}
 
-	refreshToken, err := s.parseJWT(signedRefreshToken, jwtSecret)
+// Logout revokes the session of the refresh token. Missing, expired or
+// foreign tokens are ignored, so the client can always clear its state.
+func (s *UserService) Logout(signedRefreshToken string, jwtKeys *jwtx.KeySet) error {
+	if signedRefreshToken == "" {
+		return nil
+	}
 
+	refreshToken, err := jwtKeys.Parse(signedRefreshToken, jwt.WithoutClaimsValidation())
 	if err != nil {
-		var errResponse *errx.AppError
-		if errors.Is(err, jwt.ErrTokenExpired) {
-			errResponse = errx.NewUnauthorizedError(fmt.Errorf("refresh token is expired"))
-		} else {
-			errResponse = errx.NewUnauthorizedError(err)
-		}
-		return nil, errResponse
+		return nil
 	}
 
 	rtClaims, err := s.validateRefreshToken(refreshToken)
 	if err != nil {
-		errResponse := errx.NewUnauthorizedError(err)
-		return nil, errResponse
+		return nil
 	}
 
-	isExists, err := s.userRepository.IsRefreshTokenExists(rtClaims.Id, rtClaims.Jit)
+	storedRefreshToken, err := s.sessionRepository.GetRefreshToken(rtClaims.Id, rtClaims.Jit)
+	if err != nil {
+		return err
+	}
+
+	if storedRefreshToken == nil {
+		return nil
+	}
 
+	err = s.sessionRepository.Revoke(storedRefreshToken.SessionId, entity.SessionRevokeReasonLogout)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	return nil
+}
 
-	if !isExists {
-		errResponse := errx.NewUnauthorizedError(fmt.Errorf("refresh token for user %s is not found", rtClaims.Id))
-		return nil, errResponse
+func (s *UserService) GetProfile(userId uuid.UUID) (*entity.User, *entity.UserStatistics, error) {
+	user, err := s.getExistingUser(userId)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	user, err := s.userRepository.GetById(rtClaims.Id)
+	statistics, err := s.userRepository.GetStatistics(userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return user, statistics, nil
+}
+
+func (s *UserService) UpdateProfile(userId uuid.UUID, profile *userSchemas.ProfileRequestModel) (*entity.User, *entity.UserStatistics, error) {
+	user, err := s.getExistingUser(userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user.DisplayName = profile.DisplayName
+	user.Language = profile.Language
+	user.NotifyOrders = profile.Notifications.Orders
+	user.NotifyFavourites = profile.Notifications.Favourites
+
+	err = s.userRepository.UpdateProfile(user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return s.GetProfile(userId)
+}
+
+func (s *UserService) DeleteAccount(userId uuid.UUID) error {
+	_, err := s.getExistingUser(userId)
+	if err != nil {
+		return err
+	}
+
+	err = s.userRepository.Delete(userId)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *UserService) GetSessions(userId uuid.UUID) ([]entity.Session, error) {
+	sessions, err := s.sessionRepository.GetAllActiveByUserId(userId)
 	if err != nil {
 		return nil, err
 	}
+	return sessions, nil
+}
 
-	if user == nil {
-		errResponse := errx.NewNotFoundError(fmt.Errorf("user with id %s is not found", rtClaims.Id))
-		return nil, errResponse
+func (s *UserService) RevokeSession(userId, sessionId uuid.UUID) error {
+	session, err := s.sessionRepository.GetActiveById(sessionId)
+	if err != nil {
+		return err
 	}
 
-	newAccessToken, err := s.generateAccessToken(user, jwtSecret)
+	if session == nil || session.UserId != userId {
+		errResponse := errx.NewNotFoundError(fmt.Errorf("session with id %s is not found", sessionId))
+		return errResponse
+	}
 
+	err = s.sessionRepository.Revoke(sessionId, entity.SessionRevokeReasonUser)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// RevokeAllSessions signs the user out everywhere. Access tokens are bound to
+// their session, so the already issued ones stop working immediately.
+func (s *UserService) RevokeAllSessions(userId uuid.UUID) error {
+	err := s.sessionRepository.RevokeAllByUserId(userId, entity.SessionRevokeReasonRevokeAll)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *UserService) GetAllUsers(filters *userSchemas.Filters) ([]entity.User, uint64, error) {
+	users, total, err := s.userRepository.GetAll(filters)
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+func (s *UserService) GetUserActivity(userId uuid.UUID) (*entity.UserActivity, error) {
+	user, statistics, err := s.GetProfile(userId)
 	if err != nil {
 		return nil, err
 	}
 
-	newRefreshToken, err := s.createRefreshToken(user.Id, jwtSecret)
+	lastEvaluations, err := s.userRepository.GetLastEvaluations(userId, lastEvaluationsLimit)
 	if err != nil {
 		return nil, err
 	}
 
-	tokens := userSchemas.NewUserTokens(newAccessToken, newRefreshToken)
-	return tokens, nil
+	activity := &entity.UserActivity{
+		User:            user,
+		Statistics:      statistics,
+		LastEvaluations: lastEvaluations,
+	}
+	return activity, nil
 }
 
-func (s *UserService) verifyTelegramAuth(tgUser *userSchemas.TelegramUser, botToken string) error {
-	if botToken == "" {
-		return fmt.Errorf("invalid bot token")
+func (s *UserService) SetAdmin(actorId, userId uuid.UUID, isAdmin bool) (*entity.User, error) {
+	if actorId == userId {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("admin permissions can not be changed for yourself"))
+		return nil, errResponse
+	}
+
+	_, err := s.getExistingUser(userId)
+	if err != nil {
+		return nil, err
 	}
 
-	var dataStrings []string
-	dataStrings = append(dataStrings, fmt.Sprintf("auth_date=%d", tgUser.AuthDate))
-	dataStrings = append(dataStrings, fmt.Sprintf("first_name=%s", tgUser.FirstName))
-	dataStrings = append(dataStrings, fmt.Sprintf("id=%d", tgUser.Id))
-	if tgUser.LastName != "" {
-		dataStrings = append(dataStrings, fmt.Sprintf("last_name=%s", tgUser.LastName))
+	adminRole, err := s.roleRepository.GetByName(entity.RoleAdmin)
+	if err != nil {
+		return nil, err
 	}
-	if tgUser.Username != "" {
-		dataStrings = append(dataStrings, fmt.Sprintf("username=%s", tgUser.Username))
+
+	if adminRole == nil {
+		errResponse := errx.NewInternalServerError(fmt.Errorf("role %s is not found", entity.RoleAdmin))
+		return nil, errResponse
 	}
-	if tgUser.PhotoURL != "" {
-		dataStrings = append(dataStrings, fmt.Sprintf("photo_url=%s", tgUser.PhotoURL))
+
+	if isAdmin {
+		err = s.roleRepository.AssignToUser(userId, adminRole.Id)
+	} else {
+		err = s.roleRepository.UnassignFromUser(userId, adminRole.Id)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	sort.Strings(dataStrings)
-	checkString := strings.Join(dataStrings, "\n")
+	return s.getExistingUser(userId)
+}
 
-	h := sha256.New()
-	h.Write([]byte(botToken))
-	secretKey := h.Sum(nil)
+func (s *UserService) SetBlocked(actorId, userId uuid.UUID, isBlocked bool) (*entity.User, error) {
+	if actorId == userId {
+		errResponse := errx.NewBadRequestError(fmt.Errorf("you can not block yourself"))
+		return nil, errResponse
+	}
 
-	mac := hmac.New(sha256.New, secretKey)
-	mac.Write([]byte(checkString))
-	hash := hex.EncodeToString(mac.Sum(nil))
+	_, err := s.getExistingUser(userId)
+	if err != nil {
+		return nil, err
+	}
 
-	isValidHash := hash == tgUser.Hash
-	if !isValidHash {
-		return fmt.Errorf("hashes are not equal")
+	err = s.userRepository.SetBlocked(userId, isBlocked)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+
+	return s.getExistingUser(userId)
 }
 
-func (s *UserService) parseJWT(tokenString, jwtSecret string) (*jwt.Token, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+func (s *UserService) getRole(user *entity.User) string {
+	if user.IsAdmin {
+		return entity.RoleAdmin
+	}
+	return entity.RoleUser
+}
 
-			return nil, fmt.Errorf("invalid signing method")
+func (s *UserService) getRolesAndPermissions(userId uuid.UUID) ([]string, []string, error) {
+	userRoles, err := s.roleRepository.GetByUserId(userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	roles := make([]string, 0, len(userRoles))
+	permissions := make([]string, 0)
+	seenPermissions := make(map[string]struct{})
+	for _, role := range userRoles {
+		roles = append(roles, role.Name)
+		for _, permission := range role.Permissions {
+			if _, ok := seenPermissions[permission]; ok {
+				continue
+			}
+			seenPermissions[permission] = struct{}{}
+			permissions = append(permissions, permission)
 		}
+	}
+	sort.Strings(permissions)
 
-		return []byte(jwtSecret), nil
-	})
+	return roles, permissions, nil
+}
 
+func (s *UserService) getExistingUser(userId uuid.UUID) (*entity.User, error) {
+	user, err := s.userRepository.GetById(userId)
 	if err != nil {
 		return nil, err
 	}
 
-	if !token.Valid {
-		return nil, fmt.Errorf("token is invalid")
+	if user == nil {
+		errResponse := errx.NewNotFoundError(fmt.Errorf("user with id %s is not found", userId))
+		return nil, errResponse
 	}
-
-	return token, nil
+	return user, nil
 }
 
 func (s *UserService) validateAccessToken(token *jwt.Token) (*userSchemas.AccessTokenClaims, error) {
@@ -287,6 +594,10 @@ func (s *UserService) validateAccessToken(token *jwt.Token) (*userSchemas.Access
 		return nil, fmt.Errorf("invalid claims")
 	}
 
+	if claims["typ"] != accessTokenType {
+		return nil, fmt.Errorf("invalid claims: not an access token")
+	}
+
 	userClaims := &userSchemas.AccessTokenClaims{}
 
 	if exp, err := claims.GetExpirationTime(); err != nil || exp == nil {
@@ -305,6 +616,20 @@ func (s *UserService) validateAccessToken(token *jwt.Token) (*userSchemas.Access
 		userClaims.Id = idUUID
 	}
 
+	if sid, ok := claims["sid"]; !ok {
+		return nil, fmt.Errorf("invalid claims: sid can not be null")
+	} else {
+		sidString, ok := sid.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid claims: invalid sid")
+		}
+		sidUUID, err := uuid.Parse(sidString)
+		if err != nil {
+			return nil, fmt.Errorf("invalid claims: invalid sid")
+		}
+		userClaims.SessionId = sidUUID
+	}
+
 	if firstName, ok := claims["firstName"]; !ok {
 		return nil, fmt.Errorf("invalid claims: firstName can not be null")
 	} else {
@@ -338,9 +663,44 @@ func (s *UserService) validateAccessToken(token *jwt.Token) (*userSchemas.Access
 		}
 	}
 
+	roles, err := s.parseStringSliceClaim(claims, "roles")
+	if err != nil {
+		return nil, err
+	}
+	userClaims.Roles = roles
+
+	permissions, err := s.parseStringSliceClaim(claims, "permissions")
+	if err != nil {
+		return nil, err
+	}
+	userClaims.Permissions = permissions
+
 	return userClaims, nil
 }
 
+// parseStringSliceClaim treats a missing claim as empty, so tokens issued
+// before roles were introduced stay valid until they expire.
+func (s *UserService) parseStringSliceClaim(claims jwt.MapClaims, name string) ([]string, error) {
+	values := make([]string, 0)
+	claim, ok := claims[name]
+	if !ok || claim == nil {
+		return values, nil
+	}
+
+	items, ok := claim.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid claims: invalid %s", name)
+	}
+	for _, item := range items {
+		value, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid claims: invalid %s", name)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
 func (s *UserService) validateRefreshToken(token *jwt.Token) (*userSchemas.RefreshTokenClaims, error) {
 	claims, ok := token.Claims.(jwt.MapClaims)
 
@@ -348,6 +708,12 @@ func (s *UserService) validateRefreshToken(token *jwt.Token) (*userSchemas.Refre
 		return nil, fmt.Errorf("invalid claims")
 	}
 
+	// Refresh tokens issued before the typ claim was introduced stay valid
+	// until they expire, they can not pass for access tokens anyway.
+	if typ, ok := claims["typ"]; ok && typ != refreshTokenType {
+		return nil, fmt.Errorf("invalid claims: not a refresh token")
+	}
+
 	refreshClaims := &userSchemas.RefreshTokenClaims{}
 
 	if exp, err := claims.GetExpirationTime(); err != nil || exp == nil {
@@ -379,39 +745,45 @@ func (s *UserService) validateRefreshToken(token *jwt.Token) (*userSchemas.Refre
 	return refreshClaims, nil
 }
 
-func (s *UserService) generateAccessToken(user *entity.User, jwtSecret string) (*userSchemas.AccessToken, error) {
+func (s *UserService) generateAccessToken(user *entity.User, sessionId uuid.UUID, jwtKeys *jwtx.KeySet) (*userSchemas.AccessToken, error) {
 	expTime := time.Now().Add(time.Hour * 1)
-	var role string
-	if user.IsAdmin {
-		role = "admin"
-	} else {
-		role = "user"
-	}
-	accessClaims := jwt.MapClaims{
-		"id":        user.Id.String(),
-		"firstName": user.FirstName,
-		"username":  user.Username,
-		"role":      role,
-		"exp":       expTime.Unix(),
+	role := s.getRole(user)
+	roles, permissions, err := s.getRolesAndPermissions(user.Id)
+	if err != nil {
+		return nil, err
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, accessClaims)
-	signedToken, err := token.SignedString([]byte(jwtSecret))
 
-	accessToken := userSchemas.NewAccessToken(signedToken, user, role, expTime)
+	accessClaims := jwt.MapClaims{
+		"typ":         accessTokenType,
+		"id":          user.Id.String(),
+		"sid":         sessionId.String(),
+		"firstName":   user.FirstName,
+		"username":    user.Username,
+		"role":        role,
+		"roles":       roles,
+		"permissions": permissions,
+		"exp":         expTime.Unix(),
+	}
+	signedToken, err := jwtKeys.Sign(accessClaims)
+
+	accessToken := userSchemas.NewAccessToken(signedToken, user, sessionId, role, roles, permissions, expTime)
 	return accessToken, err
 }
 
-func (s *UserService) generateRefreshToken(id uuid.UUID, jwtSecret string) (*userSchemas.RefreshToken, error) {
+func (s *UserService) generateRefreshToken(id uuid.UUID, jwtKeys *jwtx.KeySet) (*userSchemas.RefreshToken, error) {
 	expTime := time.Now().Add(time.Hour * 24 * 7)
 	rtClaims := userSchemas.NewRefreshTokenClaims(id, expTime)
+	return s.signRefreshToken(rtClaims, jwtKeys)
+}
 
+func (s *UserService) signRefreshToken(rtClaims *userSchemas.RefreshTokenClaims, jwtKeys *jwtx.KeySet) (*userSchemas.RefreshToken, error) {
 	claims := jwt.MapClaims{
+		"typ": refreshTokenType,
 		"id":  rtClaims.Id.String(),
 		"exp": rtClaims.Exp.Unix(),
 		"jit": rtClaims.Jit,
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte(jwtSecret))
+	signedToken, err := jwtKeys.Sign(claims)
 
 	refreshToken := userSchemas.NewRefreshToken(signedToken, rtClaims)
 
@@ -0,0 +1,117 @@
+package tgx
+
+import (
+	"strings"
+)
+
+// Update is the part of a Bot API update the webhook handles.
+type Update struct {
+	UpdateId    int64        `json:"update_id"`
+	Message     *Message     `json:"message,omitempty"`
+	InlineQuery *InlineQuery `json:"inline_query,omitempty"`
+}
+
+type User struct {
+	Id           uint64 `json:"id"`
+	IsBot        bool   `json:"is_bot"`
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name,omitempty"`
+	Username     string `json:"username,omitempty"`
+	LanguageCode string `json:"language_code,omitempty"`
+}
+
+type Chat struct {
+	Id   int64  `json:"id"`
+	Type string `json:"type"`
+}
+
+type Message struct {
+	MessageId int64  `json:"message_id"`
+	From      *User  `json:"from,omitempty"`
+	Chat      Chat   `json:"chat"`
+	Text      string `json:"text,omitempty"`
+}
+
+type InlineQuery struct {
+	Id     string `json:"id"`
+	From   User   `json:"from"`
+	Query  string `json:"query"`
+	Offset string `json:"offset"`
+}
+
+type InlineKeyboardButton struct {
+	Text string `json:"text"`
+	Url  string `json:"url,omitempty"`
+}
+
+type InlineKeyboardMarkup struct {
+	InlineKeyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
+}
+
+type InputTextMessageContent struct {
+	MessageText string `json:"message_text"`
+	ParseMode   string `json:"parse_mode,omitempty"`
+}
+
+type InlineQueryResultArticle struct {
+	Type                string                  `json:"type"`
+	Id                  string                  `json:"id"`
+	Title               string                  `json:"title"`
+	Description         string                  `json:"description,omitempty"`
+	InputMessageContent InputTextMessageContent `json:"input_message_content"`
+	ReplyMarkup         *InlineKeyboardMarkup   `json:"reply_markup,omitempty"`
+}
+
+const ParseModeHTML = "HTML"
+
+// SendMessage and AnswerInlineQuery are written back as the response to the
+// webhook request, Telegram then calls the method on behalf of the bot.
+type SendMessage struct {
+	Method                string                `json:"method"`
+	ChatId                int64                 `json:"chat_id"`
+	Text                  string                `json:"text"`
+	ParseMode             string                `json:"parse_mode,omitempty"`
+	DisableWebPagePreview bool                  `json:"disable_web_page_preview,omitempty"`
+	ReplyMarkup           *InlineKeyboardMarkup `json:"reply_markup,omitempty"`
+}
+
+func NewSendMessage(chatId int64, text string) *SendMessage {
+	return &SendMessage{
+		Method:                "sendMessage",
+		ChatId:                chatId,
+		Text:                  text,
+		ParseMode:             ParseModeHTML,
+		DisableWebPagePreview: true,
+	}
+}
+
+type AnswerInlineQuery struct {
+	Method        string                     `json:"method"`
+	InlineQueryId string                     `json:"inline_query_id"`
+	Results       []InlineQueryResultArticle `json:"results"`
+	CacheTime     int                        `json:"cache_time"`
+	IsPersonal    bool                       `json:"is_personal,omitempty"`
+}
+
+func NewAnswerInlineQuery(inlineQueryId string, results []InlineQueryResultArticle, cacheTime int, isPersonal bool) *AnswerInlineQuery {
+	return &AnswerInlineQuery{
+		Method:        "answerInlineQuery",
+		InlineQueryId: inlineQueryId,
+		Results:       results,
+		CacheTime:     cacheTime,
+		IsPersonal:    isPersonal,
+	}
+}
+
+// ParseCommand splits "/search@our_bot green tea" into "search" and
+// "green tea". Text that is not a command gives an empty command.
+func ParseCommand(text string) (string, string) {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "/") {
+		return "", ""
+	}
+
+	command, args, _ := strings.Cut(text[1:], " ")
+	command, _, _ = strings.Cut(command, "@")
+	return strings.ToLower(command), strings.TrimSpace(args)
+}
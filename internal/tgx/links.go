@@ -0,0 +1,25 @@
+package tgx
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// MiniAppLink builds a t.me deep link that opens the mini app of the bot
+// with the given start parameter. An empty appName points to the main mini app of the bot.
+func MiniAppLink(botName, appName, startParam string) string {
+	botName = strings.TrimPrefix(botName, "@")
+	if botName == "" {
+		return ""
+	}
+
+	link := fmt.Sprintf("https://t.me/%s", botName)
+	if appName != "" {
+		link += "/" + appName
+	}
+	if startParam != "" {
+		link += "?startapp=" + url.QueryEscape(startParam)
+	}
+	return link
+}
@@ -0,0 +1,42 @@
+package tgx
+
+import (
+	"sync"
+	"time"
+)
+
+// ReplayCache remembers signatures of Telegram auth payloads that have
+// already been used. Entries live as long as the payload itself is accepted,
+// after that the auth_date check rejects the payload anyway.
+type ReplayCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]time.Time
+}
+
+func NewReplayCache(ttl time.Duration) *ReplayCache {
+	return &ReplayCache{
+		ttl:     ttl,
+		entries: make(map[string]time.Time),
+	}
+}
+
+// Remember stores the hash and reports whether it was already seen.
+func (c *ReplayCache) Remember(hash string) bool {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, expiresAt := range c.entries {
+		if now.After(expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+
+	if _, ok := c.entries[hash]; ok {
+		return true
+	}
+	c.entries[hash] = now.Add(c.ttl)
+	return false
+}
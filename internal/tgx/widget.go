@@ -0,0 +1,44 @@
+package tgx
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CheckWidgetHash verifies the data of the Telegram login widget. The check
+// string is built from every field except the hash itself, so fields added
+// by Telegram later on are covered as well.
+func CheckWidgetHash(data map[string]string, botToken string) error {
+	hash, ok := data["hash"]
+	if !ok || hash == "" {
+		return fmt.Errorf("hash is missing")
+	}
+
+	dataStrings := make([]string, 0, len(data))
+	for key, value := range data {
+		if key == "hash" {
+			continue
+		}
+		dataStrings = append(dataStrings, fmt.Sprintf("%s=%s", key, value))
+	}
+
+	sort.Strings(dataStrings)
+	checkString := strings.Join(dataStrings, "\n")
+
+	h := sha256.New()
+	h.Write([]byte(botToken))
+	secretKey := h.Sum(nil)
+
+	mac := hmac.New(sha256.New, secretKey)
+	mac.Write([]byte(checkString))
+	expectedHash := hex.EncodeToString(mac.Sum(nil))
+
+	if !hmac.Equal([]byte(expectedHash), []byte(hash)) {
+		return fmt.Errorf("hashes are not equal")
+	}
+	return nil
+}
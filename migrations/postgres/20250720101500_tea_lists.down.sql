@@ -0,0 +1,17 @@
+create table users_favourite_teas
+(
+    id         uuid                                default gen_random_uuid() primary key,
+    user_id    uuid references users (id) not null,
+    tea_id     uuid references teas (id)  not null,
+    created_at timestamp                  not null default current_timestamp,
+    constraint favourite_user_tea_unique unique (user_id, tea_id)
+);
+
+insert into users_favourite_teas (user_id, tea_id, created_at)
+select tl.user_id, tli.tea_id, tli.created_at
+from tea_list_items tli
+         join tea_lists tl on tl.id = tli.list_id
+where tl.is_favourite;
+
+drop table if exists tea_list_items;
+drop table if exists tea_lists;
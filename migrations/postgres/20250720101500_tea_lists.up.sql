@@ -0,0 +1,37 @@
+create table if not exists tea_lists
+(
+    id           uuid                             default gen_random_uuid() primary key,
+    user_id      uuid references users (id)  not null,
+    name         varchar(255)                not null,
+    is_favourite boolean                     not null default false,
+    created_at   timestamp                   not null default current_timestamp,
+    updated_at   timestamp                   not null default current_timestamp,
+    constraint tea_list_user_name_unique unique (user_id, name)
+);
+
+create unique index if not exists idx_tea_lists_user_favourite on tea_lists (user_id) where is_favourite;
+
+create table if not exists tea_list_items
+(
+    id         uuid                                    default gen_random_uuid() primary key,
+    list_id    uuid references tea_lists (id)     not null,
+    tea_id     uuid references teas (id)          not null,
+    position   int                                not null default 0,
+    note       varchar                            null,
+    created_at timestamp                          not null default current_timestamp,
+    constraint tea_list_item_unique unique (list_id, tea_id)
+);
+
+insert into tea_lists (user_id, name, is_favourite)
+select distinct user_id, 'Favourites', true
+from users_favourite_teas;
+
+insert into tea_list_items (list_id, tea_id, position, created_at)
+select tl.id,
+       uft.tea_id,
+       row_number() over (partition by uft.user_id order by uft.created_at),
+       uft.created_at
+from users_favourite_teas uft
+         join tea_lists tl on tl.user_id = uft.user_id and tl.is_favourite;
+
+drop table users_favourite_teas;
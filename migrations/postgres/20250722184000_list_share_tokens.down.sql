@@ -0,0 +1,3 @@
+alter table tea_lists
+    drop column share_token,
+    drop column shared_at;
@@ -0,0 +1,3 @@
+alter table tea_lists
+    add column share_token varchar(64) null unique,
+    add column shared_at   timestamp   null;
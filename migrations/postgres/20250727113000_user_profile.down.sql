@@ -0,0 +1,5 @@
+alter table users
+    drop column display_name,
+    drop column language,
+    drop column notify_orders,
+    drop column notify_favourites;
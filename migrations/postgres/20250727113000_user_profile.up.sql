@@ -0,0 +1,5 @@
+alter table users
+    add column display_name      varchar(255) null,
+    add column language          varchar(16)  null,
+    add column notify_orders     boolean      not null default true,
+    add column notify_favourites boolean      not null default true;
@@ -0,0 +1,5 @@
+drop index if exists idx_users_username_lower;
+
+alter table users
+    drop column is_blocked,
+    drop column blocked_at;
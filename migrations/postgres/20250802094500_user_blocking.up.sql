@@ -0,0 +1,5 @@
+alter table users
+    add column is_blocked boolean   not null default false,
+    add column blocked_at timestamp null;
+
+create index if not exists idx_users_username_lower on users (lower(username));
@@ -0,0 +1,18 @@
+alter table teas
+    drop column stock;
+
+alter table users
+    add column is_admin bool default false;
+
+update users u
+set is_admin = true
+where exists(select 1
+             from users_roles ur
+                      join roles r on r.id = ur.role_id
+             where ur.user_id = u.id
+               and r.name = 'admin');
+
+drop table if exists users_roles;
+drop table if exists roles_permissions;
+drop table if exists roles;
+drop table if exists permissions;
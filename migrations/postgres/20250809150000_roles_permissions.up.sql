@@ -0,0 +1,77 @@
+create table if not exists permissions
+(
+    code        varchar(64) primary key,
+    description varchar null
+);
+
+create table if not exists roles
+(
+    id          uuid default gen_random_uuid() primary key,
+    name        varchar(64) not null unique,
+    description varchar     null,
+    is_system   boolean     not null default false
+);
+
+create table if not exists roles_permissions
+(
+    role_id         uuid references roles (id) on delete cascade          not null,
+    permission_code varchar(64) references permissions (code) on delete cascade not null,
+    primary key (role_id, permission_code)
+);
+
+create table if not exists users_roles
+(
+    user_id    uuid references users (id) on delete cascade not null,
+    role_id    uuid references roles (id) on delete cascade not null,
+    created_at timestamp                                    not null default current_timestamp,
+    primary key (user_id, role_id)
+);
+
+insert into permissions (code, description)
+values ('teas:write', 'Create, edit and delete teas'),
+       ('teas:visibility', 'Hide and unhide teas'),
+       ('stock:write', 'Adjust stock of teas'),
+       ('tags:write', 'Create, edit and delete tags'),
+       ('categories:write', 'Create, edit and delete categories'),
+       ('units:write', 'Create, edit and delete units'),
+       ('reviews:moderate', 'Delete evaluations of other users'),
+       ('users:manage', 'View, block and unblock users'),
+       ('roles:manage', 'Manage roles and assign them to users');
+
+insert into roles (name, description, is_system)
+values ('admin', 'Full access', true),
+       ('barista', 'Hides and unhides teas, adjusts stock', false),
+       ('menu_editor', 'Edits teas, tags and categories', false),
+       ('moderator', 'Moderates reviews', false);
+
+insert into roles_permissions (role_id, permission_code)
+select r.id, p.code
+from roles r
+         cross join permissions p
+where r.name = 'admin';
+
+insert into roles_permissions (role_id, permission_code)
+select r.id, p.code
+from roles r
+         join (values ('barista', 'teas:visibility'),
+                      ('barista', 'stock:write'),
+                      ('menu_editor', 'teas:write'),
+                      ('menu_editor', 'teas:visibility'),
+                      ('menu_editor', 'tags:write'),
+                      ('menu_editor', 'categories:write'),
+                      ('menu_editor', 'units:write'),
+                      ('moderator', 'reviews:moderate')) as rp (role_name, permission_code)
+              on rp.role_name = r.name
+         join permissions p on p.code = rp.permission_code;
+
+insert into users_roles (user_id, role_id)
+select u.id, r.id
+from users u
+         join roles r on r.name = 'admin'
+where u.is_admin;
+
+alter table users
+    drop column is_admin;
+
+alter table teas
+    add column stock numeric(10, 2) null check ( stock >= 0 );
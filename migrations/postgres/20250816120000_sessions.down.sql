@@ -0,0 +1,13 @@
+alter table users
+    add column refresh_token_id uuid null;
+
+update users u
+set refresh_token_id = s.refresh_token_id
+from (select distinct on (user_id) user_id, refresh_token_id
+      from sessions
+      where revoked_at is null
+        and expires_at > now()
+      order by user_id, last_used_at desc) s
+where s.user_id = u.id;
+
+drop table if exists sessions;
@@ -0,0 +1,24 @@
+create table if not exists sessions
+(
+    id               uuid default gen_random_uuid() primary key,
+    user_id          uuid references users (id) on delete cascade not null,
+    refresh_token_id uuid                                         not null unique,
+    user_agent       varchar                                      null,
+    ip_address       varchar(64)                                  null,
+    created_at       timestamp                                    not null default current_timestamp,
+    last_used_at     timestamp                                    not null default current_timestamp,
+    expires_at       timestamp                                    not null,
+    revoked_at       timestamp                                    null
+);
+
+create index if not exists idx_sessions_user_id on sessions (user_id);
+
+-- Every live refresh token becomes a session, so the users stay signed in.
+-- Refresh tokens live for 7 days, the token itself still expires on its own.
+insert into sessions (user_id, refresh_token_id, expires_at)
+select id, refresh_token_id, now() + interval '7 days'
+from users
+where refresh_token_id is not null;
+
+alter table users
+    drop column refresh_token_id;
@@ -0,0 +1,19 @@
+alter table sessions
+    add column refresh_token_id uuid null,
+    drop column revoked_reason;
+
+update sessions s
+set refresh_token_id = rt.id
+from refresh_tokens rt
+where rt.session_id = s.id
+  and rt.rotated_at is null;
+
+delete
+from sessions
+where refresh_token_id is null;
+
+alter table sessions
+    alter column refresh_token_id set not null,
+    add constraint sessions_refresh_token_id_key unique (refresh_token_id);
+
+drop table if exists refresh_tokens;
@@ -0,0 +1,20 @@
+create table if not exists refresh_tokens
+(
+    id         uuid primary key,
+    session_id uuid references sessions (id) on delete cascade       not null,
+    parent_id  uuid references refresh_tokens (id) on delete cascade null,
+    created_at timestamp                                             not null default current_timestamp,
+    expires_at timestamp                                             not null,
+    rotated_at timestamp                                             null
+);
+
+create index if not exists idx_refresh_tokens_session_id on refresh_tokens (session_id);
+create unique index if not exists idx_refresh_tokens_session_active on refresh_tokens (session_id) where rotated_at is null;
+
+insert into refresh_tokens (id, session_id, created_at, expires_at)
+select refresh_token_id, id, last_used_at, expires_at
+from sessions;
+
+alter table sessions
+    drop column refresh_token_id,
+    add column revoked_reason varchar(32) null;
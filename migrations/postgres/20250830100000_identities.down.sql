@@ -0,0 +1,18 @@
+-- Users signed in by email or OIDC can not be kept without a Telegram id,
+-- they have to be dealt with by hand before rolling back.
+do
+$$
+    begin
+        if exists(select 1 from users where telegram_id is null) then
+            raise exception 'users without telegram_id exist, migrate or delete them before rolling back';
+        end if;
+    end
+$$;
+
+drop table if exists oidc_login_states;
+drop table if exists email_login_tokens;
+
+alter table users
+    alter column telegram_id set not null;
+
+drop table if exists identities;
@@ -0,0 +1,39 @@
+create table if not exists identities
+(
+    id           uuid                                         default gen_random_uuid() primary key,
+    user_id      uuid references users (id) on delete cascade not null,
+    provider     varchar(32)                                  not null,
+    subject      varchar(255)                                 not null,
+    email        varchar(255)                                 null,
+    created_at   timestamp                                    not null default current_timestamp,
+    last_used_at timestamp                                    not null default current_timestamp,
+    unique (provider, subject)
+);
+
+create index if not exists idx_identities_user_id on identities (user_id);
+
+insert into identities (user_id, provider, subject, created_at, last_used_at)
+select id, 'telegram', telegram_id::text, created_at, updated_at
+from users
+where telegram_id is not null;
+
+alter table users
+    alter column telegram_id drop not null;
+
+create table if not exists email_login_tokens
+(
+    token_hash varchar(64) primary key,
+    email      varchar(255) not null,
+    created_at timestamp    not null default current_timestamp,
+    expires_at timestamp    not null,
+    used_at    timestamp    null
+);
+
+create table if not exists oidc_login_states
+(
+    state         varchar(64)  primary key,
+    nonce         varchar(64)  not null,
+    code_verifier varchar(128) not null,
+    created_at    timestamp    not null default current_timestamp,
+    expires_at    timestamp    not null
+);
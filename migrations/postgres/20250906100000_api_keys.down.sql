@@ -0,0 +1,6 @@
+delete
+from permissions
+where code = 'api_keys:manage';
+
+drop table if exists api_keys_scopes;
+drop table if exists api_keys;
@@ -0,0 +1,27 @@
+create table if not exists api_keys
+(
+    id           uuid                                          default gen_random_uuid() primary key,
+    name         varchar(255)                                  not null,
+    prefix       varchar(16)                                   not null,
+    key_hash     varchar(64)                                   not null unique,
+    created_by   uuid references users (id) on delete set null null,
+    created_at   timestamp                                     not null default current_timestamp,
+    expires_at   timestamp                                     null,
+    last_used_at timestamp                                     null,
+    revoked_at   timestamp                                     null
+);
+
+create table if not exists api_keys_scopes
+(
+    api_key_id uuid references api_keys (id) on delete cascade not null,
+    scope      varchar(32)                                     not null,
+    primary key (api_key_id, scope)
+);
+
+insert into permissions (code, description)
+values ('api_keys:manage', 'Create and revoke API keys for integrations');
+
+insert into roles_permissions (role_id, permission_code)
+select r.id, 'api_keys:manage'
+from roles r
+where r.name = 'admin';
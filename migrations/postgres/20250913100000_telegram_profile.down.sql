@@ -0,0 +1,4 @@
+alter table users
+    drop column photo_url,
+    drop column language_code,
+    drop column is_premium;
@@ -0,0 +1,4 @@
+alter table users
+    add column photo_url     varchar     null,
+    add column language_code varchar(16) null,
+    add column is_premium    boolean     not null default false;
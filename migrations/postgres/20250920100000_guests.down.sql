@@ -0,0 +1,2 @@
+drop table if exists guest_tea_marks;
+drop table if exists guests;
@@ -0,0 +1,15 @@
+create table if not exists guests
+(
+    id           uuid primary key   default gen_random_uuid(),
+    created_at   timestamp not null default now(),
+    last_used_at timestamp not null default now()
+);
+
+create table if not exists guest_tea_marks
+(
+    guest_id   uuid        not null references guests (id) on delete cascade,
+    tea_id     uuid        not null references teas (id) on delete cascade,
+    mark       varchar(16) not null,
+    created_at timestamp   not null default now(),
+    primary key (guest_id, tea_id, mark)
+);
@@ -0,0 +1,3 @@
+drop table if exists order_items;
+drop table if exists orders;
+drop table if exists cart_items;
@@ -0,0 +1,40 @@
+create table if not exists cart_items
+(
+    id         uuid primary key        default gen_random_uuid(),
+    user_id    uuid           not null references users (id) on delete cascade,
+    tea_id     uuid           not null references teas (id) on delete cascade,
+    kind       varchar(16)    not null,
+    quantity   numeric(10, 2) not null check ( quantity > 0 ),
+    price      numeric(10, 2) not null,
+    created_at timestamp      not null default now(),
+    updated_at timestamp      not null default now(),
+    constraint cart_items_user_tea_kind_unique unique (user_id, tea_id, kind)
+);
+
+create table if not exists orders
+(
+    id         uuid primary key        default gen_random_uuid(),
+    number     serial         not null,
+    user_id    uuid           null references users (id) on delete set null,
+    status     varchar(16)    not null default 'placed',
+    total      numeric(10, 2) not null,
+    comment    varchar(500)   null,
+    created_at timestamp      not null default now(),
+    updated_at timestamp      not null default now()
+);
+
+create index if not exists idx_orders_user_id on orders (user_id, created_at desc);
+
+create table if not exists order_items
+(
+    id       uuid primary key        default gen_random_uuid(),
+    order_id uuid           not null references orders (id) on delete cascade,
+    tea_id   uuid           null references teas (id) on delete set null,
+    tea_name varchar(255)   not null,
+    kind     varchar(16)    not null,
+    quantity numeric(10, 2) not null,
+    price    numeric(10, 2) not null,
+    amount   numeric(10, 2) not null
+);
+
+create index if not exists idx_order_items_order_id on order_items (order_id);
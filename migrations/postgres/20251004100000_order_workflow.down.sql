@@ -0,0 +1,12 @@
+delete
+from permissions
+where code = 'orders:manage';
+
+drop index if exists idx_orders_active;
+
+alter table orders
+    alter column status set default 'placed';
+
+update orders
+set status = 'placed'
+where status = 'new';
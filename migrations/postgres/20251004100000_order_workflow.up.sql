@@ -0,0 +1,17 @@
+update orders
+set status = 'new'
+where status = 'placed';
+
+alter table orders
+    alter column status set default 'new';
+
+create index if not exists idx_orders_active on orders (created_at)
+    where status in ('new', 'accepted', 'brewing', 'ready');
+
+insert into permissions (code, description)
+values ('orders:manage', 'Work with the order queue at the bar');
+
+insert into roles_permissions (role_id, permission_code)
+select r.id, 'orders:manage'
+from roles r
+where r.name in ('admin', 'barista');
@@ -0,0 +1,15 @@
+create table if not exists notifications
+(
+    id              uuid primary key     default gen_random_uuid(),
+    user_id         uuid        not null references users (id) on delete cascade,
+    recipient       varchar(64) not null,
+    text            text        not null,
+    status          varchar(16) not null default 'pending',
+    attempts        int         not null default 0,
+    last_error      text        null,
+    next_attempt_at timestamp   not null default now(),
+    created_at      timestamp   not null default now()
+);
+
+create index if not exists idx_notifications_pending on notifications (next_attempt_at)
+    where status = 'pending';
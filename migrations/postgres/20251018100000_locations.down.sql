@@ -0,0 +1,9 @@
+delete
+from permissions
+where code = 'locations:manage';
+
+alter table users
+    drop column if exists location_id;
+
+drop table if exists tea_location_overrides;
+drop table if exists locations;
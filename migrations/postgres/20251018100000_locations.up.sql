@@ -0,0 +1,42 @@
+create table if not exists locations
+(
+    id         uuid primary key      default gen_random_uuid(),
+    name       varchar(64)  not null unique,
+    address    varchar(256) null,
+    is_default boolean      not null default false,
+    created_at timestamp    not null default now(),
+    updated_at timestamp    not null default now()
+);
+
+create unique index if not exists idx_locations_default on locations (is_default)
+    where is_default;
+
+-- The catalogue values of the teas become the values of the default
+-- location, other locations override them where they differ.
+insert into locations (name, is_default)
+values ('Main', true);
+
+create table if not exists tea_location_overrides
+(
+    tea_id      uuid           not null references teas (id) on delete cascade,
+    location_id uuid           not null references locations (id) on delete cascade,
+    is_hidden   boolean        null,
+    serve_price numeric(10, 2) null check ( serve_price >= 0 ),
+    unit_price  numeric(10, 2) null check ( unit_price >= 0 ),
+    stock       numeric(10, 2) null check ( stock >= 0 ),
+    updated_at  timestamp      not null default now(),
+    primary key (tea_id, location_id)
+);
+
+create index if not exists idx_tea_location_overrides_location_id on tea_location_overrides (location_id);
+
+alter table users
+    add column if not exists location_id uuid null references locations (id) on delete set null;
+
+insert into permissions (code, description)
+values ('locations:manage', 'Manage locations');
+
+insert into roles_permissions (role_id, permission_code)
+select r.id, 'locations:manage'
+from roles r
+where r.name = 'admin';
@@ -0,0 +1,7 @@
+delete
+from permissions
+where code = 'events:manage';
+
+drop table if exists event_registrations;
+drop table if exists event_teas;
+drop table if exists events;
@@ -0,0 +1,46 @@
+-- Event times are entered with the offset of the venue, so unlike the other
+-- tables they are stored with the time zone.
+create table if not exists events
+(
+    id          uuid primary key        default gen_random_uuid(),
+    title       varchar(128)   not null,
+    description text           null,
+    starts_at   timestamptz    not null,
+    ends_at     timestamptz    not null,
+    capacity    integer        not null check ( capacity > 0 ),
+    price       numeric(10, 2) not null default 0 check ( price >= 0 ),
+    location_id uuid           null references locations (id) on delete set null,
+    created_at  timestamp      not null default now(),
+    updated_at  timestamp      not null default now(),
+    constraint events_ends_after_starts check ( ends_at > starts_at )
+);
+
+create index if not exists idx_events_ends_at on events (ends_at);
+
+create table if not exists event_teas
+(
+    event_id uuid    not null references events (id) on delete cascade,
+    tea_id   uuid    not null references teas (id) on delete cascade,
+    position integer not null,
+    primary key (event_id, tea_id)
+);
+
+create table if not exists event_registrations
+(
+    event_id   uuid        not null references events (id) on delete cascade,
+    user_id    uuid        not null references users (id) on delete cascade,
+    status     varchar(16) not null,
+    created_at timestamp   not null default now(),
+    updated_at timestamp   not null default now(),
+    primary key (event_id, user_id)
+);
+
+create index if not exists idx_event_registrations_user_id on event_registrations (user_id);
+
+insert into permissions (code, description)
+values ('events:manage', 'Manage tasting events and see attendees');
+
+insert into roles_permissions (role_id, permission_code)
+select r.id, 'events:manage'
+from roles r
+where r.name = 'admin';
@@ -0,0 +1,13 @@
+delete
+from permissions
+where code = 'loyalty:manage';
+
+drop table if exists loyalty_transactions;
+
+alter table orders
+    drop column if exists redeemed_points,
+    drop column if exists discount;
+
+alter table categories
+    drop column if exists loyalty_amount_percent,
+    drop column if exists loyalty_serve_points;
@@ -0,0 +1,36 @@
+-- Points a served order earns for the teas of the category: a stamp per
+-- serve and a percent of the amount. One stamp per serve mirrors the paper
+-- card.
+alter table categories
+    add column if not exists loyalty_serve_points   integer      not null default 1 check ( loyalty_serve_points >= 0 ),
+    add column if not exists loyalty_amount_percent numeric(5, 2) not null default 0 check ( loyalty_amount_percent between 0 and 100 );
+
+alter table orders
+    add column if not exists discount        numeric(10, 2) not null default 0,
+    add column if not exists redeemed_points integer        not null default 0;
+
+create table if not exists loyalty_transactions
+(
+    id         uuid primary key      default gen_random_uuid(),
+    user_id    uuid         not null references users (id) on delete cascade,
+    order_id   uuid         null references orders (id) on delete set null,
+    kind       varchar(16)  not null,
+    points     integer      not null check ( points <> 0 ),
+    reason     varchar(256) null,
+    created_by uuid         null references users (id) on delete set null,
+    created_at timestamp    not null default now()
+);
+
+create index if not exists idx_loyalty_transactions_user_id on loyalty_transactions (user_id, created_at desc);
+
+-- An order earns, redeems and refunds points at most once.
+create unique index if not exists idx_loyalty_transactions_order_kind on loyalty_transactions (order_id, kind)
+    where order_id is not null;
+
+insert into permissions (code, description)
+values ('loyalty:manage', 'Adjust loyalty points of users and set earning rules');
+
+insert into roles_permissions (role_id, permission_code)
+select r.id, 'loyalty:manage'
+from roles r
+where r.name in ('admin', 'barista');
@@ -0,0 +1,7 @@
+delete
+from permissions
+where code = 'discounts:manage';
+
+drop table if exists cart_promo_codes;
+drop table if exists discount_redemptions;
+drop table if exists discounts;
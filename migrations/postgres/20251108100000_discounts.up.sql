@@ -0,0 +1,53 @@
+-- A discount with a code is a promo code the customer enters, one without a
+-- code applies to every cart that matches it.
+create table if not exists discounts
+(
+    id               uuid primary key        default gen_random_uuid(),
+    name             varchar(128)   not null,
+    code             varchar(32)    null,
+    kind             varchar(16)    not null,
+    value            numeric(10, 2) not null check ( value > 0 ),
+    scope            varchar(16)    not null default 'order',
+    target_id        uuid           null,
+    min_order_amount numeric(10, 2) not null default 0 check ( min_order_amount >= 0 ),
+    starts_at        timestamptz    null,
+    ends_at          timestamptz    null,
+    usage_limit      integer        null check ( usage_limit > 0 ),
+    per_user_limit   integer        null check ( per_user_limit > 0 ),
+    is_active        boolean        not null default true,
+    created_at       timestamp      not null default now(),
+    updated_at       timestamp      not null default now(),
+    constraint discounts_percent_value check ( kind <> 'percent' or value <= 100 ),
+    constraint discounts_ends_after_starts check ( starts_at is null or ends_at is null or ends_at > starts_at )
+);
+
+create unique index if not exists idx_discounts_code on discounts (lower(code))
+    where code is not null;
+
+create table if not exists discount_redemptions
+(
+    id          uuid primary key        default gen_random_uuid(),
+    discount_id uuid           not null references discounts (id) on delete cascade,
+    order_id    uuid           not null references orders (id) on delete cascade,
+    user_id     uuid           null references users (id) on delete set null,
+    amount      numeric(10, 2) not null,
+    created_at  timestamp      not null default now(),
+    constraint discount_redemptions_discount_order_unique unique (discount_id, order_id)
+);
+
+create index if not exists idx_discount_redemptions_user_id on discount_redemptions (discount_id, user_id);
+
+create table if not exists cart_promo_codes
+(
+    user_id     uuid primary key references users (id) on delete cascade,
+    discount_id uuid      not null references discounts (id) on delete cascade,
+    created_at  timestamp not null default now()
+);
+
+insert into permissions (code, description)
+values ('discounts:manage', 'Manage promo codes and discount rules');
+
+insert into roles_permissions (role_id, permission_code)
+select r.id, 'discounts:manage'
+from roles r
+where r.name = 'admin';
@@ -0,0 +1,7 @@
+drop index if exists idx_orders_location_active;
+
+alter table orders
+    drop column if exists location_id;
+
+alter table cart_items
+    drop column if exists location_id;
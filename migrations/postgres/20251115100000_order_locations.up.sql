@@ -0,0 +1,20 @@
+-- Carts and orders belong to a location, the prices, the visibility and the
+-- stock of the teas in them are the ones of the location. Existing carts and
+-- orders go to the default location.
+alter table cart_items
+    add column if not exists location_id uuid null references locations (id) on delete cascade;
+
+update cart_items
+set location_id = (select id from locations where is_default);
+
+alter table cart_items
+    alter column location_id set not null;
+
+alter table orders
+    add column if not exists location_id uuid null references locations (id) on delete set null;
+
+update orders
+set location_id = (select id from locations where is_default);
+
+create index if not exists idx_orders_location_active on orders (location_id, created_at)
+    where status in ('new', 'accepted', 'brewing', 'ready');
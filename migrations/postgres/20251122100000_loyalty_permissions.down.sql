@@ -0,0 +1,11 @@
+insert into permissions (code, description)
+values ('loyalty:manage', 'Adjust loyalty points of users and set earning rules');
+
+insert into roles_permissions (role_id, permission_code)
+select distinct rp.role_id, 'loyalty:manage'
+from roles_permissions rp
+where rp.permission_code in ('loyalty:adjust', 'loyalty:rules');
+
+delete
+from permissions
+where code in ('loyalty:adjust', 'loyalty:rules');
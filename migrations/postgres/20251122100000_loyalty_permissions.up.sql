@@ -0,0 +1,20 @@
+-- loyalty:manage is split up: baristas adjust balances at the counter, the
+-- earning rules are set by admins only. Roles that could manage loyalty keep
+-- adjusting balances.
+insert into permissions (code, description)
+values ('loyalty:adjust', 'View and adjust loyalty points of users'),
+       ('loyalty:rules', 'Set loyalty earning rules of categories');
+
+insert into roles_permissions (role_id, permission_code)
+select rp.role_id, 'loyalty:adjust'
+from roles_permissions rp
+where rp.permission_code = 'loyalty:manage';
+
+insert into roles_permissions (role_id, permission_code)
+select r.id, 'loyalty:rules'
+from roles r
+where r.name = 'admin';
+
+delete
+from permissions
+where code = 'loyalty:manage';
@@ -0,0 +1,30 @@
+-- Deleted discounts that have been redeemed can not be dropped without their
+-- redemptions, they have to be dealt with by hand before rolling back.
+do
+$$
+    begin
+        if exists(select 1
+                  from discounts d
+                  where d.deleted_at is not null
+                    and exists(select 1 from discount_redemptions dr where dr.discount_id = d.id)) then
+            raise exception 'deleted discounts with redemptions exist, delete them before rolling back';
+        end if;
+    end
+$$;
+
+delete
+from discounts
+where deleted_at is not null;
+
+alter table discount_redemptions
+    drop constraint if exists discount_redemptions_discount_id_fkey,
+    add constraint discount_redemptions_discount_id_fkey
+        foreign key (discount_id) references discounts (id) on delete cascade;
+
+drop index if exists idx_discounts_code;
+
+create unique index if not exists idx_discounts_code on discounts (lower(code))
+    where code is not null;
+
+alter table discounts
+    drop column if exists deleted_at;
@@ -0,0 +1,14 @@
+-- Deleted discounts are kept for the redemptions report. Their codes can be
+-- used by new discounts.
+alter table discounts
+    add column if not exists deleted_at timestamp null;
+
+drop index if exists idx_discounts_code;
+
+create unique index if not exists idx_discounts_code on discounts (lower(code))
+    where code is not null and deleted_at is null;
+
+alter table discount_redemptions
+    drop constraint if exists discount_redemptions_discount_id_fkey,
+    add constraint discount_redemptions_discount_id_fkey
+        foreign key (discount_id) references discounts (id) on delete restrict;
@@ -0,0 +1,4 @@
+drop index if exists idx_tea_lists_user_name;
+
+alter table tea_lists
+    add constraint tea_list_user_name_unique unique (user_id, name);
@@ -0,0 +1,15 @@
+-- List names are unique per user regardless of the case, as ListService
+-- checks them. Lists whose names differ only in the case get a number.
+update tea_lists tl
+set name       = left(tl.name, 250) || ' (' || d.rn || ')',
+    updated_at = now()
+from (select id,
+             row_number() over (partition by user_id, lower(name) order by is_favourite desc, created_at) as rn
+      from tea_lists) d
+where d.id = tl.id
+  and d.rn > 1;
+
+alter table tea_lists
+    drop constraint if exists tea_list_user_name_unique;
+
+create unique index if not exists idx_tea_lists_user_name on tea_lists (user_id, lower(name));